@@ -77,6 +77,13 @@ func (c *Client) sendRequest(req *http.Request, v interface{}, useCookie bool) (
 		return nil, fmt.Errorf("unknown error, status code: %d", res.StatusCode)
 	}
 
+	// responses that are not JSON, such as exported graphs, are returned as they are
+	if raw, ok := v.(*[]byte); ok {
+		*raw, err = ioutil.ReadAll(res.Body)
+
+		return nil, err
+	}
+
 	if v != nil {
 		if err = json.NewDecoder(res.Body).Decode(v); err != nil {
 			return nil, err
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 )
@@ -46,3 +48,90 @@ func (c *Client) GetK8sNamespaces(
 
 	return bodyResp, nil
 }
+
+// CreateK8sNamespaceRequest represents the accepted fields for creating a
+// namespace in a k8s cluster
+type CreateK8sNamespaceRequest struct {
+	Name          string            `json:"name"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	ResourceQuota map[string]string `json:"resource_quota,omitempty"`
+}
+
+// CreateK8sNamespaceResponse is the namespace returned after creation
+type CreateK8sNamespaceResponse v1.Namespace
+
+// CreateK8sNamespace creates a namespace in a k8s cluster
+func (c *Client) CreateK8sNamespace(
+	ctx context.Context,
+	projectID uint,
+	clusterID uint,
+	createNamespaceRequest *CreateK8sNamespaceRequest,
+) (*CreateK8sNamespaceResponse, error) {
+	data, err := json.Marshal(createNamespaceRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cl := fmt.Sprintf("%d", clusterID)
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/k8s/namespaces?"+url.Values{
+			"cluster_id": []string{cl},
+		}.Encode(), c.BaseURL, projectID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &CreateK8sNamespaceResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// DeleteK8sNamespace deletes a namespace in a k8s cluster
+func (c *Client) DeleteK8sNamespace(
+	ctx context.Context,
+	projectID uint,
+	clusterID uint,
+	name string,
+) error {
+	cl := fmt.Sprintf("%d", clusterID)
+
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf("%s/projects/%d/k8s/namespaces/%s?"+url.Values{
+			"cluster_id": []string{cl},
+		}.Encode(), c.BaseURL, projectID, name),
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, nil, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return err
+	}
+
+	return nil
+}
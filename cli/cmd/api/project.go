@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/models"
 )
 
@@ -74,6 +75,40 @@ func (c *Client) GetProjectCluster(
 	return bodyResp, nil
 }
 
+// CheckProjectClusterResponse is the result of checking the connection to a
+// project's cluster, and the permissions that Porter has in it
+type CheckProjectClusterResponse kubernetes.ClusterCheckResult
+
+// CheckProjectCluster checks that Porter can connect to a project's cluster
+func (c *Client) CheckProjectCluster(
+	ctx context.Context,
+	projectID uint,
+	clusterID uint,
+) (*CheckProjectClusterResponse, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/clusters/%d/check", c.BaseURL, projectID, clusterID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &CheckProjectClusterResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
 // ListProjectClustersResponse lists the linked clusters for a project
 type ListProjectClustersResponse []models.ClusterExternal
 
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetReleaseGraph gets the graph of the components of a release, exported in
+// the passed format. If revision is 0, the latest revision is used.
+func (c *Client) GetReleaseGraph(
+	ctx context.Context,
+	projectID uint,
+	clusterID uint,
+	namespace string,
+	name string,
+	revision uint,
+	format string,
+) ([]byte, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/releases/%s/%d/components?"+url.Values{
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+			"namespace":  []string{namespace},
+			"storage":    []string{"secret"},
+			"format":     []string{format},
+		}.Encode(), c.BaseURL, projectID, url.PathEscape(name), revision),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := []byte{}
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
@@ -47,6 +47,18 @@ var clusterDeleteCmd = &cobra.Command{
 	},
 }
 
+var clusterCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks that Porter can connect to the cluster and has the permissions it needs",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, checkCluster)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
 var clusterNamespaceCmd = &cobra.Command{
 	Use:     "namespace",
 	Aliases: []string{"namespaces"},
@@ -65,6 +77,38 @@ var clusterNamespaceListCmd = &cobra.Command{
 	},
 }
 
+var clusterNamespaceCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Creates a namespace in a cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, createNamespace)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var clusterNamespaceDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Deletes a namespace in a cluster, along with all resources inside it",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, deleteNamespace)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	namespaceLabels      map[string]string
+	namespaceAnnotations map[string]string
+	namespaceQuota       map[string]string
+)
+
 func init() {
 	rootCmd.AddCommand(clusterCmd)
 
@@ -78,8 +122,32 @@ func init() {
 	clusterCmd.AddCommand(clusterNamespaceCmd)
 	clusterCmd.AddCommand(clusterListCmd)
 	clusterCmd.AddCommand(clusterDeleteCmd)
+	clusterCmd.AddCommand(clusterCheckCmd)
 
 	clusterNamespaceCmd.AddCommand(clusterNamespaceListCmd)
+	clusterNamespaceCmd.AddCommand(clusterNamespaceCreateCmd)
+	clusterNamespaceCmd.AddCommand(clusterNamespaceDeleteCmd)
+
+	clusterNamespaceCreateCmd.PersistentFlags().StringToStringVar(
+		&namespaceLabels,
+		"label",
+		nil,
+		"labels to add to the namespace, as key=value",
+	)
+
+	clusterNamespaceCreateCmd.PersistentFlags().StringToStringVar(
+		&namespaceAnnotations,
+		"annotation",
+		nil,
+		"annotations to add to the namespace, as key=value",
+	)
+
+	clusterNamespaceCreateCmd.PersistentFlags().StringToStringVar(
+		&namespaceQuota,
+		"quota",
+		nil,
+		"hard resource quota for the namespace, as resource=quantity (for example requests.cpu=4)",
+	)
 }
 
 func listClusters(user *api.AuthCheckResponse, client *api.Client, args []string) error {
@@ -141,6 +209,38 @@ func deleteCluster(user *api.AuthCheckResponse, client *api.Client, args []strin
 	return nil
 }
 
+func checkCluster(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	res, err := client.CheckProjectCluster(context.Background(), getProjectID(), clusterID)
+
+	if err != nil {
+		return err
+	}
+
+	if res.ServerVersion != "" {
+		fmt.Printf("Server version: %s\n", res.ServerVersion)
+	}
+
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	for _, check := range res.Checks {
+		if check.Passed {
+			green.Printf("PASS  %s\n", check.Name)
+		} else {
+			red.Printf("FAIL  %s\n", check.Name)
+			fmt.Printf("      %s\n", check.Message)
+		}
+	}
+
+	if !res.Passed {
+		return fmt.Errorf("cluster %d failed one or more checks", clusterID)
+	}
+
+	green.Printf("Cluster %d passed all checks\n", clusterID)
+
+	return nil
+}
+
 func listNamespaces(user *api.AuthCheckResponse, client *api.Client, args []string) error {
 	pID := getProjectID()
 
@@ -171,3 +271,51 @@ func listNamespaces(user *api.AuthCheckResponse, client *api.Client, args []stri
 
 	return nil
 }
+
+func createNamespace(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	namespace, err := client.CreateK8sNamespace(
+		context.Background(),
+		getProjectID(),
+		getClusterID(),
+		&api.CreateK8sNamespaceRequest{
+			Name:          args[0],
+			Labels:        namespaceLabels,
+			Annotations:   namespaceAnnotations,
+			ResourceQuota: namespaceQuota,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Created namespace %s\n", namespace.Name)
+
+	return nil
+}
+
+func deleteNamespace(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	userResp, err := utils.PromptPlaintext(
+		fmt.Sprintf(
+			`Are you sure you'd like to delete the namespace %s and all resources inside it? %s `,
+			args[0],
+			color.New(color.FgCyan).Sprintf("[y/n]"),
+		),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if userResp := strings.ToLower(userResp); userResp == "y" || userResp == "yes" {
+		err = client.DeleteK8sNamespace(context.Background(), getProjectID(), getClusterID(), args[0])
+
+		if err != nil {
+			return err
+		}
+
+		color.New(color.FgGreen).Printf("Deleted namespace %s\n", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+)
+
+// releaseCmd represents the "porter release" base command when called
+// without any subcommands
+var releaseCmd = &cobra.Command{
+	Use:     "release",
+	Aliases: []string{"releases"},
+	Short:   "Commands that read from the releases in a cluster",
+}
+
+var releaseGraphCmd = &cobra.Command{
+	Use:   "graph [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Prints the graph of the components of a release, in a format that other tools can render",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, printReleaseGraph)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	releaseNamespace string
+	releaseRevision  uint
+	graphFormat      string
+)
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.PersistentFlags().UintVar(
+		&clusterID,
+		"cluster-id",
+		getClusterID(),
+		"id of the cluster",
+	)
+
+	releaseCmd.PersistentFlags().StringVar(
+		&releaseNamespace,
+		"namespace",
+		"default",
+		"namespace of the release",
+	)
+
+	releaseCmd.AddCommand(releaseGraphCmd)
+
+	releaseGraphCmd.PersistentFlags().UintVar(
+		&releaseRevision,
+		"revision",
+		0,
+		"revision of the release, or 0 for the latest revision",
+	)
+
+	releaseGraphCmd.PersistentFlags().StringVarP(
+		&graphFormat,
+		"output",
+		"o",
+		"dot",
+		"format of the graph: dot, graphml, mermaid or json",
+	)
+}
+
+func printReleaseGraph(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	graph, err := client.GetReleaseGraph(
+		context.Background(),
+		getProjectID(),
+		clusterID,
+		releaseNamespace,
+		args[0],
+		releaseRevision,
+		graphFormat,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(graph))
+
+	return nil
+}
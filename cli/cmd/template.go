@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// templateCmd represents the "porter template" base command when called
+// without any subcommands
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Commands for authoring templates",
+}
+
+var templateLintCmd = &cobra.Command{
+	Use:   "lint [chart-dir]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Checks the form.yaml of a chart against the chart's values",
+	Long: `Checks the form.yaml of a chart against the chart's values. Every context type,
+query, variable, show_if and compute expression of the form is checked, and the
+mistakes are printed to stderr with the line of the form.yaml they are on. Exits
+with a non-zero status if the form has mistakes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := lintTemplate(args[0])
+
+		if err != nil {
+			color.New(color.FgRed).Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+
+	templateCmd.AddCommand(templateLintCmd)
+}
+
+func lintTemplate(chartDir string) error {
+	ch, err := loader.Load(chartDir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range ch.Files {
+		if !strings.Contains(file.Name, "form.yaml") {
+			continue
+		}
+
+		formPath := filepath.Join(chartDir, file.Name)
+
+		errs, err := parser.LintFormYAML(ch, file.Data)
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", formPath, err)
+		}
+
+		for _, lintErr := range errs {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", formPath, lintErr.Line, lintErr.Key, lintErr.Message)
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("%s has %d error(s)", formPath, len(errs))
+		}
+
+		color.New(color.FgGreen).Printf("%s has no errors\n", formPath)
+
+		return nil
+	}
+
+	fmt.Printf("%s has no form.yaml, so a form will be generated from its values\n", chartDir)
+
+	return nil
+}
@@ -48,10 +48,7 @@ var testCmd = &cobra.Command{
 									Type:  "number-input",
 									Value: "service.targetPort",
 									Label: "Target Port",
-									Settings: struct {
-										Default interface{} `yaml:"default,omitempty" json:"default,omitempty"`
-										Unit    interface{} `yaml:"unit,omitempty" json:"unit,omitempty"`
-									}{
+									Settings: models.FormContentSettings{
 										Default: 8000,
 									},
 								},
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/porter-dev/porter/internal/config"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
+	lr "github.com/porter-dev/porter/internal/logger"
+)
+
+// minReconnectDelay is the delay before the first attempt to reopen the tunnel
+const minReconnectDelay = time.Second
+
+func main() {
+	agentConf := config.AgentFromEnv()
+
+	logger := lr.NewConsole(agentConf.Debug)
+
+	// the agent proxies requests with the service account of its pod
+	k8sAgent, err := kubernetes.GetAgentInClusterConfig()
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("could not load in-cluster config")
+		return
+	}
+
+	restConf, err := k8sAgent.RESTClientGetter.ToRESTConfig()
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("could not load in-cluster config")
+		return
+	}
+
+	proxy, err := tunnel.NewAPIServerProxy(restConf)
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("could not create API server proxy")
+		return
+	}
+
+	delay := minReconnectDelay
+
+	for {
+		ws, err := tunnel.Dial(
+			context.Background(),
+			agentConf.ServerURL,
+			agentConf.ProjectID,
+			agentConf.ClusterID,
+			agentConf.Token,
+		)
+
+		if err != nil {
+			logger.Error().Err(err).Msgf("retrying in %s", delay)
+
+			time.Sleep(delay)
+
+			if delay *= 2; delay > agentConf.MaxReconnectDelay {
+				delay = agentConf.MaxReconnectDelay
+			}
+
+			continue
+		}
+
+		logger.Info().Msgf("tunnel opened to %s", agentConf.ServerURL)
+		delay = minReconnectDelay
+
+		if err := tunnel.ServeAgent(ws, proxy); err != nil {
+			logger.Error().Err(err).Msg("tunnel failed")
+		} else {
+			logger.Info().Msg("tunnel closed")
+		}
+
+		// the server closes the tunnel when another agent connects for the same
+		// cluster, so reconnecting is delayed to avoid replacing it in a loop
+		time.Sleep(delay)
+	}
+}
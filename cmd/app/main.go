@@ -36,6 +36,7 @@ func main() {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleImpersonation{},
 		&models.User{},
 		&models.Session{},
 		&models.GitRepo{},
@@ -43,11 +44,15 @@ func main() {
 		&models.Cluster{},
 		&models.ClusterCandidate{},
 		&models.ClusterResolver{},
+		&models.ManifestRelease{},
+		&models.ManifestFile{},
+		&models.Stack{},
 		&ints.KubeIntegration{},
 		&ints.OIDCIntegration{},
 		&ints.OAuthIntegration{},
 		&ints.GCPIntegration{},
 		&ints.AWSIntegration{},
+		&ints.BastionIntegration{},
 		&ints.TokenCache{},
 		&ints.RegTokenCache{},
 	)
@@ -86,6 +91,7 @@ func main() {
 			Scopes:       []string{"repo", "user", "read:user"},
 			BaseURL:      appConf.Server.ServerURL,
 		},
+		appConf.Server.DiscoveryCacheDir,
 	)
 
 	appRouter := router.New(a, store, appConf.Server.CookieName, appConf.Server.StaticFilePath, repo)
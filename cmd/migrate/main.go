@@ -27,6 +27,7 @@ func main() {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleImpersonation{},
 		&models.User{},
 		&models.Session{},
 		&models.GitRepo{},
@@ -34,11 +35,15 @@ func main() {
 		&models.Cluster{},
 		&models.ClusterCandidate{},
 		&models.ClusterResolver{},
+		&models.ManifestRelease{},
+		&models.ManifestFile{},
+		&models.Stack{},
 		&ints.KubeIntegration{},
 		&ints.OIDCIntegration{},
 		&ints.OAuthIntegration{},
 		&ints.GCPIntegration{},
 		&ints.AWSIntegration{},
+		&ints.BastionIntegration{},
 		&ints.TokenCache{},
 		&ints.RegTokenCache{},
 	)
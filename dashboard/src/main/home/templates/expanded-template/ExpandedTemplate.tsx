@@ -3,6 +3,7 @@ import styled from 'styled-components';
 
 import { PorterTemplate } from '../../../../shared/types';
 import api from '../../../../shared/api';
+import { Context } from '../../../../shared/Context';
 
 import TemplateInfo from './TemplateInfo';
 import LaunchTemplate from './LaunchTemplate';
@@ -36,11 +37,9 @@ export default class ExpandedTemplate extends Component<PropsType, StateType> {
   }
 
   componentDidMount() {
-    this.setState({ loading: true });
-    api.getTemplateInfo('<token>', {}, {
-      name: this.props.currentTemplate.name.toLowerCase().trim(),
-      version: 'latest',
-    }, (err: any, res: any) => {
+    let { currentProject, currentCluster } = this.context;
+    let name = this.props.currentTemplate.name.toLowerCase().trim();
+    let callback = (err: any, res: any) => {
       if (err) {
         this.setState({ loading: false, error: true });
       } else {
@@ -48,7 +47,22 @@ export default class ExpandedTemplate extends Component<PropsType, StateType> {
         let keywords = metadata.keywords;
         this.setState({ form, values, markdown, keywords, loading: false, error: false });
       }
-    });
+    };
+
+    this.setState({ loading: true });
+
+    // read the template for the current cluster so its form can list cluster values
+    if (currentProject && currentCluster) {
+      api.getProjectTemplateInfo('<token>', {
+        cluster_id: currentCluster.id,
+      }, {
+        id: currentProject.id,
+        name,
+        version: 'latest',
+      }, callback);
+    } else {
+      api.getTemplateInfo('<token>', {}, { name, version: 'latest' }, callback);
+    }
   }
 
   renderContents = () => {
@@ -89,6 +103,8 @@ export default class ExpandedTemplate extends Component<PropsType, StateType> {
   }
 }
 
+ExpandedTemplate.contextType = Context;
+
 const FadeWrapper = styled.div`
   animation: fadeIn 0.2s;
   @keyframes fadeIn {
@@ -129,6 +129,13 @@ const getTemplateInfo = baseApi<{}, { name: string, version: string }>('GET', pa
   return `/api/templates/${pathParams.name}/${pathParams.version}`;
 });
 
+const getProjectTemplateInfo = baseApi<{
+  cluster_id: number,
+}, { id: number, name: string, version: string }>('GET', pathParams => {
+  let { id, name, version } = pathParams;
+  return `/api/projects/${id}/templates/${name}/${version}`;
+});
+
 const getRepos = baseApi<{}, { id: number }>('GET', pathParams => {
   return `/api/projects/${pathParams.id}/repos`;
 });
@@ -244,6 +251,7 @@ export default {
   upgradeChartValues,
   getTemplates,
   getTemplateInfo,
+  getProjectTemplateInfo,
   getBranches,
   getBranchContents,
   getProjects,
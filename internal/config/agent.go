@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log"
+	"time"
+
+	"github.com/joeshaw/envdecode"
+)
+
+// AgentConf is the configuration for the in-cluster agent, which opens a tunnel
+// to the Porter server for a cluster that uses the agent auth mechanism
+type AgentConf struct {
+	Debug bool `env:"DEBUG,default=false"`
+
+	// ServerURL is the URL of the Porter server that the agent connects to
+	ServerURL string `env:"PORTER_SERVER_URL,required"`
+
+	ProjectID uint `env:"PORTER_PROJECT_ID,required"`
+	ClusterID uint `env:"PORTER_CLUSTER_ID,required"`
+
+	// Token is the agent token returned when the cluster was created
+	Token string `env:"PORTER_AGENT_TOKEN,required"`
+
+	// MaxReconnectDelay bounds the delay between attempts to reopen the tunnel
+	MaxReconnectDelay time.Duration `env:"PORTER_MAX_RECONNECT_DELAY,default=30s"`
+}
+
+// AgentFromEnv generates an agent configuration from environment variables
+func AgentFromEnv() *AgentConf {
+	var c AgentConf
+
+	if err := envdecode.StrictDecode(&c); err != nil {
+		log.Fatalf("Failed to decode: %s", err)
+	}
+
+	return &c
+}
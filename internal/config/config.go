@@ -27,6 +27,10 @@ type ServerConf struct {
 	TimeoutIdle    time.Duration `env:"SERVER_TIMEOUT_IDLE,default=15s"`
 	IsLocal        bool          `env:"IS_LOCAL,default=false"`
 
+	// DiscoveryCacheDir is the directory in which the discovery caches of each
+	// cluster are stored, which defaults to ~/.kube/cache/porter if unset
+	DiscoveryCacheDir string `env:"DISCOVERY_CACHE_DIR"`
+
 	GithubClientID     string `env:"GITHUB_CLIENT_ID"`
 	GithubClientSecret string `env:"GITHUB_CLIENT_SECRET"`
 }
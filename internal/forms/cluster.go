@@ -69,17 +69,40 @@ func (ccf *CreateClusterForm) ToCluster() (*models.Cluster, error) {
 	}, nil
 }
 
+// CreateAgentClusterForm represents the accepted values for creating a cluster
+// that is reached through an in-cluster agent
+type CreateAgentClusterForm struct {
+	Name      string `json:"name" form:"required"`
+	ProjectID uint   `json:"project_id" form:"required"`
+}
+
+// ToCluster converts the form to a cluster, which authenticates its agent with
+// the token whose hash is agentTokenHash
+func (ccf *CreateAgentClusterForm) ToCluster(agentTokenHash []byte) (*models.Cluster, error) {
+	return &models.Cluster{
+		AuthMechanism:  models.Agent,
+		ProjectID:      ccf.ProjectID,
+		Name:           ccf.Name,
+		Server:         kubernetes.AgentTunnelServer,
+		AgentTokenHash: agentTokenHash,
+	}, nil
+}
+
 // UpdateClusterForm represents the accepted values for updating a
-// cluster (only name for now)
+// cluster. The proxy and bastion are only changed if they are set: an empty
+// proxy url or a bastion id of 0 removes them.
 type UpdateClusterForm struct {
 	ID uint
 
 	Name string `json:"name" form:"required"`
+
+	ProxyURL             *string `json:"proxy_url"`
+	BastionIntegrationID *uint   `json:"bastion_integration_id"`
 }
 
 // ToCluster converts the form to a cluster
-func (ucf *UpdateClusterForm) ToCluster(repo repository.ClusterRepository) (*models.Cluster, error) {
-	cluster, err := repo.ReadCluster(ucf.ID)
+func (ucf *UpdateClusterForm) ToCluster(repo repository.Repository) (*models.Cluster, error) {
+	cluster, err := repo.Cluster.ReadCluster(ucf.ID)
 
 	if err != nil {
 		return nil, err
@@ -87,6 +110,40 @@ func (ucf *UpdateClusterForm) ToCluster(repo repository.ClusterRepository) (*mod
 
 	cluster.Name = ucf.Name
 
+	if ucf.ProxyURL != nil {
+		if *ucf.ProxyURL != "" {
+			u, err := url.Parse(*ucf.ProxyURL)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy url: %v", err)
+			}
+
+			switch u.Scheme {
+			case "http", "https", "socks5", "socks5h":
+			default:
+				return nil, fmt.Errorf("unsupported proxy scheme %q: must be one of http, https, socks5", u.Scheme)
+			}
+		}
+
+		cluster.ProxyURL = *ucf.ProxyURL
+	}
+
+	if ucf.BastionIntegrationID != nil {
+		if *ucf.BastionIntegrationID != 0 {
+			bastion, err := repo.BastionIntegration.ReadBastionIntegration(*ucf.BastionIntegrationID)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if bastion.ProjectID != cluster.ProjectID {
+				return nil, errors.New("bastion integration does not belong to the cluster's project")
+			}
+		}
+
+		cluster.BastionIntegrationID = *ucf.BastionIntegrationID
+	}
+
 	return cluster, nil
 }
 
@@ -43,3 +43,37 @@ func (caf *CreateAWSIntegrationForm) ToAWSIntegration() (*ints.AWSIntegration, e
 		AWSSecretAccessKey: []byte(caf.AWSSecretAccessKey),
 	}, nil
 }
+
+// CreateBastionIntegrationForm represents the accepted values for creating an
+// SSH bastion integration
+type CreateBastionIntegrationForm struct {
+	UserID     uint   `json:"user_id" form:"required"`
+	ProjectID  uint   `json:"project_id" form:"required"`
+	Host       string `json:"host" form:"required"`
+	Port       int    `json:"port"`
+	User       string `json:"user" form:"required"`
+	PrivateKey string `json:"private_key" form:"required"`
+	Passphrase string `json:"passphrase"`
+	HostKey    string `json:"host_key" form:"required"`
+}
+
+// ToBastionIntegration converts the form to a bastion integration, and checks
+// that the keys can be parsed
+func (cbf *CreateBastionIntegrationForm) ToBastionIntegration() (*ints.BastionIntegration, error) {
+	bastion := &ints.BastionIntegration{
+		UserID:     cbf.UserID,
+		ProjectID:  cbf.ProjectID,
+		Host:       cbf.Host,
+		Port:       cbf.Port,
+		User:       cbf.User,
+		HostKey:    cbf.HostKey,
+		PrivateKey: []byte(cbf.PrivateKey),
+		Passphrase: []byte(cbf.Passphrase),
+	}
+
+	if _, err := bastion.SSHClientConfig(); err != nil {
+		return nil, err
+	}
+
+	return bastion, nil
+}
@@ -1,11 +1,16 @@
 package forms
 
 import (
+	"fmt"
 	"net/url"
 	"strconv"
 
 	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/repository"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // K8sForm is the generic base type for CRUD operations on k8s objects
@@ -37,3 +42,217 @@ func (kf *K8sForm) PopulateK8sOptionsFromQueryParams(
 
 	return nil
 }
+
+// RestartControllerForm represents the accepted values for triggering a rolling
+// restart of a controller
+type RestartControllerForm struct {
+	*K8sForm
+	Namespace string `form:"required"`
+	Kind      string `form:"required,oneof=deployment statefulset daemonset"`
+	Name      string `form:"required"`
+}
+
+// ScaleControllerForm represents the accepted values for scaling a controller
+type ScaleControllerForm struct {
+	*K8sForm
+	Namespace string `form:"required"`
+	Kind      string `form:"required,oneof=deployment statefulset replicaset"`
+	Name      string `form:"required"`
+	Replicas  *int32 `json:"replicas" form:"required,min=0"`
+}
+
+// DeletePodForm represents the accepted values for deleting a single pod
+type DeletePodForm struct {
+	*K8sForm
+	Namespace string `form:"required"`
+	Name      string `form:"required"`
+}
+
+// CreateNamespaceForm represents the accepted values for creating a namespace.
+// ResourceQuota and LimitRange are optional, and are keyed by resource name,
+// for example "requests.cpu" or "memory".
+type CreateNamespaceForm struct {
+	*K8sForm
+	Name          string            `json:"name" form:"required"`
+	Labels        map[string]string `json:"labels"`
+	Annotations   map[string]string `json:"annotations"`
+	ResourceQuota map[string]string `json:"resource_quota"`
+	LimitRange    *LimitRangeForm   `json:"limit_range"`
+}
+
+// LimitRangeForm represents the default container limits and requests that
+// get applied to a namespace on creation
+type LimitRangeForm struct {
+	Default        map[string]string `json:"default"`
+	DefaultRequest map[string]string `json:"default_request"`
+}
+
+// ToNamespace converts the form to a namespace
+func (cnf *CreateNamespaceForm) ToNamespace() *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cnf.Name,
+			Labels:      cnf.Labels,
+			Annotations: cnf.Annotations,
+		},
+	}
+}
+
+// ToResourceQuota converts the form to a resource quota, or returns nil if
+// no quota was requested
+func (cnf *CreateNamespaceForm) ToResourceQuota() (*v1.ResourceQuota, error) {
+	if len(cnf.ResourceQuota) == 0 {
+		return nil, nil
+	}
+
+	hard, err := toResourceList(cnf.ResourceQuota)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "porter-default",
+			Namespace: cnf.Name,
+		},
+		Spec: v1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}, nil
+}
+
+// ToLimitRange converts the form to a limit range for containers, or returns nil
+// if no limit range was requested
+func (cnf *CreateNamespaceForm) ToLimitRange() (*v1.LimitRange, error) {
+	if cnf.LimitRange == nil {
+		return nil, nil
+	}
+
+	def, err := toResourceList(cnf.LimitRange.Default)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defRequest, err := toResourceList(cnf.LimitRange.DefaultRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "porter-default",
+			Namespace: cnf.Name,
+		},
+		Spec: v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{
+				v1.LimitRangeItem{
+					Type:           v1.LimitTypeContainer,
+					Default:        def,
+					DefaultRequest: defRequest,
+				},
+			},
+		},
+	}, nil
+}
+
+// DeleteNamespaceForm represents the accepted values for deleting a namespace
+type DeleteNamespaceForm struct {
+	*K8sForm
+	Name string `form:"required"`
+}
+
+func toResourceList(vals map[string]string) (v1.ResourceList, error) {
+	res := make(v1.ResourceList)
+
+	for name, val := range vals {
+		quantity, err := resource.ParseQuantity(val)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %s for resource %s", val, name)
+		}
+
+		res[v1.ResourceName(name)] = quantity
+	}
+
+	return res, nil
+}
+
+// ResourceForm represents the accepted values for reading or deleting objects of an
+// arbitrary resource, such as a CRD. The group and version are optional, and are
+// resolved by the api server's discovery information if not set.
+type ResourceForm struct {
+	*K8sForm
+	Group         string
+	Version       string
+	Resource      string `form:"required"`
+	Namespace     string
+	Name          string
+	LabelSelector string
+}
+
+// PopulateResourceFromQueryParams populates the resource fields of the ResourceForm
+// using the passed url.Values (the parsed query params)
+func (rf *ResourceForm) PopulateResourceFromQueryParams(vals url.Values) {
+	if group, ok := vals["group"]; ok && len(group) == 1 {
+		rf.Group = group[0]
+	}
+
+	if version, ok := vals["version"]; ok && len(version) == 1 {
+		rf.Version = version[0]
+	}
+
+	if resource, ok := vals["resource"]; ok && len(resource) == 1 {
+		rf.Resource = resource[0]
+	}
+
+	if namespace, ok := vals["namespace"]; ok && len(namespace) == 1 {
+		rf.Namespace = namespace[0]
+	}
+
+	if selector, ok := vals["label_selector"]; ok && len(selector) == 1 {
+		rf.LabelSelector = selector[0]
+	}
+}
+
+// ToGroupVersionResource returns the (possibly partial) GroupVersionResource
+// specified by the form
+func (rf *ResourceForm) ToGroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    rf.Group,
+		Version:  rf.Version,
+		Resource: rf.Resource,
+	}
+}
+
+// ApplyResourceForm represents the accepted values for applying a YAML or JSON
+// manifest with server-side apply
+type ApplyResourceForm struct {
+	*K8sForm
+	Namespace string `json:"namespace"`
+	Manifest  string `json:"manifest" form:"required"`
+}
+
+// NodeForm represents the accepted values for operating on a single node
+type NodeForm struct {
+	*K8sForm
+	Name string `form:"required"`
+}
+
+// DrainNodeForm represents the accepted values for draining a node
+type DrainNodeForm struct {
+	*K8sForm
+	Name            string `form:"required"`
+	Force           bool   `json:"force"`
+	DeleteLocalData bool   `json:"delete_local_data"`
+}
+
+// ToDrainOptions converts the form to the options for draining a node
+func (dnf *DrainNodeForm) ToDrainOptions() kubernetes.DrainOptions {
+	return kubernetes.DrainOptions{
+		Force:           dnf.Force,
+		DeleteLocalData: dnf.DeleteLocalData,
+	}
+}
@@ -0,0 +1,69 @@
+package forms
+
+import (
+	"sort"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateManifestReleaseForm represents the accepted values for deploying a release
+// of raw manifests or a Kustomize base and overlay
+type CreateManifestReleaseForm struct {
+	*K8sForm
+	Name      string                 `json:"name" form:"required"`
+	Namespace string                 `json:"namespace" form:"required"`
+	Kind      string                 `json:"kind" form:"required,oneof=manifests kustomize"`
+	Overlay   string                 `json:"overlay"`
+	Files     map[string]string      `json:"files" form:"required,min=1"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// ToManifestRelease converts the form to a manifest release in a project and cluster
+func (cmf *CreateManifestReleaseForm) ToManifestRelease(projectID, clusterID uint) *models.ManifestRelease {
+	return &models.ManifestRelease{
+		ProjectID: projectID,
+		ClusterID: clusterID,
+		Name:      cmf.Name,
+		Namespace: cmf.Namespace,
+		Kind:      cmf.Kind,
+		Overlay:   cmf.Overlay,
+		Files:     toManifestFiles(cmf.Files),
+	}
+}
+
+// UpgradeManifestReleaseForm represents the accepted values for upgrading a release
+// of raw manifests or Kustomize. If files are passed, they replace the files of the
+// release before the values are written.
+type UpgradeManifestReleaseForm struct {
+	*K8sForm
+	Namespace string                 `form:"required"`
+	Name      string                 `form:"required"`
+	Files     map[string]string      `json:"files"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// ToManifestFiles returns the files of the form, ordered by path
+func (umf *UpgradeManifestReleaseForm) ToManifestFiles() []models.ManifestFile {
+	return toManifestFiles(umf.Files)
+}
+
+func toManifestFiles(files map[string]string) []models.ManifestFile {
+	paths := make([]string, 0, len(files))
+
+	for path := range files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	res := make([]models.ManifestFile, 0, len(paths))
+
+	for _, path := range paths {
+		res = append(res, models.ManifestFile{
+			Path: path,
+			Data: files[path],
+		})
+	}
+
+	return res
+}
@@ -1,6 +1,9 @@
 package forms
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
 	"gorm.io/gorm"
@@ -41,3 +44,38 @@ func (cprf *CreateProjectRoleForm) ToProject(_ repository.ProjectRepository) (*m
 		Roles: cprf.Roles,
 	}, nil
 }
+
+// UpdateRoleImpersonationForm represents the accepted values for mapping a
+// project role to the Kubernetes identity it acts as
+type UpdateRoleImpersonationForm struct {
+	ProjectID uint     `json:"-" form:"required"`
+	RoleKind  string   `json:"-" form:"required"`
+	User      string   `json:"user" form:"required"`
+	Groups    []string `json:"groups"`
+}
+
+// ToRoleImpersonation converts the form to a gorm role impersonation model
+func (urif *UpdateRoleImpersonationForm) ToRoleImpersonation() (*models.RoleImpersonation, error) {
+	groups := make([]string, 0)
+
+	for _, group := range urif.Groups {
+		group = strings.TrimSpace(group)
+
+		if group == "" {
+			continue
+		}
+
+		if strings.Contains(group, ",") {
+			return nil, fmt.Errorf("group %q cannot contain a comma", group)
+		}
+
+		groups = append(groups, group)
+	}
+
+	return &models.RoleImpersonation{
+		ProjectID: urif.ProjectID,
+		RoleKind:  urif.RoleKind,
+		User:      strings.TrimSpace(urif.User),
+		Groups:    strings.Join(groups, ","),
+	}, nil
+}
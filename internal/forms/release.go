@@ -100,6 +100,37 @@ type ListReleaseHistoryForm struct {
 	Name string `json:"name" form:"required"`
 }
 
+// DiffReleaseGraphForm represents the accepted values for comparing the graphs of
+// two revisions of a Helm release
+type DiffReleaseGraphForm struct {
+	*ReleaseForm
+	Name string `json:"name" form:"required"`
+	From int    `json:"from" form:"required"`
+	To   int    `json:"to" form:"required"`
+}
+
+// PopulateRevisionsFromQueryParams populates the revisions to compare using the
+// passed url.Values (the parsed query params). Invalid revisions are left unset,
+// so that they fail validation.
+func (drgf *DiffReleaseGraphForm) PopulateRevisionsFromQueryParams(
+	vals url.Values,
+	_ repository.ClusterRepository,
+) error {
+	if from, ok := vals["from"]; ok && len(from) == 1 {
+		if fromInt, err := strconv.ParseInt(from[0], 10, 64); err == nil {
+			drgf.From = int(fromInt)
+		}
+	}
+
+	if to, ok := vals["to"]; ok && len(to) == 1 {
+		if toInt, err := strconv.ParseInt(to[0], 10, 64); err == nil {
+			drgf.To = int(toInt)
+		}
+	}
+
+	return nil
+}
+
 // RollbackReleaseForm represents the accepted values for getting a single Helm release
 type RollbackReleaseForm struct {
 	*ReleaseForm
@@ -114,6 +145,16 @@ type UpgradeReleaseForm struct {
 	Values string `json:"values" form:"required"`
 }
 
+// SubmitReleaseFormForm represents the accepted values for writing the values of a
+// release's form back to the contexts of the form
+type SubmitReleaseFormForm struct {
+	*ReleaseForm
+	Name string `json:"name" form:"required"`
+
+	// Values are the submitted values, keyed by the variable of each form content
+	Values map[string]interface{} `json:"values" form:"required"`
+}
+
 // ChartTemplateForm represents the accepted values for installing a new chart from a template.
 type ChartTemplateForm struct {
 	TemplateName string                 `json:"templateName" form:"required"`
@@ -142,6 +142,35 @@ func (a *Agent) InstallChart(
 	return cmd.Run(conf.Chart, conf.Values)
 }
 
+// UpgradeChart upgrades a release to a chart, which may differ from the chart the
+// release was deployed with, and replaces the values of the release
+func (a *Agent) UpgradeChart(
+	conf *InstallChartConfig,
+) (*release.Release, error) {
+	cmd := action.NewUpgrade(a.ActionConfig)
+
+	cmd.Namespace = conf.Namespace
+
+	res, err := cmd.Run(conf.Name, conf.Chart, conf.Values)
+
+	if err != nil {
+		return nil, fmt.Errorf("Upgrade failed: %v", err)
+	}
+
+	return res, nil
+}
+
+// UninstallRelease uninstalls a release and removes its history
+func (a *Agent) UninstallRelease(
+	name string,
+) error {
+	cmd := action.NewUninstall(a.ActionConfig)
+
+	_, err := cmd.Run(name)
+
+	return err
+}
+
 // RollbackRelease rolls a release back to a specified revision/version
 func (a *Agent) RollbackRelease(
 	name string,
@@ -0,0 +1,161 @@
+package grapher
+
+import (
+	"reflect"
+)
+
+// ObjectRef identifies an object across the revisions of a release, in which the
+// same object may have a different ID
+type ObjectRef struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// RelationRef is a relation between two objects, identified across revisions
+type RelationRef struct {
+	Source ObjectRef
+	Target ObjectRef
+	Type   string
+}
+
+// GraphDiff lists the differences between the graphs of two revisions of a release
+type GraphDiff struct {
+	AddedObjects   []ObjectRef
+	RemovedObjects []ObjectRef
+
+	// ChangedObjects are the objects in both revisions whose spec, or other
+	// content such as the data of a ConfigMap, changed
+	ChangedObjects []ObjectRef
+
+	AddedRelations   []RelationRef
+	RemovedRelations []RelationRef
+}
+
+// ParseManifest parses the objects in a release manifest and computes all of
+// their relations
+func ParseManifest(manifest []byte) *ParsedObjs {
+	parsed := &ParsedObjs{
+		Objects: ParseObjs(ImportMultiDocYAML(manifest)),
+	}
+
+	parsed.GetControlRel()
+	parsed.GetLabelRel()
+	parsed.GetSpecRel()
+
+	return parsed
+}
+
+// Diff computes the difference between the graph of an earlier revision, from,
+// and a later revision, to. Objects are matched by kind, name and namespace.
+func Diff(from, to *ParsedObjs) *GraphDiff {
+	diff := &GraphDiff{
+		AddedObjects:     []ObjectRef{},
+		RemovedObjects:   []ObjectRef{},
+		ChangedObjects:   []ObjectRef{},
+		AddedRelations:   []RelationRef{},
+		RemovedRelations: []RelationRef{},
+	}
+
+	fromObjs := objectsByRef(from)
+	toObjs := objectsByRef(to)
+
+	for _, o := range to.Objects {
+		ref := o.Ref()
+		prev, ok := fromObjs[ref]
+
+		if !ok {
+			diff.AddedObjects = append(diff.AddedObjects, ref)
+		} else if !reflect.DeepEqual(content(prev.RawYAML), content(o.RawYAML)) {
+			diff.ChangedObjects = append(diff.ChangedObjects, ref)
+		}
+	}
+
+	for _, o := range from.Objects {
+		if _, ok := toObjs[o.Ref()]; !ok {
+			diff.RemovedObjects = append(diff.RemovedObjects, o.Ref())
+		}
+	}
+
+	fromRels := from.relationRefs()
+	toRels := to.relationRefs()
+
+	fromRelSet := make(map[RelationRef]bool)
+
+	for _, rel := range fromRels {
+		fromRelSet[rel] = true
+	}
+
+	toRelSet := make(map[RelationRef]bool)
+
+	for _, rel := range toRels {
+		toRelSet[rel] = true
+
+		if !fromRelSet[rel] {
+			diff.AddedRelations = append(diff.AddedRelations, rel)
+		}
+	}
+
+	for _, rel := range fromRels {
+		if !toRelSet[rel] {
+			diff.RemovedRelations = append(diff.RemovedRelations, rel)
+		}
+	}
+
+	return diff
+}
+
+// Ref returns the reference that identifies the object across revisions
+func (o *Object) Ref() ObjectRef {
+	return ObjectRef{
+		Kind:      o.Kind,
+		Name:      o.Name,
+		Namespace: o.Namespace,
+	}
+}
+
+func objectsByRef(parsed *ParsedObjs) map[ObjectRef]Object {
+	objs := make(map[ObjectRef]Object)
+
+	for _, o := range parsed.Objects {
+		objs[o.Ref()] = o
+	}
+
+	return objs
+}
+
+// relationRefs returns the edges of the graph with their objects identified by
+// reference rather than by ID
+func (parsed *ParsedObjs) relationRefs() []RelationRef {
+	byID := make(map[int]ObjectRef)
+
+	for _, o := range parsed.Objects {
+		byID[o.ID] = o.Ref()
+	}
+
+	rels := []RelationRef{}
+
+	for _, e := range parsed.Edges() {
+		rels = append(rels, RelationRef{
+			Source: byID[e.Source],
+			Target: byID[e.Target],
+			Type:   e.Type,
+		})
+	}
+
+	return rels
+}
+
+// content returns the fields of an object other than its metadata and status,
+// which change without the object being changed by the release
+func content(yaml map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for k, v := range yaml {
+		if k != "metadata" && k != "status" {
+			res[k] = v
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,127 @@
+package grapher_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm/grapher"
+)
+
+const diffFromYAML = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  LOG_LEVEL: info
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: web:1.0.0
+        envFrom:
+        - configMapRef:
+            name: web
+`
+
+const diffToYAML = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    updated: "true"
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: web:1.1.0
+        envFrom:
+        - secretRef:
+            name: web
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+stringData:
+  LOG_LEVEL: info
+`
+
+func TestDiff(t *testing.T) {
+	from := grapher.ParseManifest([]byte(diffFromYAML))
+	to := grapher.ParseManifest([]byte(diffToYAML))
+
+	configMap := grapher.ObjectRef{Kind: "ConfigMap", Name: "web", Namespace: "default"}
+	secret := grapher.ObjectRef{Kind: "Secret", Name: "web", Namespace: "default"}
+	deployment := grapher.ObjectRef{Kind: "Deployment", Name: "web", Namespace: "default"}
+	pod := grapher.ObjectRef{Kind: "Pod", Name: "web-0", Namespace: "default"}
+
+	expDiff := &grapher.GraphDiff{
+		AddedObjects:   []grapher.ObjectRef{secret},
+		RemovedObjects: []grapher.ObjectRef{configMap},
+		// the annotation of the Service is ignored, and the pod changes with the
+		// template of the Deployment
+		ChangedObjects: []grapher.ObjectRef{deployment, pod},
+		AddedRelations: []grapher.RelationRef{
+			grapher.RelationRef{Source: pod, Target: secret, Type: grapher.EdgeSpec},
+		},
+		RemovedRelations: []grapher.RelationRef{
+			grapher.RelationRef{Source: pod, Target: configMap, Type: grapher.EdgeSpec},
+		},
+	}
+
+	if diff := deep.Equal(expDiff, grapher.Diff(from, to)); diff != nil {
+		t.Errorf("incorrect graph diff")
+		t.Error(diff)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	from := grapher.ParseManifest([]byte(diffFromYAML))
+	to := grapher.ParseManifest([]byte(diffFromYAML))
+
+	diff := grapher.Diff(from, to)
+
+	if len(diff.AddedObjects)+len(diff.RemovedObjects)+len(diff.ChangedObjects)+
+		len(diff.AddedRelations)+len(diff.RemovedRelations) != 0 {
+		t.Errorf("expected empty diff for the same manifest, got %+v", diff)
+	}
+}
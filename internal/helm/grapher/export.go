@@ -0,0 +1,259 @@
+package grapher
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The formats that a graph can be exported to
+const (
+	FormatJSON    string = "json"
+	FormatDOT     string = "dot"
+	FormatGraphML string = "graphml"
+	FormatMermaid string = "mermaid"
+)
+
+// The types of relations, used to label the edges of exported graphs
+const (
+	EdgeControl string = "control"
+	EdgeLabel   string = "label"
+	EdgeSpec    string = "spec"
+)
+
+// Edge is a single directed relation between two objects of a graph
+type Edge struct {
+	Source int
+	Target int
+	Type   string
+}
+
+// Edges returns each relation between the objects once. Relations are stored on
+// both of the objects they connect, so only the copy on the source is used.
+func (parsed *ParsedObjs) Edges() []Edge {
+	edges := []Edge{}
+	seen := make(map[Edge]bool)
+
+	add := func(objID int, rel Relation, relType string) {
+		edge := Edge{rel.Source, rel.Target, relType}
+
+		if rel.Source != objID || seen[edge] {
+			return
+		}
+
+		seen[edge] = true
+		edges = append(edges, edge)
+	}
+
+	for _, o := range parsed.Objects {
+		for _, rel := range o.Relations.ControlRels {
+			add(o.ID, rel.Relation, EdgeControl)
+		}
+
+		for _, rel := range o.Relations.LabelRels {
+			add(o.ID, rel.Relation, EdgeLabel)
+		}
+
+		for _, rel := range o.Relations.SpecRels {
+			add(o.ID, rel.Relation, EdgeSpec)
+		}
+	}
+
+	return edges
+}
+
+// ContentType returns the content type of a graph exported to format
+func ContentType(format string) string {
+	switch format {
+	case FormatDOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	case FormatGraphML:
+		return "application/graphml+xml; charset=utf-8"
+	case FormatMermaid:
+		return "text/plain; charset=utf-8"
+	}
+
+	return "application/json; charset=utf-8"
+}
+
+// Export writes the graph of the objects to w in the passed format, which is one
+// of FormatDOT, FormatGraphML or FormatMermaid. The graph is named after name,
+// which is usually the name of the release.
+func (parsed *ParsedObjs) Export(w io.Writer, name, format string) error {
+	switch format {
+	case FormatDOT:
+		return parsed.writeDOT(w, name)
+	case FormatGraphML:
+		return parsed.writeGraphML(w, name)
+	case FormatMermaid:
+		return parsed.writeMermaid(w)
+	}
+
+	return fmt.Errorf("unsupported graph format %q", format)
+}
+
+// dotEdgeStyles distinguishes the types of relations in DOT graphs
+var dotEdgeStyles = map[string]string{
+	EdgeControl: "solid",
+	EdgeLabel:   "dashed",
+	EdgeSpec:    "dotted",
+}
+
+func (parsed *ParsedObjs) writeDOT(w io.Writer, name string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	b.WriteString("  node [shape=box];\n")
+
+	for _, o := range parsed.Objects {
+		fmt.Fprintf(&b, "  n%d [label=%s];\n", o.ID, dotQuote(o.Kind+"\n"+o.Name))
+	}
+
+	for _, e := range parsed.Edges() {
+		fmt.Fprintf(
+			&b,
+			"  n%d -> n%d [label=%s, style=%s];\n",
+			e.Source,
+			e.Target,
+			dotQuote(e.Type),
+			dotEdgeStyles[e.Type],
+		)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+// mermaidArrows distinguishes the types of relations in Mermaid flowcharts
+var mermaidArrows = map[string]string{
+	EdgeControl: "-->",
+	EdgeLabel:   "-.->",
+	EdgeSpec:    "==>",
+}
+
+func (parsed *ParsedObjs) writeMermaid(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("graph LR\n")
+
+	for _, o := range parsed.Objects {
+		fmt.Fprintf(&b, "  n%d[\"%s<br/>%s\"]\n", o.ID, mermaidEscape(o.Kind), mermaidEscape(o.Name))
+	}
+
+	for _, e := range parsed.Edges() {
+		fmt.Fprintf(&b, "  n%d %s|%s| n%d\n", e.Source, mermaidArrows[e.Type], e.Type, e.Target)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (parsed *ParsedObjs) writeGraphML(w io.Writer, name string) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "namespace", For: "node", AttrName: "namespace", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          name,
+			EdgeDefault: "directed",
+			Nodes:       []graphMLNode{},
+			Edges:       []graphMLEdge{},
+		},
+	}
+
+	for _, o := range parsed.Objects {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: fmt.Sprintf("n%d", o.ID),
+			Data: []graphMLData{
+				{Key: "kind", Value: o.Kind},
+				{Key: "name", Value: o.Name},
+				{Key: "namespace", Value: o.Namespace},
+			},
+		})
+	}
+
+	for i, e := range parsed.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: fmt.Sprintf("n%d", e.Source),
+			Target: fmt.Sprintf("n%d", e.Target),
+			Data: []graphMLData{
+				{Key: "relation", Value: e.Type},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,170 @@
+package grapher_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/grapher"
+)
+
+const exportYAML = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      serviceAccountName: web
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+`
+
+func parseExportYAML() *grapher.ParsedObjs {
+	yamlArr := grapher.ImportMultiDocYAML([]byte(exportYAML))
+	parsed := &grapher.ParsedObjs{
+		Objects: grapher.ParseObjs(yamlArr),
+	}
+
+	parsed.GetControlRel()
+	parsed.GetLabelRel()
+	parsed.GetSpecRel()
+
+	return parsed
+}
+
+func TestEdges(t *testing.T) {
+	edges := parseExportYAML().Edges()
+
+	expEdges := []grapher.Edge{
+		grapher.Edge{Source: 0, Target: 1, Type: grapher.EdgeLabel},
+		grapher.Edge{Source: 0, Target: 3, Type: grapher.EdgeLabel},
+		grapher.Edge{Source: 1, Target: 3, Type: grapher.EdgeControl},
+		grapher.Edge{Source: 1, Target: 3, Type: grapher.EdgeLabel},
+		grapher.Edge{Source: 3, Target: 2, Type: grapher.EdgeSpec},
+	}
+
+	if len(edges) != len(expEdges) {
+		t.Fatalf("Number of edges differs. Expected %v. Got %v", expEdges, edges)
+	}
+
+	for i, e := range expEdges {
+		if edges[i] != e {
+			t.Errorf("Edge %d differs. Expected %v. Got %v", i, e, edges[i])
+		}
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := parseExportYAML().Export(&buf, "web", grapher.FormatDOT); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	exp := `digraph "web" {
+  node [shape=box];
+  n0 [label="Service\nweb"];
+  n1 [label="Deployment\nweb"];
+  n2 [label="ServiceAccount\nweb"];
+  n3 [label="Pod\nweb-0"];
+  n0 -> n1 [label="label", style=dashed];
+  n0 -> n3 [label="label", style=dashed];
+  n1 -> n3 [label="control", style=solid];
+  n1 -> n3 [label="label", style=dashed];
+  n3 -> n2 [label="spec", style=dotted];
+}
+`
+
+	if got := buf.String(); got != exp {
+		t.Errorf("DOT export differs. Expected:\n%s\nGot:\n%s", exp, got)
+	}
+}
+
+func TestExportMermaid(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := parseExportYAML().Export(&buf, "web", grapher.FormatMermaid); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	exp := `graph LR
+  n0["Service<br/>web"]
+  n1["Deployment<br/>web"]
+  n2["ServiceAccount<br/>web"]
+  n3["Pod<br/>web-0"]
+  n0 -.->|label| n1
+  n0 -.->|label| n3
+  n1 -->|control| n3
+  n1 -.->|label| n3
+  n3 ==>|spec| n2
+`
+
+	if got := buf.String(); got != exp {
+		t.Errorf("Mermaid export differs. Expected:\n%s\nGot:\n%s", exp, got)
+	}
+}
+
+func TestExportGraphML(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := parseExportYAML().Export(&buf, "web", grapher.FormatGraphML); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the export must be well-formed XML with a node per object and an edge per relation
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GraphML export is not valid XML: %v", err)
+	}
+
+	if len(doc.Graph.Nodes) != 4 {
+		t.Errorf("Number of nodes differs. Expected 4. Got %d", len(doc.Graph.Nodes))
+	}
+
+	if len(doc.Graph.Edges) != 5 {
+		t.Errorf("Number of edges differs. Expected 5. Got %d", len(doc.Graph.Edges))
+	}
+
+	if !strings.Contains(buf.String(), `<data key="kind">ServiceAccount</data>`) {
+		t.Errorf("GraphML export is missing node data:\n%s", buf.String())
+	}
+}
+
+func TestExportUnsupportedFormat(t *testing.T) {
+	if err := parseExportYAML().Export(ioutil.Discard, "web", "png"); err == nil {
+		t.Errorf("expected error for unsupported format, got nil")
+	}
+}
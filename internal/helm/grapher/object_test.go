@@ -18,6 +18,12 @@ var c2 = grapher.Object{
 	Name: "my-release-cassandra-headless",
 	Relations: grapher.Relations{
 		LabelRels: []grapher.LabelRel{
+			grapher.LabelRel{
+				Relation: grapher.Relation{
+					Source: 1,
+					Target: 3,
+				},
+			},
 			grapher.LabelRel{
 				Relation: grapher.Relation{
 					Source: 1,
@@ -39,6 +45,12 @@ var c3 = grapher.Object{
 	Name: "my-release-cassandra",
 	Relations: grapher.Relations{
 		LabelRels: []grapher.LabelRel{
+			grapher.LabelRel{
+				Relation: grapher.Relation{
+					Source: 2,
+					Target: 3,
+				},
+			},
 			grapher.LabelRel{
 				Relation: grapher.Relation{
 					Source: 2,
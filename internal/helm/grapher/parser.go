@@ -36,6 +36,16 @@ func getField(yaml map[string]interface{}, keys ...string) interface{} {
 	return getField(yaml[keys[0]].(map[string]interface{}), keys[1:len(keys)]...)
 }
 
+// getList returns the list at the nested keys, or an empty list if there is no
+// list at the nested keys
+func getList(yaml map[string]interface{}, keys ...string) []interface{} {
+	if list, ok := getField(yaml, keys...).([]interface{}); ok {
+		return list
+	}
+
+	return []interface{}{}
+}
+
 // recursively convert all key values in generic interface{} format into strings.
 // i.e. map[interface{}]interface{} --> map[string]interface{}
 func recursiveConv(m interface{}) interface{} {
@@ -145,8 +145,9 @@ func (parsed *ParsedObjs) GetLabelRel() {
 		yaml := o.RawYAML
 		matchLabels, matchExpressions := aggregateLabelSelectors(yaml)
 
-		// Find ID's of targets that match the label selector
-		targetID := parsed.findLabelsBySelector(o.ID, matchLabels, matchExpressions)
+		// Find ID's of targets that match the label selector. Services also select
+		// the controllers whose pods they route to.
+		targetID := parsed.findLabelsBySelector(o.ID, o.Kind == "Service", matchLabels, matchExpressions)
 		lrels := o.Relations.LabelRels
 		for _, tid := range targetID {
 			newrel := LabelRel{
@@ -170,60 +171,21 @@ func (parsed *ParsedObjs) GetSpecRel() {
 		case "ClusterRoleBinding", "RoleBinding":
 			tid = parsed.findRBACTargets(o.ID, o.RawYAML)
 		case "Ingress":
-			rules := getField(o.RawYAML, "spec", "rules")
-			if rules == nil {
-				rules = []interface{}{}
-			}
-			for _, r := range rules.([]interface{}) {
-				paths := getField(r.(map[string]interface{}), "http", "paths")
-				if paths == nil {
-					paths = []interface{}{}
-				}
-				for _, p := range paths.([]interface{}) {
-					// service and resource are mutually exclusive backend types.
-					name := getField(p.(map[string]interface{}), "backend", "serviceName")
-					kind := "Service"
-					if name == nil {
-						name = getField(p.(map[string]interface{}), "backend", "service", "name")
-					}
-					if name == nil {
-						name = getField(p.(map[string]interface{}), "backend", "resource", "name")
-						kind = getField(p.(map[string]interface{}), "backend", "resource", "kind").(string)
-					}
-					tid = parsed.findObjectByNameAndKind(o.ID, name, kind)
-				}
-			}
+			tid = parsed.findIngressTargets(o.ID, o.RawYAML)
 		case "StatefulSet":
 			serviceName := getField(o.RawYAML, "spec", "serviceName")
 			tid = append(tid, parsed.findObjectByNameAndKind(o.ID, serviceName, "Service")...)
 		case "Pod":
-			volume := getField(o.RawYAML, "spec", "volumes")
-			imageSecrets := getField(o.RawYAML, "spec", "ImagePullSecrets")
-			serviceAccount := getField(o.RawYAML, "spec", "serviceAccountName")
-
-			if imageSecrets == nil {
-				imageSecrets = []interface{}{}
-			}
+			tid = parsed.findPodSpecTargets(o.ID, o.RawYAML)
+		case "PersistentVolumeClaim":
+			storageClass := getField(o.RawYAML, "spec", "storageClassName")
 
-			if volume == nil {
-				volume = []interface{}{}
+			// fall back to the annotation that preceded spec.storageClassName
+			if storageClass == nil {
+				storageClass = getField(o.RawYAML, "metadata", "annotations", "volume.beta.kubernetes.io/storage-class")
 			}
 
-			for _, sec := range imageSecrets.([]interface{}) {
-				tid = append(tid, parsed.findObjectByNameAndKind(o.ID, sec, "Secret")...)
-			}
-			tid = append(tid, parsed.findObjectByNameAndKind(o.ID, serviceAccount, "ServiceAccount")...)
-
-			for _, v := range volume.([]interface{}) {
-				vt := v.(map[string]interface{})
-				configMap := getField(vt, "configMap", "name")
-				pvc := getField(vt, "persistentVolumeClaim", "claimName")
-				secret := getField(vt, "secret", "secretName")
-
-				tid = append(tid, parsed.findObjectByNameAndKind(o.ID, configMap, "ConfigMap")...)
-				tid = append(tid, parsed.findObjectByNameAndKind(o.ID, pvc, "PersistentVolumeClaim")...)
-				tid = append(tid, parsed.findObjectByNameAndKind(o.ID, secret, "Secret")...)
-			}
+			tid = parsed.findObjectByNameAndKind(o.ID, storageClass, "StorageClass")
 		}
 
 		// Add edges to parent
@@ -290,14 +252,167 @@ func aggregateLabelSelectors(yaml map[string]interface{}) ([]MatchLabel, []Match
 }
 
 // SpecRel helpers
+
+// objectRef is a reference from the spec of an object to another object
+type objectRef struct {
+	name interface{}
+	kind string
+}
+
+// appendRef adds a reference to refs unless it is empty or already present, so
+// that an object referenced in several places only gets a single edge
+func appendRef(refs []objectRef, name interface{}, kind string) []objectRef {
+	if name == nil {
+		return refs
+	}
+
+	for _, ref := range refs {
+		if ref.name == name && ref.kind == kind {
+			return refs
+		}
+	}
+
+	return append(refs, objectRef{name, kind})
+}
+
+func (parsed *ParsedObjs) findRefs(parentID int, refs []objectRef) []int {
+	targets := []int{}
+
+	for _, ref := range refs {
+		targets = append(targets, parsed.findObjectByNameAndKind(parentID, ref.name, ref.kind)...)
+	}
+
+	return targets
+}
+
+// findIngressTargets finds the Services, or resources, that an Ingress routes to
+// through its default backend and the backends of its rules
+func (parsed *ParsedObjs) findIngressTargets(parentID int, yaml map[string]interface{}) []int {
+	refs := []objectRef{}
+
+	// spec.backend was renamed to spec.defaultBackend in networking.k8s.io/v1
+	for _, key := range []string{"backend", "defaultBackend"} {
+		if backend, ok := getField(yaml, "spec", key).(map[string]interface{}); ok {
+			refs = appendBackendRef(refs, backend)
+		}
+	}
+
+	for _, r := range getList(yaml, "spec", "rules") {
+		rule, ok := r.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		for _, p := range getList(rule, "http", "paths") {
+			path, ok := p.(map[string]interface{})
+
+			if !ok {
+				continue
+			}
+
+			if backend, ok := getField(path, "backend").(map[string]interface{}); ok {
+				refs = appendBackendRef(refs, backend)
+			}
+		}
+	}
+
+	return parsed.findRefs(parentID, refs)
+}
+
+// appendBackendRef adds the target of an Ingress backend. Service and resource are
+// mutually exclusive backend types, and serviceName is the extensions/v1beta1 and
+// networking.k8s.io/v1beta1 form of a Service backend.
+func appendBackendRef(refs []objectRef, backend map[string]interface{}) []objectRef {
+	if name := getField(backend, "serviceName"); name != nil {
+		return appendRef(refs, name, "Service")
+	}
+
+	if name := getField(backend, "service", "name"); name != nil {
+		return appendRef(refs, name, "Service")
+	}
+
+	if kind, ok := getField(backend, "resource", "kind").(string); ok {
+		return appendRef(refs, getField(backend, "resource", "name"), kind)
+	}
+
+	return refs
+}
+
+// findPodSpecTargets finds the objects that a pod references: its service account
+// and image pull secrets, the ConfigMaps, Secrets and PVCs that it mounts, and the
+// ConfigMaps and Secrets that its containers read through env and envFrom
+func (parsed *ParsedObjs) findPodSpecTargets(parentID int, yaml map[string]interface{}) []int {
+	refs := []objectRef{}
+
+	for _, sec := range getList(yaml, "spec", "imagePullSecrets") {
+		if secret, ok := sec.(map[string]interface{}); ok {
+			refs = appendRef(refs, secret["name"], "Secret")
+		}
+	}
+
+	serviceAccount := getField(yaml, "spec", "serviceAccountName")
+
+	// spec.serviceAccount is the deprecated alias of spec.serviceAccountName
+	if serviceAccount == nil {
+		serviceAccount = getField(yaml, "spec", "serviceAccount")
+	}
+
+	refs = appendRef(refs, serviceAccount, "ServiceAccount")
+
+	for _, v := range getList(yaml, "spec", "volumes") {
+		vt, ok := v.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		refs = appendRef(refs, getField(vt, "configMap", "name"), "ConfigMap")
+		refs = appendRef(refs, getField(vt, "secret", "secretName"), "Secret")
+		refs = appendRef(refs, getField(vt, "persistentVolumeClaim", "claimName"), "PersistentVolumeClaim")
+
+		for _, src := range getList(vt, "projected", "sources") {
+			if st, ok := src.(map[string]interface{}); ok {
+				refs = appendRef(refs, getField(st, "configMap", "name"), "ConfigMap")
+				refs = appendRef(refs, getField(st, "secret", "name"), "Secret")
+			}
+		}
+	}
+
+	containers := append(getList(yaml, "spec", "initContainers"), getList(yaml, "spec", "containers")...)
+
+	for _, c := range containers {
+		ct, ok := c.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		for _, e := range getList(ct, "envFrom") {
+			if et, ok := e.(map[string]interface{}); ok {
+				refs = appendRef(refs, getField(et, "configMapRef", "name"), "ConfigMap")
+				refs = appendRef(refs, getField(et, "secretRef", "name"), "Secret")
+			}
+		}
+
+		for _, e := range getList(ct, "env") {
+			if et, ok := e.(map[string]interface{}); ok {
+				refs = appendRef(refs, getField(et, "valueFrom", "configMapKeyRef", "name"), "ConfigMap")
+				refs = appendRef(refs, getField(et, "valueFrom", "secretKeyRef", "name"), "Secret")
+			}
+		}
+	}
+
+	return parsed.findRefs(parentID, refs)
+}
+
 func (parsed *ParsedObjs) findObjectByNameAndKind(parentID int, name interface{}, kind string) []int {
 	targets := []int{}
 
-	if name == nil {
+	if _, ok := name.(string); !ok {
 		return targets
 	}
 
-	name = name.(string)
 	for i, o := range parsed.Objects {
 		newrel := SpecRel{
 			Relation{
@@ -360,20 +475,37 @@ func addMatchLabels(matchLabels []MatchLabel, ml map[string]interface{}) []Match
 }
 
 // TODO: Implement MatchExpression for set based operations.
-func (parsed *ParsedObjs) findLabelsBySelector(parentID int, ml []MatchLabel, me []MatchExpression) []int {
+func (parsed *ParsedObjs) findLabelsBySelector(
+	parentID int,
+	selectsControllers bool,
+	ml []MatchLabel,
+	me []MatchExpression,
+) []int {
 	matchedObjs := []int{}
 	for i, o := range parsed.Objects {
+		var labels interface{}
+
+		// Only Pods can be selected by spec.selector, and controllers are selected
+		// through the labels of their pod template
+		switch {
+		case o.Kind == "Pod":
+			labels = getField(o.RawYAML, "metadata", "labels")
+		case selectsControllers && isController(o.Kind):
+			labels = getField(o.RawYAML, "spec", "template", "metadata", "labels")
+		default:
+			continue
+		}
 
-		// Only Pods can be selected by spec.selector
-		if o.Kind != "Pod" {
+		labelMap, ok := labels.(map[string]interface{})
+
+		if !ok {
 			continue
 		}
 
-		// find Pods that match labels
-		labels := getField(o.RawYAML, "metadata", "labels")
+		// find objects that match labels
 		match := 0
 		for _, l := range ml {
-			if labels.(map[string]interface{})[l.key] == l.value {
+			if labelMap[l.key] == l.value {
 				match++
 			}
 		}
@@ -394,3 +526,14 @@ func (parsed *ParsedObjs) findLabelsBySelector(parentID int, ml []MatchLabel, me
 	}
 	return matchedObjs
 }
+
+// isController returns true for the controller kinds whose children are pods
+// created from the controller's template
+func isController(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "DaemonSet", "Job":
+		return true
+	}
+
+	return false
+}
@@ -27,6 +27,18 @@ var c7 = grapher.Object{
 			},
 		},
 		LabelRels: []grapher.LabelRel{
+			grapher.LabelRel{
+				Relation: grapher.Relation{
+					Source: 1,
+					Target: 3,
+				},
+			},
+			grapher.LabelRel{
+				Relation: grapher.Relation{
+					Source: 2,
+					Target: 3,
+				},
+			},
 			grapher.LabelRel{
 				Relation: grapher.Relation{
 					Source: 3,
@@ -272,3 +284,147 @@ func TestSpecRels(t *testing.T) {
 		// }
 	}
 }
+
+func specRels(rels ...[2]int) []grapher.SpecRel {
+	res := []grapher.SpecRel{}
+
+	for _, r := range rels {
+		res = append(res, grapher.SpecRel{
+			Relation: grapher.Relation{
+				Source: r[0],
+				Target: r[1],
+			},
+		})
+	}
+
+	return res
+}
+
+func labelRels(rels ...[2]int) []grapher.LabelRel {
+	res := []grapher.LabelRel{}
+
+	for _, r := range rels {
+		res = append(res, grapher.LabelRel{
+			Relation: grapher.Relation{
+				Source: r[0],
+				Target: r[1],
+			},
+		})
+	}
+
+	return res
+}
+
+// Expected objects for a web app wired to its storage, config and ingress
+var expWiringRels = []grapher.Object{
+	grapher.Object{
+		Kind: "StorageClass",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{1, 0}),
+		},
+	},
+	grapher.Object{
+		Kind: "PersistentVolumeClaim",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{1, 0}, [2]int{8, 1}),
+		},
+	},
+	grapher.Object{
+		Kind: "ConfigMap",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{8, 2}),
+		},
+	},
+	grapher.Object{
+		Kind: "Secret",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{8, 3}),
+		},
+	},
+	grapher.Object{
+		Kind: "Secret",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{8, 4}),
+		},
+	},
+	grapher.Object{
+		Kind: "Service",
+		Relations: grapher.Relations{
+			LabelRels: labelRels([2]int{5, 7}, [2]int{5, 8}),
+			SpecRels:  specRels([2]int{6, 5}),
+		},
+	},
+	grapher.Object{
+		Kind: "Ingress",
+		Relations: grapher.Relations{
+			SpecRels: specRels([2]int{6, 5}),
+		},
+	},
+	grapher.Object{
+		Kind: "Deployment",
+		Relations: grapher.Relations{
+			LabelRels: labelRels([2]int{5, 7}, [2]int{7, 8}),
+		},
+	},
+	grapher.Object{
+		Kind: "Pod",
+		Relations: grapher.Relations{
+			LabelRels: labelRels([2]int{5, 8}, [2]int{7, 8}),
+			SpecRels:  specRels([2]int{8, 2}, [2]int{8, 1}, [2]int{8, 4}, [2]int{8, 3}),
+		},
+	},
+}
+
+func TestWiringRels(t *testing.T) {
+	file, err := ioutil.ReadFile("./test_yaml/wiring.yaml")
+
+	if err != nil {
+		t.Fatalf("Error reading file ./test_yaml/wiring.yaml")
+	}
+
+	yamlArr := grapher.ImportMultiDocYAML(file)
+	objects := grapher.ParseObjs(yamlArr)
+	parsed := grapher.ParsedObjs{
+		Objects: objects,
+	}
+
+	parsed.GetControlRel()
+	parsed.GetLabelRel()
+	parsed.GetSpecRel()
+
+	if len(parsed.Objects) != len(expWiringRels) {
+		t.Fatalf("Number of objects differs. Expected %d. Got %d", len(expWiringRels), len(parsed.Objects))
+	}
+
+	for i, o := range parsed.Objects {
+		e := expWiringRels[i]
+
+		if e.Kind != o.Kind {
+			t.Errorf("Object kinds are different at position %d. Expected %s. Got %s", i, e.Kind, o.Kind)
+		}
+
+		if len(e.Relations.LabelRels) != len(o.Relations.LabelRels) {
+			t.Errorf("Number of LabelRel differs for %s of type %s. Expected %d. Got %d",
+				o.Name, o.Kind, len(e.Relations.LabelRels), len(o.Relations.LabelRels))
+		} else {
+			for j, lrel := range o.Relations.LabelRels {
+				if e.Relations.LabelRels[j].Relation != lrel.Relation {
+					t.Errorf("LabelRel differs for %s of type %s. Expected %v. Got %v",
+						o.Name, o.Kind, e.Relations.LabelRels[j].Relation, lrel.Relation)
+				}
+			}
+		}
+
+		if len(e.Relations.SpecRels) != len(o.Relations.SpecRels) {
+			t.Errorf("Number of SpecRel differs for %s of type %s. Expected %d. Got %d",
+				o.Name, o.Kind, len(e.Relations.SpecRels), len(o.Relations.SpecRels))
+		} else {
+			for j, srel := range o.Relations.SpecRels {
+				if e.Relations.SpecRels[j].Relation != srel.Relation {
+					t.Errorf("SpecRel differs for %s of type %s. Expected %v. Got %v",
+						o.Name, o.Kind, e.Relations.SpecRels[j].Relation, srel.Relation)
+				}
+			}
+		}
+	}
+}
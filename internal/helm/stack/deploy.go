@@ -0,0 +1,359 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	th "github.com/porter-dev/porter/internal/templater/helm"
+)
+
+// Deployer deploys the charts of a stack as a unit, each as its own release
+type Deployer struct {
+	Agent     *helm.Agent
+	Namespace string
+
+	// Template is the stack template, whose dependencies are used for the charts of
+	// the stack that are vendored in it
+	Template *chart.Chart
+
+	// RepoURL is the repo that the charts of the stack are loaded from with
+	// LoadChart, unless a chart sets its own repo
+	RepoURL   string
+	LoadChart func(repoURL, name, version string) (*chart.Chart, error)
+
+	// Install is set on the first deploy of a stack. A release that already exists
+	// with the name of the release of a chart does not belong to the stack, so the
+	// deploy fails rather than upgrading it.
+	Install bool
+
+	// Previous is the stack.yaml that the stack was last deployed with, which is
+	// set when the stack is upgraded. The releases of the charts that the stack no
+	// longer lists are uninstalled once the charts of the stack are deployed.
+	Previous *models.StackYAML
+
+	// charts caches the loaded charts by the name of the chart in the stack
+	charts map[string]*chart.Chart
+}
+
+// deployedChart is a chart that was deployed in a run of Deploy, or that failed to
+// deploy after its release was installed or upgraded, with the revision of its
+// release before the run, which is 0 if the release was installed
+type deployedChart struct {
+	release      string
+	prevRevision int
+}
+
+// Deploy installs the releases of the charts of a stack, or upgrades the releases
+// that already exist, in the order of the stack. The values of each chart are the
+// values of the chart in the stack.yaml, then the values referenced from the
+// releases of other charts, and then the values in vals that are keyed by the name
+// of the chart, which take precedence. If the deployer installs the stack, the
+// deploy fails if the release of any chart already exists.
+//
+// If a chart fails to deploy, its release and the releases deployed before it are
+// rolled back, so that the stack is left as it was: installed releases are
+// uninstalled, and upgraded releases are rolled back to their previous revision.
+// The releases of the charts dropped from the previous stack are uninstalled last,
+// and if one fails to uninstall the deployed charts are rolled back as well,
+// although the dropped releases already uninstalled are not reinstalled.
+func (d *Deployer) Deploy(
+	name string,
+	s *models.StackYAML,
+	vals map[string]interface{},
+) ([]*release.Release, error) {
+	charts, err := Order(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*release.Release, 0, len(charts))
+	byName := make(map[string]*release.Release)
+	deployed := make([]*deployedChart, 0, len(charts))
+
+	for _, c := range charts {
+		rel, dc, err := d.deployChart(name, c, vals, byName)
+
+		if err != nil {
+			err = fmt.Errorf("could not deploy chart %s: %v", c.Name, err)
+
+			// the release of the failed chart is rolled back as well, if it was
+			// installed or upgraded
+			if dc != nil {
+				deployed = append(deployed, dc)
+			}
+
+			if rollbackErr := d.rollback(deployed); rollbackErr != nil {
+				err = fmt.Errorf("%v, and could not roll back the stack: %v", err, rollbackErr)
+			}
+
+			return nil, err
+		}
+
+		deployed = append(deployed, dc)
+		byName[c.Name] = rel
+		res = append(res, rel)
+	}
+
+	if err := d.uninstallDropped(name, s); err != nil {
+		if rollbackErr := d.rollback(deployed); rollbackErr != nil {
+			err = fmt.Errorf("%v, and could not roll back the stack: %v", err, rollbackErr)
+		}
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// uninstallDropped uninstalls the releases of the charts of the previous stack that
+// the stack no longer lists, in the reverse of the order they were deployed in
+func (d *Deployer) uninstallDropped(name string, s *models.StackYAML) error {
+	if d.Previous == nil {
+		return nil
+	}
+
+	prev, err := Order(d.Previous)
+
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]bool)
+
+	for _, c := range s.Charts {
+		listed[c.Name] = true
+	}
+
+	for i := len(prev) - 1; i >= 0; i-- {
+		if listed[prev[i].Name] {
+			continue
+		}
+
+		relName := ReleaseName(name, prev[i].Name)
+
+		if _, err := d.Agent.GetRelease(relName, 0); err == driver.ErrReleaseNotFound {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not read release %s: %v", relName, err)
+		}
+
+		if err := d.Agent.UninstallRelease(relName); err != nil {
+			return fmt.Errorf("could not uninstall release %s of dropped chart %s: %v", relName, prev[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// deployChart installs or upgrades the release of a chart. Once the release is
+// installed or upgraded, the chart is returned as deployed even if it fails, so
+// that its release can be rolled back.
+func (d *Deployer) deployChart(
+	name string,
+	c *models.StackChart,
+	vals map[string]interface{},
+	byName map[string]*release.Release,
+) (*release.Release, *deployedChart, error) {
+	ch, err := d.Chart(c)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chartVals := utils.CopyValues(c.Values)
+
+	for _, ref := range c.ValuesFrom {
+		val, err := referenceValue(ref, byName[ref.Chart])
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		utils.SetValue(chartVals, ref.Variable, val)
+	}
+
+	overrides, _ := vals[c.Name].(map[string]interface{})
+	chartVals = utils.CoalesceValues(chartVals, utils.CopyValues(overrides))
+
+	conf := &helm.InstallChartConfig{
+		Chart:     ch,
+		Name:      ReleaseName(name, c.Name),
+		Namespace: d.Namespace,
+		Values:    chartVals,
+	}
+
+	prev, err := d.Agent.GetRelease(conf.Name, 0)
+
+	if err == driver.ErrReleaseNotFound {
+		rel, err := d.Agent.InstallChart(conf)
+
+		return rel, &deployedChart{conf.Name, 0}, err
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("could not read release %s: %v", conf.Name, err)
+	}
+
+	if d.Install {
+		return nil, nil, fmt.Errorf("release %s already exists", conf.Name)
+	}
+
+	rel, err := d.Agent.UpgradeChart(conf)
+
+	return rel, &deployedChart{conf.Name, prev.Version}, err
+}
+
+// Chart returns the chart of a chart of a stack, from the dependencies of the stack
+// template if it is vendored there, and otherwise from the repo of the chart
+func (d *Deployer) Chart(c *models.StackChart) (*chart.Chart, error) {
+	if ch, ok := d.charts[c.Name]; ok {
+		return ch, nil
+	}
+
+	ch, err := d.loadChart(c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if d.charts == nil {
+		d.charts = make(map[string]*chart.Chart)
+	}
+
+	d.charts[c.Name] = ch
+
+	return ch, nil
+}
+
+func (d *Deployer) loadChart(c *models.StackChart) (*chart.Chart, error) {
+	if d.Template != nil {
+		for _, dep := range d.Template.Dependencies() {
+			if dep.Name() == c.Chart && (c.Version == "" || dep.Metadata.Version == c.Version) {
+				return detach(dep), nil
+			}
+		}
+	}
+
+	repoURL := c.RepoURL
+
+	if repoURL == "" {
+		repoURL = d.RepoURL
+	}
+
+	if d.LoadChart == nil {
+		return nil, fmt.Errorf("chart %s is not in the stack template", c.Chart)
+	}
+
+	return d.LoadChart(repoURL, c.Chart, c.Version)
+}
+
+// DefaultValues returns the values that the values of a chart of a stack fall back
+// to: the values of the chart in the stack.yaml, over the default values of the chart
+func DefaultValues(c *models.StackChart, ch *chart.Chart) map[string]interface{} {
+	return utils.CoalesceValues(utils.CopyValues(ch.Values), utils.CopyValues(c.Values))
+}
+
+// detach copies a chart vendored in the stack template without its parent, so that
+// it is rendered as a chart of its own rather than as a subchart of the template
+func detach(ch *chart.Chart) *chart.Chart {
+	res := &chart.Chart{
+		Raw:       ch.Raw,
+		Metadata:  ch.Metadata,
+		Lock:      ch.Lock,
+		Templates: ch.Templates,
+		Values:    ch.Values,
+		Schema:    ch.Schema,
+		Files:     ch.Files,
+	}
+
+	res.SetDependencies(ch.Dependencies()...)
+
+	return res
+}
+
+// rollback rolls back the charts deployed in a failed run of Deploy, in reverse
+func (d *Deployer) rollback(deployed []*deployedChart) error {
+	errs := make([]string, 0)
+
+	for i := len(deployed) - 1; i >= 0; i-- {
+		var err error
+
+		if deployed[i].prevRevision == 0 {
+			// a chart that failed to install may not have created its release
+			if _, getErr := d.Agent.GetRelease(deployed[i].release, 0); getErr == driver.ErrReleaseNotFound {
+				continue
+			}
+
+			err = d.Agent.UninstallRelease(deployed[i].release)
+		} else {
+			err = d.Agent.RollbackRelease(deployed[i].release, deployed[i].prevRevision)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", deployed[i].release, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// referenceValue reads the value of a reference from the release of another chart
+// of the stack. The query is run against the name, namespace, values and manifests
+// of the release. A query that matches one value returns that value, and a query
+// that matches several values returns the list of them.
+func referenceValue(ref *models.StackValueReference, rel *release.Release) (interface{}, error) {
+	if rel == nil {
+		return nil, fmt.Errorf("chart %s is not deployed", ref.Chart)
+	}
+
+	values, err := (&th.ValuesTemplateReader{Release: rel}).ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := (&th.ManifestsTemplateReader{Release: rel}).ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := utils.NewQuery(ref.Variable, ref.Query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := utils.QueryValues(map[string]interface{}{
+		"name":      rel.Name,
+		"namespace": rel.Namespace,
+		"values":    values,
+		"manifests": manifests["manifests"],
+	}, []*templater.TemplateReaderQuery{query})
+
+	if err != nil {
+		return nil, err
+	}
+
+	results, _ := data[ref.Variable].([]interface{})
+
+	switch len(results) {
+	case 0:
+		return nil, fmt.Errorf("query for %s matched no value of chart %s", ref.Variable, ref.Chart)
+	case 1:
+		return results[0], nil
+	}
+
+	return results, nil
+}
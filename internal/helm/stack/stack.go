@@ -0,0 +1,144 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
+)
+
+// FileName is the file of a chart that marks it as a stack template
+const FileName = "stack.yaml"
+
+// FromChart returns the stack.yaml of a stack template, or nil if the chart is not
+// a stack template
+func FromChart(ch *chart.Chart) (*models.StackYAML, error) {
+	for _, file := range ch.Files {
+		if file.Name == FileName {
+			return FromBytes(file.Data)
+		}
+	}
+
+	return nil, nil
+}
+
+// FromBytes parses a stack.yaml, and checks that the charts of the stack can be
+// ordered
+func FromBytes(bytes []byte) (*models.StackYAML, error) {
+	s := &models.StackYAML{}
+
+	if err := yaml.Unmarshal(bytes, s); err != nil {
+		return nil, err
+	}
+
+	if _, err := Order(s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ReleaseName returns the name of the release of a chart in a stack
+func ReleaseName(stackName, chartName string) string {
+	return fmt.Sprintf("%s-%s", stackName, chartName)
+}
+
+// Order returns the charts of a stack in the order they are deployed, which is the
+// order they are listed in, except that each chart is deployed after the charts it
+// depends on or references values from. An error is returned if the charts are
+// invalid or have a circular dependency.
+func Order(s *models.StackYAML) ([]*models.StackChart, error) {
+	if len(s.Charts) == 0 {
+		return nil, fmt.Errorf("stack must list at least one chart")
+	}
+
+	byName := make(map[string]*models.StackChart)
+
+	for _, c := range s.Charts {
+		if c.Name == "" || c.Chart == "" {
+			return nil, fmt.Errorf("every chart of the stack must set a name and a chart")
+		} else if _, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("chart %s is listed more than once", c.Name)
+		}
+
+		byName[c.Name] = c
+	}
+
+	deps := make(map[string][]string)
+
+	for _, c := range s.Charts {
+		for _, dep := range dependencies(c) {
+			if _, ok := byName[dep]; !ok || dep == c.Name {
+				return nil, fmt.Errorf("chart %s depends on unknown chart %s", c.Name, dep)
+			}
+
+			deps[c.Name] = append(deps[c.Name], dep)
+		}
+
+		for _, ref := range c.ValuesFrom {
+			if ref.Variable == "" || ref.Query == "" {
+				return nil, fmt.Errorf("values of chart %s must set a variable and a query", c.Name)
+			}
+
+			if _, err := utils.NewQuery(ref.Variable, ref.Query); err != nil {
+				return nil, fmt.Errorf("invalid query for %s of chart %s: %v", ref.Variable, c.Name, err)
+			}
+		}
+	}
+
+	res := make([]*models.StackChart, 0, len(s.Charts))
+	placed := make(map[string]bool)
+
+	for len(res) < len(s.Charts) {
+		next := -1
+
+		for i, c := range s.Charts {
+			if placed[c.Name] {
+				continue
+			}
+
+			ready := true
+
+			for _, dep := range deps[c.Name] {
+				ready = ready && placed[dep]
+			}
+
+			if ready {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			unplaced := make([]string, 0)
+
+			for _, c := range s.Charts {
+				if !placed[c.Name] {
+					unplaced = append(unplaced, c.Name)
+				}
+			}
+
+			return nil, fmt.Errorf("charts %s have a circular dependency", strings.Join(unplaced, ", "))
+		}
+
+		placed[s.Charts[next].Name] = true
+		res = append(res, s.Charts[next])
+	}
+
+	return res, nil
+}
+
+// dependencies returns the charts that a chart depends on, including the charts
+// that it references values from
+func dependencies(c *models.StackChart) []string {
+	res := append([]string{}, c.DependsOn...)
+
+	for _, ref := range c.ValuesFrom {
+		res = append(res, ref.Chart)
+	}
+
+	return res
+}
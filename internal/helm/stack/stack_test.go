@@ -0,0 +1,413 @@
+package stack_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/stack"
+	"github.com/porter-dev/porter/internal/logger"
+	"helm.sh/helm/v3/pkg/chart"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+const stackYAML = `name: shop
+charts:
+- name: app
+  chart: web
+  depends_on:
+  - cache
+  values:
+    replicas: 2
+  values_from:
+  - variable: env.DB_HOST
+    chart: db
+    query: '{ .manifests[?(@.kind=="Service")].metadata.name }'
+  - variable: env.DB_PORT
+    chart: db
+    query: '{ .values.service.port }'
+- name: db
+  chart: postgres
+- name: cache
+  chart: redis
+`
+
+const serviceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  ports:
+  - port: {{ .Values.service.port }}
+`
+
+const hookTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Release.Name }}-migrate
+  annotations:
+    helm.sh/hook: pre-install
+`
+
+func newChart(name, chartType string, values map[string]interface{}, templates ...*chart.File) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       name,
+			Version:    "0.1.0",
+			Type:       chartType,
+		},
+		Values:    values,
+		Templates: templates,
+	}
+}
+
+func newTemplate(t *testing.T, stackBytes string, charts ...*chart.Chart) *chart.Chart {
+	t.Helper()
+
+	ch := newChart("shop-stack", "application", nil)
+	ch.Files = []*chart.File{
+		&chart.File{Name: stack.FileName, Data: []byte(stackBytes)},
+	}
+
+	ch.AddDependency(charts...)
+
+	return ch
+}
+
+func newDeployer(t *testing.T, template *chart.Chart) *stack.Deployer {
+	t.Helper()
+
+	form := &helm.Form{
+		Namespace: "default",
+	}
+
+	return &stack.Deployer{
+		Agent:     helm.GetAgentTesting(form, nil, logger.NewConsole(true)),
+		Namespace: "default",
+		Template:  template,
+	}
+}
+
+func defaultCharts() []*chart.Chart {
+	return []*chart.Chart{
+		newChart("web", "application", map[string]interface{}{"replicas": 1}),
+		newChart(
+			"postgres",
+			"application",
+			map[string]interface{}{
+				"service": map[string]interface{}{"port": 5432},
+			},
+			&chart.File{Name: "templates/service.yaml", Data: []byte(serviceTemplate)},
+		),
+		newChart("redis", "application", nil),
+	}
+}
+
+func TestOrder(t *testing.T) {
+	s, err := stack.FromBytes([]byte(stackYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	charts, err := stack.Order(s)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	names := make([]string, 0)
+
+	for _, c := range charts {
+		names = append(names, c.Name)
+	}
+
+	if expNames := []string{"db", "cache", "app"}; !reflect.DeepEqual(names, expNames) {
+		t.Errorf("wrong order: expected %v, got %v", expNames, names)
+	}
+}
+
+func TestOrderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stack string
+	}{
+		{
+			name:  "no charts",
+			stack: "name: shop\n",
+		},
+		{
+			name:  "duplicate chart",
+			stack: "charts:\n- name: db\n  chart: postgres\n- name: db\n  chart: mysql\n",
+		},
+		{
+			name:  "unknown dependency",
+			stack: "charts:\n- name: app\n  chart: web\n  depends_on: [db]\n",
+		},
+		{
+			name: "circular dependency",
+			stack: "charts:\n- name: app\n  chart: web\n  depends_on: [db]\n" +
+				"- name: db\n  chart: postgres\n  values_from:\n  - variable: host\n    chart: app\n    query: '{ .name }'\n",
+		},
+		{
+			name:  "invalid query",
+			stack: "charts:\n- name: db\n  chart: postgres\n- name: app\n  chart: web\n  values_from:\n  - variable: host\n    chart: db\n    query: '{ .name'\n",
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := stack.FromBytes([]byte(test.stack)); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
+
+func TestFromChart(t *testing.T) {
+	if s, err := stack.FromChart(newChart("web", "application", nil)); s != nil || err != nil {
+		t.Errorf("expected no stack for a chart without %s, got %v, %v", stack.FileName, s, err)
+	}
+
+	s, err := stack.FromChart(newTemplate(t, stackYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if s.Name != "shop" || len(s.Charts) != 3 {
+		t.Errorf("wrong stack: %v", s)
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	template := newTemplate(t, stackYAML, defaultCharts()...)
+	d := newDeployer(t, template)
+
+	s, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	rels, err := d.Deploy("shop", s, map[string]interface{}{
+		"app": map[string]interface{}{
+			"replicas": 3,
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(rels) != 3 || rels[2].Name != "shop-app" {
+		t.Fatalf("wrong releases deployed: %v", rels)
+	}
+
+	expConfig := map[string]interface{}{
+		"replicas": 3,
+		"env": map[string]interface{}{
+			"DB_HOST": "shop-db",
+			"DB_PORT": 5432,
+		},
+	}
+
+	// the values are compared printed, since numbers may be ints or floats
+	if fmt.Sprint(rels[2].Config) != fmt.Sprint(expConfig) {
+		t.Errorf("wrong values for app: expected %v, got %v", expConfig, rels[2].Config)
+	}
+
+	// deploying the stack again upgrades each release
+	rels, err = d.Deploy("shop", s, nil)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, rel := range rels {
+		if rel.Version != 2 {
+			t.Errorf("expected %s to be upgraded to revision 2, got %d", rel.Name, rel.Version)
+		}
+	}
+
+	status, err := stack.GetStatus(d.Agent, "shop", s)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if status.Status != stack.StatusDeployed || len(status.Charts) != 3 {
+		t.Errorf("wrong status: %v", status)
+	}
+
+	for _, chartStatus := range status.Charts {
+		if chartStatus.Revision != 2 || chartStatus.ChartVersion != "0.1.0" {
+			t.Errorf("wrong status for %s: %v", chartStatus.Name, chartStatus)
+		}
+	}
+}
+
+func TestDeployDroppedChart(t *testing.T) {
+	template := newTemplate(t, stackYAML, defaultCharts()...)
+	d := newDeployer(t, template)
+
+	prev, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := d.Deploy("shop", prev, nil); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the upgraded stack no longer lists the cache
+	s, err := stack.FromBytes([]byte(`name: shop
+charts:
+- name: app
+  chart: web
+- name: db
+  chart: postgres
+`))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	d.Previous = prev
+
+	if _, err := d.Deploy("shop", s, nil); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := d.Agent.GetRelease("shop-cache", 0); err != driver.ErrReleaseNotFound {
+		t.Errorf("expected release of dropped cache to be uninstalled, got %v", err)
+	}
+
+	for _, name := range []string{"shop-app", "shop-db"} {
+		if rel, err := d.Agent.GetRelease(name, 0); err != nil || rel.Version != 2 {
+			t.Errorf("expected %s to be upgraded to revision 2, got %v", name, err)
+		}
+	}
+}
+
+func TestDeployRollback(t *testing.T) {
+	charts := defaultCharts()
+
+	// library charts cannot be installed, so the app fails to deploy
+	charts[0].Metadata.Type = "library"
+
+	template := newTemplate(t, stackYAML, charts...)
+	d := newDeployer(t, template)
+
+	s, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := d.Deploy("shop", s, nil); err == nil {
+		t.Fatalf("expected error deploying app")
+	}
+
+	for _, name := range []string{"shop-db", "shop-cache"} {
+		if _, err := d.Agent.GetRelease(name, 0); err == nil {
+			t.Errorf("expected %s to be uninstalled", name)
+		}
+	}
+
+	status, err := stack.GetStatus(d.Agent, "shop", s)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if status.Status != stack.StatusDegraded || status.Charts[0].Status != stack.StatusMissing {
+		t.Errorf("wrong status: %v", status)
+	}
+}
+
+func TestDeployRollbackFailedChart(t *testing.T) {
+	charts := defaultCharts()
+
+	// the hook of the db is created after its release is stored
+	charts[1].Templates = append(charts[1].Templates, &chart.File{
+		Name: "templates/hook.yaml",
+		Data: []byte(hookTemplate),
+	})
+
+	template := newTemplate(t, stackYAML, charts...)
+	d := newDeployer(t, template)
+
+	s, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	d.Agent.ActionConfig.KubeClient.(*kubefake.FailingKubeClient).CreateError = errors.New("create failed")
+
+	if _, err := d.Deploy("shop", s, nil); err == nil {
+		t.Fatalf("expected error deploying db")
+	}
+
+	if _, err := d.Agent.GetRelease("shop-db", 0); err != driver.ErrReleaseNotFound {
+		t.Errorf("expected failed release of db to be uninstalled, got %v", err)
+	}
+}
+
+func TestDeployMissingReference(t *testing.T) {
+	charts := defaultCharts()
+
+	// the db has no service to reference
+	charts[1].Templates = nil
+
+	template := newTemplate(t, stackYAML, charts...)
+	d := newDeployer(t, template)
+
+	s, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := d.Deploy("shop", s, nil); err == nil {
+		t.Errorf("expected error for reference that matches no value")
+	}
+}
+
+func TestDeployInstallExistingRelease(t *testing.T) {
+	template := newTemplate(t, stackYAML, defaultCharts()...)
+	d := newDeployer(t, template)
+
+	s, err := stack.FromChart(template)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// a release that does not belong to the stack has the name of the db's release
+	_, err = d.Agent.InstallChart(&helm.InstallChartConfig{
+		Chart:     newChart("postgres", "application", nil),
+		Name:      "shop-db",
+		Namespace: "default",
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	d.Install = true
+
+	if _, err := d.Deploy("shop", s, nil); err == nil {
+		t.Fatalf("expected error installing stack over an existing release")
+	}
+
+	rel, err := d.Agent.GetRelease("shop-db", 0)
+
+	if err != nil || rel.Version != 1 {
+		t.Errorf("expected existing release to be left as it was, got %v, %v", rel, err)
+	}
+}
@@ -0,0 +1,116 @@
+package stack
+
+import (
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// the combined statuses of a stack
+const (
+	// StatusDeployed is set if the releases of all the charts are deployed
+	StatusDeployed = "deployed"
+
+	// StatusFailed is set if the release of any chart failed
+	StatusFailed = "failed"
+
+	// StatusProgressing is set if the release of any chart is being installed,
+	// upgraded or rolled back
+	StatusProgressing = "progressing"
+
+	// StatusDegraded is set if the release of any chart is missing or uninstalled
+	StatusDegraded = "degraded"
+)
+
+// StatusMissing is the status of a chart that has no release
+const StatusMissing = "missing"
+
+// ChartStatus is the status of the release of a chart of a stack
+type ChartStatus struct {
+	Name         string `json:"name"`
+	Release      string `json:"release"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chart_version,omitempty"`
+	Revision     int    `json:"revision,omitempty"`
+	Status       string `json:"status"`
+}
+
+// Status is the combined status of the releases of a stack, with the status of each
+// chart in the order the charts are deployed
+type Status struct {
+	Status string         `json:"status"`
+	Charts []*ChartStatus `json:"charts"`
+}
+
+// GetStatus reads the latest release of each chart of a stack, and combines their
+// statuses into the status of the stack
+func GetStatus(agent *helm.Agent, name string, s *models.StackYAML) (*Status, error) {
+	charts, err := Order(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Status{
+		Status: StatusDeployed,
+		Charts: make([]*ChartStatus, 0, len(charts)),
+	}
+
+	for _, c := range charts {
+		chartStatus := &ChartStatus{
+			Name:    c.Name,
+			Release: ReleaseName(name, c.Name),
+			Chart:   c.Chart,
+			Status:  StatusMissing,
+		}
+
+		rel, err := agent.GetRelease(chartStatus.Release, 0)
+
+		if err != nil && err != driver.ErrReleaseNotFound {
+			return nil, err
+		}
+
+		if err == nil {
+			chartStatus.Revision = rel.Version
+			chartStatus.Status = rel.Info.Status.String()
+
+			if rel.Chart != nil && rel.Chart.Metadata != nil {
+				chartStatus.ChartVersion = rel.Chart.Metadata.Version
+			}
+		}
+
+		res.Charts = append(res.Charts, chartStatus)
+
+		if status := combinedStatus(chartStatus.Status); statusRank[status] > statusRank[res.Status] {
+			res.Status = status
+		}
+	}
+
+	return res, nil
+}
+
+// statusRank ranks the combined statuses, so that the status of a stack is the
+// worst status of its charts
+var statusRank = map[string]int{
+	StatusDeployed:    0,
+	StatusProgressing: 1,
+	StatusDegraded:    2,
+	StatusFailed:      3,
+}
+
+// combinedStatus maps the status of the release of a chart to the status it
+// contributes to the stack
+func combinedStatus(status string) string {
+	switch release.Status(status) {
+	case release.StatusDeployed:
+		return StatusDeployed
+	case release.StatusFailed:
+		return StatusFailed
+	case release.StatusPendingInstall, release.StatusPendingUpgrade, release.StatusPendingRollback,
+		release.StatusUninstalling:
+		return StatusProgressing
+	}
+
+	return StatusDegraded
+}
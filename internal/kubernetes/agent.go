@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/client-go/informers"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
@@ -23,6 +27,7 @@ import (
 type Agent struct {
 	RESTClientGetter genericclioptions.RESTClientGetter
 	Clientset        kubernetes.Interface
+	DynamicClient    dynamic.Interface
 }
 
 type Message struct {
@@ -33,6 +38,7 @@ type Message struct {
 
 type ListOptions struct {
 	FieldSelector string
+	LabelSelector string
 }
 
 // ListNamespaces simply lists namespaces
@@ -43,6 +49,43 @@ func (a *Agent) ListNamespaces() (*v1.NamespaceList, error) {
 	)
 }
 
+// CreateNamespace creates a namespace with the given name, labels and annotations
+func (a *Agent) CreateNamespace(ns *v1.Namespace) (*v1.Namespace, error) {
+	return a.Clientset.CoreV1().Namespaces().Create(
+		context.TODO(),
+		ns,
+		metav1.CreateOptions{},
+	)
+}
+
+// DeleteNamespace deletes a namespace by name, which deletes all objects in
+// that namespace
+func (a *Agent) DeleteNamespace(name string) error {
+	return a.Clientset.CoreV1().Namespaces().Delete(
+		context.TODO(),
+		name,
+		metav1.DeleteOptions{},
+	)
+}
+
+// CreateResourceQuota creates a resource quota in the given namespace
+func (a *Agent) CreateResourceQuota(namespace string, quota *v1.ResourceQuota) (*v1.ResourceQuota, error) {
+	return a.Clientset.CoreV1().ResourceQuotas(namespace).Create(
+		context.TODO(),
+		quota,
+		metav1.CreateOptions{},
+	)
+}
+
+// CreateLimitRange creates a limit range in the given namespace
+func (a *Agent) CreateLimitRange(namespace string, limitRange *v1.LimitRange) (*v1.LimitRange, error) {
+	return a.Clientset.CoreV1().LimitRanges(namespace).Create(
+		context.TODO(),
+		limitRange,
+		metav1.CreateOptions{},
+	)
+}
+
 // GetDeployment gets the depployment given the name and namespace
 func (a *Agent) GetDeployment(c grapher.Object) (*appsv1.Deployment, error) {
 	return a.Clientset.AppsV1().Deployments(c.Namespace).Get(
@@ -90,6 +133,97 @@ func (a *Agent) GetPodsByLabel(selector string) (*v1.PodList, error) {
 	)
 }
 
+// RestartController triggers a rolling restart of a Deployment, StatefulSet or DaemonSet
+// by setting the restartedAt annotation on its pod template, the same way
+// "kubectl rollout restart" does
+func (a *Agent) RestartController(kind string, namespace string, name string) error {
+	patch := []byte(fmt.Sprintf(
+		`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339),
+	))
+
+	var err error
+
+	switch strings.ToLower(kind) {
+	case "deployment":
+		_, err = a.Clientset.AppsV1().Deployments(namespace).Patch(
+			context.TODO(),
+			name,
+			types.StrategicMergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	case "statefulset":
+		_, err = a.Clientset.AppsV1().StatefulSets(namespace).Patch(
+			context.TODO(),
+			name,
+			types.StrategicMergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	case "daemonset":
+		_, err = a.Clientset.AppsV1().DaemonSets(namespace).Patch(
+			context.TODO(),
+			name,
+			types.StrategicMergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	default:
+		return fmt.Errorf("cannot restart controller of kind %s", kind)
+	}
+
+	return err
+}
+
+// ScaleController sets the number of replicas of a Deployment, StatefulSet or ReplicaSet
+func (a *Agent) ScaleController(kind string, namespace string, name string, replicas int32) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+
+	var err error
+
+	switch strings.ToLower(kind) {
+	case "deployment":
+		_, err = a.Clientset.AppsV1().Deployments(namespace).Patch(
+			context.TODO(),
+			name,
+			types.MergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	case "statefulset":
+		_, err = a.Clientset.AppsV1().StatefulSets(namespace).Patch(
+			context.TODO(),
+			name,
+			types.MergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	case "replicaset":
+		_, err = a.Clientset.AppsV1().ReplicaSets(namespace).Patch(
+			context.TODO(),
+			name,
+			types.MergePatchType,
+			patch,
+			metav1.PatchOptions{},
+		)
+	default:
+		return fmt.Errorf("cannot scale controller of kind %s", kind)
+	}
+
+	return err
+}
+
+// DeletePod deletes a single pod by name and namespace. If the pod is managed by
+// a controller, it will be recreated.
+func (a *Agent) DeletePod(namespace string, name string) error {
+	return a.Clientset.CoreV1().Pods(namespace).Delete(
+		context.TODO(),
+		name,
+		metav1.DeleteOptions{},
+	)
+}
+
 // GetPodLogs streams real-time logs from a given pod.
 func (a *Agent) GetPodLogs(namespace string, name string, conn *websocket.Conn) error {
 	// follow logs
@@ -152,64 +286,82 @@ func (a *Agent) GetPodLogs(namespace string, name string, conn *websocket.Conn)
 	}
 }
 
-// StreamControllerStatus streams controller status. Supports Deployment, StatefulSet, ReplicaSet, and DaemonSet
+// controllerResources maps the controller kinds that support status streaming to
+// their resources
+var controllerResources = map[string]schema.GroupVersionResource{
+	"deployment":  appsv1.SchemeGroupVersion.WithResource("deployments"),
+	"statefulset": appsv1.SchemeGroupVersion.WithResource("statefulsets"),
+	"replicaset":  appsv1.SchemeGroupVersion.WithResource("replicasets"),
+	"daemonset":   appsv1.SchemeGroupVersion.WithResource("daemonsets"),
+}
+
+// StreamControllerStatus streams controller status. Supports Deployment, StatefulSet, ReplicaSet, and DaemonSet.
+// The informer for the controller kind is shared through informers, which may be nil
+// to start an informer for this stream only.
 // TODO: Support Jobs
-func (a *Agent) StreamControllerStatus(conn *websocket.Conn, kind string) error {
-	factory := informers.NewSharedInformerFactory(
-		a.Clientset,
-		10,
-	)
-	var informer cache.SharedInformer
+func (a *Agent) StreamControllerStatus(
+	conn *websocket.Conn,
+	kind string,
+	informers informer.Subscriber,
+) error {
+	// Convert to lowercase for robustness
+	kind = strings.ToLower(kind)
+	gvr, ok := controllerResources[kind]
 
-	// Spins up an informer depending on kind. Convert to lowercase for robustness
-	switch strings.ToLower(kind) {
-	case "deployment":
-		informer = factory.Apps().V1().Deployments().Informer()
-	case "statefulset":
-		informer = factory.Apps().V1().StatefulSets().Informer()
-	case "replicaset":
-		informer = factory.Apps().V1().ReplicaSets().Informer()
-	case "daemonset":
-		informer = factory.Apps().V1().DaemonSets().Informer()
+	if !ok {
+		return fmt.Errorf("cannot stream status of controller of kind %s", kind)
 	}
 
-	stopper := make(chan struct{})
-	errorchan := make(chan error)
-	defer close(errorchan)
+	if informers == nil {
+		informers = informer.NewRegistry(0).ForCluster(0, a.DynamicClient)
+	}
+
+	// only the first error is read, so later senders must not block
+	errorchan := make(chan error, 1)
 
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	sendErr := func(err error) {
+		select {
+		case errorchan <- err:
+		default:
+		}
+	}
+
+	unsubscribe, err := informers.Subscribe(gvr, "", cache.ResourceEventHandlerFuncs{
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			msg := Message{
 				EventType: "UPDATE",
 				Object:    newObj,
-				Kind:      strings.ToLower(kind),
+				Kind:      kind,
 			}
+
 			if writeErr := conn.WriteJSON(msg); writeErr != nil {
-				errorchan <- writeErr
-				return
+				sendErr(writeErr)
 			}
 		},
 	})
 
+	if err != nil {
+		return err
+	}
+
+	defer unsubscribe()
+
 	go func() {
 		// listens for websocket closing handshake
 		for {
 			if _, _, err := conn.ReadMessage(); err != nil {
-				defer conn.Close()
-				defer close(stopper)
-				defer fmt.Println("Successfully closed controller status stream")
-				errorchan <- nil
+				sendErr(nil)
 				return
 			}
 		}
 	}()
 
-	go informer.Run(stopper)
+	err = <-errorchan
+	conn.Close()
 
-	for {
-		select {
-		case err := <-errorchan:
-			return err
-		}
+	if err == nil {
+		fmt.Println("Successfully closed controller status stream")
 	}
+
+	return err
 }
@@ -0,0 +1,328 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// DefaultAgentCacheTTL is the longest time that an Agent is cached for, even if the
+// credentials of its cluster have not changed
+const DefaultAgentCacheTTL = 30 * time.Minute
+
+// tokenExpiryMargin is how long before a cached bearer token expires that the
+// agent using it is rebuilt
+const tokenExpiryMargin = time.Minute
+
+// AgentCache caches Agents by cluster ID and impersonated identity, so that the REST config, clients and
+// discovery information are not rebuilt for every request. A cached Agent is only
+// reused while the credential version of its cluster is unchanged, and expires
+// before the bearer token it was built with.
+type AgentCache struct {
+	TTL time.Duration
+
+	// DiscoveryCacheDir is the directory under which the discovery caches of
+	// the cached agents are stored
+	DiscoveryCacheDir string
+
+	// Tunnels opens connections to clusters that use the agent auth mechanism
+	Tunnels TunnelDialer
+
+	mu     sync.Mutex
+	agents map[agentKey]*cachedAgent
+}
+
+// agentKey identifies the cached Agent of a cluster for an impersonated identity,
+// since the REST config of an Agent carries the identity it acts as
+type agentKey struct {
+	clusterID uint
+	identity  string
+}
+
+func newAgentKey(conf *OutOfClusterConfig) agentKey {
+	key := agentKey{clusterID: conf.Cluster.ID}
+
+	if conf.Impersonate != nil {
+		key.identity = conf.Impersonate.Identity()
+	}
+
+	return key
+}
+
+type cachedAgent struct {
+	agent   *Agent
+	version string
+	expiry  time.Time
+}
+
+// NewAgentCache creates a new AgentCache whose entries expire after ttl, and which
+// stores discovery caches under discoveryCacheDir
+func NewAgentCache(ttl time.Duration, discoveryCacheDir string) *AgentCache {
+	return &AgentCache{
+		TTL:               ttl,
+		DiscoveryCacheDir: discoveryCacheDir,
+		agents:            make(map[agentKey]*cachedAgent),
+	}
+}
+
+// GetAgent returns the cached Agent for the cluster in the OutOfClusterConfig, or
+// creates and caches a new Agent if there is no valid cached Agent
+func (c *AgentCache) GetAgent(conf *OutOfClusterConfig) (*Agent, error) {
+	key := newAgentKey(conf)
+	version, err := CredentialVersion(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.agents[key]
+	c.mu.Unlock()
+
+	if ok && entry.version == version && time.Now().Before(entry.expiry) {
+		return entry.agent, nil
+	}
+
+	// the discovery caches are always stored in the directory of the cache,
+	// rather than in a directory chosen by the caller
+	confCopy := *conf
+	confCopy.DiscoveryCacheDir = c.DiscoveryCacheDir
+
+	if confCopy.Tunnels == nil {
+		confCopy.Tunnels = c.Tunnels
+	}
+
+	conf = &confCopy
+
+	agent, err := newCachedAgent(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// building the agent may have refreshed the token cache, so the version is
+	// computed again
+	version, err = CredentialVersion(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &cachedAgent{
+		agent:   agent,
+		version: version,
+		expiry:  time.Now().Add(c.TTL),
+	}
+
+	switch conf.Cluster.AuthMechanism {
+	case models.GCP, models.AWS:
+		tokExpiry := conf.Cluster.TokenCache.Expiry.Add(-tokenExpiryMargin)
+
+		if tokExpiry.Before(entry.expiry) {
+			entry.expiry = tokExpiry
+		}
+	}
+
+	c.mu.Lock()
+	c.agents[key] = entry
+	c.mu.Unlock()
+
+	return agent, nil
+}
+
+// Invalidate removes the cached Agents for a cluster
+func (c *AgentCache) Invalidate(clusterID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.agents {
+		if key.clusterID == clusterID {
+			delete(c.agents, key)
+		}
+	}
+}
+
+// InvalidateDiscovery removes the cached Agent and the discovery cache of a cluster,
+// which should be done when the resources served by its API server have changed,
+// for example after a cluster upgrade
+func (c *AgentCache) InvalidateDiscovery(clusterID uint) error {
+	c.Invalidate(clusterID)
+
+	return InvalidateDiscoveryCache(c.DiscoveryCacheDir, clusterID)
+}
+
+// CredentialVersion identifies the version of the credentials used to connect to the
+// cluster of conf. It changes whenever the cluster is updated, its auth mechanism or
+// integrations change, an integration is updated in place, or its token cache is
+// refreshed.
+func CredentialVersion(conf *OutOfClusterConfig) (string, error) {
+	cluster := conf.Cluster
+	integrationsUpdatedAt, err := conf.integrationsUpdatedAt()
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"%s-%d-%d-%d-%d-%d-%d-%d-%d-%d",
+		cluster.AuthMechanism,
+		cluster.UpdatedAt.UnixNano(),
+		cluster.KubeIntegrationID,
+		cluster.OIDCIntegrationID,
+		cluster.GCPIntegrationID,
+		cluster.AWSIntegrationID,
+		cluster.BastionIntegrationID,
+		integrationsUpdatedAt.UnixNano(),
+		cluster.TokenCache.UpdatedAt.UnixNano(),
+		cluster.TokenCache.Expiry.UnixNano(),
+	), nil
+}
+
+// integrationsUpdatedAt returns the latest time that the integrations used to
+// connect to the cluster were updated, which are the integration of its auth
+// mechanism and its bastion
+func (conf *OutOfClusterConfig) integrationsUpdatedAt() (time.Time, error) {
+	cluster := conf.Cluster
+	updatedAt := make([]time.Time, 0)
+
+	switch cluster.AuthMechanism {
+	case models.Local, models.X509, models.Basic, models.Bearer:
+		kubeAuth, err := conf.Repo.KubeIntegration.ReadKubeIntegration(cluster.KubeIntegrationID)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		updatedAt = append(updatedAt, kubeAuth.UpdatedAt)
+	case models.OIDC:
+		oidcAuth, err := conf.Repo.OIDCIntegration.ReadOIDCIntegration(cluster.OIDCIntegrationID)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		updatedAt = append(updatedAt, oidcAuth.UpdatedAt)
+	case models.GCP:
+		gcpAuth, err := conf.Repo.GCPIntegration.ReadGCPIntegration(cluster.GCPIntegrationID)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		updatedAt = append(updatedAt, gcpAuth.UpdatedAt)
+	case models.AWS:
+		awsAuth, err := conf.Repo.AWSIntegration.ReadAWSIntegration(cluster.AWSIntegrationID)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		updatedAt = append(updatedAt, awsAuth.UpdatedAt)
+	}
+
+	if cluster.BastionIntegrationID != 0 {
+		bastion, err := conf.Repo.BastionIntegration.ReadBastionIntegration(cluster.BastionIntegrationID)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		updatedAt = append(updatedAt, bastion.UpdatedAt)
+	}
+
+	var latest time.Time
+
+	for _, t := range updatedAt {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+
+	return latest, nil
+}
+
+// newCachedAgent creates an Agent whose RESTClientGetter builds the REST config,
+// discovery client and REST mapper once, so that the Agent can be reused
+func newCachedAgent(conf *OutOfClusterConfig) (*Agent, error) {
+	rawLoader, err := conf.GetClientConfigFromCluster()
+
+	if err != nil {
+		return nil, err
+	}
+
+	restConf, err := rawLoader.ClientConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rest.SetKubernetesDefaults(restConf)
+
+	if err := conf.configureDialer(restConf); err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := conf.discoveryClientForConfig(rest.CopyConfig(restConf))
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+
+	getter := &cachedRESTClientGetter{
+		restConf:        restConf,
+		rawLoader:       rawLoader,
+		discoveryClient: discoveryClient,
+		mapper:          restmapper.NewShortcutExpander(mapper, discoveryClient),
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dynClient, err := dynamic.NewForConfig(restConf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{getter, clientset, dynClient}, nil
+}
+
+// cachedRESTClientGetter is a RESTClientGetter that returns values computed when it
+// was created
+type cachedRESTClientGetter struct {
+	restConf        *rest.Config
+	rawLoader       clientcmd.ClientConfig
+	discoveryClient discovery.CachedDiscoveryInterface
+	mapper          meta.RESTMapper
+}
+
+// ToRESTConfig returns a copy of the REST config, since callers may modify it
+func (g *cachedRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.restConf), nil
+}
+
+func (g *cachedRESTClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return g.rawLoader
+}
+
+func (g *cachedRESTClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	return g.discoveryClient, nil
+}
+
+func (g *cachedRESTClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	return g.mapper, nil
+}
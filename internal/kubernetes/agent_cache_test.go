@@ -0,0 +1,273 @@
+package kubernetes_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository/test"
+)
+
+func newAgentCacheFixture(t *testing.T) *kubernetes.OutOfClusterConfig {
+	t.Helper()
+
+	repo := test.NewRepository(true)
+
+	ki, err := repo.KubeIntegration.CreateKubeIntegration(&ints.KubeIntegration{
+		Mechanism: ints.KubeBearer,
+		Token:     []byte("token"),
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cluster, err := repo.Cluster.CreateCluster(&models.Cluster{
+		AuthMechanism:     models.Bearer,
+		Name:              "cluster-test",
+		Server:            "https://localhost",
+		KubeIntegrationID: ki.ID,
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return &kubernetes.OutOfClusterConfig{
+		Cluster: cluster,
+		Repo:    repo,
+	}
+}
+
+func TestAgentCacheReusesAgents(t *testing.T) {
+	conf := newAgentCacheFixture(t)
+	cache := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, t.TempDir())
+
+	agent, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cached, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if agent != cached {
+		t.Errorf("expected cached agent to be reused")
+	}
+
+	// updating the cluster changes its credential version
+	conf.Cluster.UpdatedAt = time.Now().Add(time.Second)
+
+	updated, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if updated == cached {
+		t.Errorf("expected agent to be rebuilt after cluster update")
+	}
+
+	cache.Invalidate(conf.Cluster.ID)
+
+	invalidated, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if invalidated == updated {
+		t.Errorf("expected agent to be rebuilt after invalidation")
+	}
+}
+
+func TestAgentCacheRebuildsOnTokenRefresh(t *testing.T) {
+	conf := newAgentCacheFixture(t)
+	cache := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, t.TempDir())
+
+	agent, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// a token cache refreshed by another request changes the credential version
+	conf.Cluster.TokenCache = ints.TokenCache{
+		Token:  []byte("refreshed"),
+		Expiry: time.Now().Add(time.Hour),
+	}
+
+	refreshed, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if refreshed == agent {
+		t.Errorf("expected agent to be rebuilt after token cache refresh")
+	}
+}
+
+func TestAgentCacheRebuildsOnIntegrationUpdate(t *testing.T) {
+	conf := newAgentCacheFixture(t)
+	cache := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, t.TempDir())
+
+	agent, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// rotating the token of the integration in place changes the credential version
+	ki, err := conf.Repo.KubeIntegration.ReadKubeIntegration(conf.Cluster.KubeIntegrationID)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ki.Token = []byte("rotated")
+	ki.UpdatedAt = time.Now()
+
+	rotated, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if rotated == agent {
+		t.Fatalf("expected agent to be rebuilt after integration update")
+	}
+
+	restConf, err := rotated.RESTClientGetter.ToRESTConfig()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if restConf.BearerToken != "rotated" {
+		t.Errorf("wrong bearer token: expected rotated, got %s", restConf.BearerToken)
+	}
+}
+
+func TestAgentCacheInvalidateDiscovery(t *testing.T) {
+	conf := newAgentCacheFixture(t)
+	cacheDir := t.TempDir()
+	cache := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, cacheDir)
+
+	agent, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// simulate discovery information written by the agent
+	clusterDir := kubernetes.DiscoveryCacheDir(cacheDir, conf.Cluster.ID)
+
+	if err := os.MkdirAll(filepath.Join(clusterDir, "discovery"), 0755); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err := cache.InvalidateDiscovery(conf.Cluster.ID); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := os.Stat(clusterDir); !os.IsNotExist(err) {
+		t.Errorf("expected discovery cache %s to be removed", clusterDir)
+	}
+
+	rebuilt, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if rebuilt == agent {
+		t.Errorf("expected agent to be rebuilt after discovery invalidation")
+	}
+}
+
+func TestAgentCacheImpersonation(t *testing.T) {
+	conf := newAgentCacheFixture(t)
+	cache := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, t.TempDir())
+
+	agent, err := cache.GetAgent(conf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	viewerConf := *conf
+	viewerConf.Impersonate = &models.RoleImpersonation{
+		RoleKind: models.RoleViewer,
+		User:     "porter-viewer",
+		Groups:   "viewers, auditors",
+	}
+
+	viewer, err := cache.GetAgent(&viewerConf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if viewer == agent {
+		t.Fatalf("expected a separate agent for the impersonated identity")
+	}
+
+	restConf, err := viewer.RESTClientGetter.ToRESTConfig()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if restConf.Impersonate.UserName != "porter-viewer" {
+		t.Errorf("wrong impersonated user: expected porter-viewer, got %s", restConf.Impersonate.UserName)
+	}
+
+	if groups := restConf.Impersonate.Groups; len(groups) != 2 || groups[0] != "viewers" || groups[1] != "auditors" {
+		t.Errorf("wrong impersonated groups: expected [viewers auditors], got %v", groups)
+	}
+
+	cached, err := cache.GetAgent(&viewerConf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if cached != viewer {
+		t.Errorf("expected cached agent of the impersonated identity to be reused")
+	}
+
+	// invalidating the cluster drops the agents of every identity
+	cache.Invalidate(conf.Cluster.ID)
+
+	invalidated, err := cache.GetAgent(&viewerConf)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if invalidated == viewer {
+		t.Errorf("expected agent to be rebuilt after invalidation")
+	}
+}
+
+func TestOutOfClusterConfigDiscoveryCacheDirNotDecoded(t *testing.T) {
+	conf := &kubernetes.OutOfClusterConfig{}
+
+	if err := json.Unmarshal([]byte(`{"discoverycachedir":"/tmp/other","DiscoveryCacheDir":"/tmp/other"}`), conf); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if conf.DiscoveryCacheDir != "" {
+		t.Errorf("expected discovery cache dir not to be decoded, got %s", conf.DiscoveryCacheDir)
+	}
+}
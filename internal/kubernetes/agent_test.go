@@ -1,6 +1,7 @@
 package kubernetes_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/porter-dev/porter/internal/kubernetes"
@@ -10,6 +11,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -63,3 +65,100 @@ func TestOutOfClusterConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestRestartController(t *testing.T) {
+	k8sAgent := newAgentFixture(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+		},
+	})
+
+	err := k8sAgent.RestartController("Deployment", "default", "test-deployment")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	depl, err := k8sAgent.Clientset.AppsV1().Deployments("default").Get(
+		context.TODO(),
+		"test-deployment",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, ok := depl.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"]; !ok {
+		t.Errorf("restartedAt annotation was not set on pod template")
+	}
+
+	err = k8sAgent.RestartController("ReplicaSet", "default", "test-deployment")
+
+	if err == nil {
+		t.Errorf("expected error restarting unsupported kind, got nil")
+	}
+}
+
+func TestScaleController(t *testing.T) {
+	replicas := int32(1)
+
+	k8sAgent := newAgentFixture(t, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "default",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	})
+
+	err := k8sAgent.ScaleController("statefulset", "default", "test-statefulset", 3)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ss, err := k8sAgent.Clientset.AppsV1().StatefulSets("default").Get(
+		context.TODO(),
+		"test-statefulset",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if ss.Spec.Replicas == nil || *ss.Spec.Replicas != 3 {
+		t.Errorf("replicas not updated: expected %d, got %v", 3, ss.Spec.Replicas)
+	}
+}
+
+func TestDeletePod(t *testing.T) {
+	k8sAgent := newAgentFixture(t, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	})
+
+	err := k8sAgent.DeletePod("default", "test-pod")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	pods, err := k8sAgent.Clientset.CoreV1().Pods("default").List(
+		context.TODO(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(pods.Items) != 0 {
+		t.Errorf("expected pod to be deleted, found %d pods", len(pods.Items))
+	}
+}
@@ -0,0 +1,220 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterCheck is the result of a single connectivity or permission check
+type ClusterCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+
+	// Message explains how to fix a failed check
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterCheckResult is the result of checking that Porter can connect to a cluster
+// and has the permissions that it needs
+type ClusterCheckResult struct {
+	ServerVersion string         `json:"server_version,omitempty"`
+	Passed        bool           `json:"passed"`
+	Checks        []ClusterCheck `json:"checks"`
+}
+
+// RequiredPermission is an action that Porter must be allowed to perform in every
+// namespace of a cluster
+type RequiredPermission struct {
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+
+	// Reason is the feature that requires the permission
+	Reason string
+}
+
+// RequiredPermissions are the permissions that are checked by CheckCluster
+var RequiredPermissions = []RequiredPermission{
+	{Resource: "secrets", Verb: "list", Reason: "Helm stores releases in secrets"},
+	{Resource: "secrets", Verb: "create", Reason: "Helm stores releases in secrets"},
+	{Resource: "secrets", Verb: "update", Reason: "Helm stores releases in secrets"},
+	{Resource: "secrets", Verb: "delete", Reason: "Helm stores releases in secrets"},
+	{Resource: "namespaces", Verb: "list", Reason: "releases are listed by namespace"},
+	{Group: "apps", Resource: "deployments", Verb: "list", Reason: "release controllers are displayed"},
+	{Group: "apps", Resource: "deployments", Verb: "watch", Reason: "controller status is streamed"},
+	{Group: "apps", Resource: "deployments", Verb: "patch", Reason: "controllers are restarted and scaled"},
+	{Resource: "pods", Verb: "list", Reason: "release pods are displayed"},
+	{Resource: "pods", Subresource: "log", Verb: "get", Reason: "pod logs are streamed"},
+}
+
+// CheckClusterConfig creates an Agent from the OutOfClusterConfig without caching it,
+// so that the stored credentials are used, and checks the cluster with the Agent. A
+// failure to create the Agent is reported as a failed check.
+func CheckClusterConfig(conf *OutOfClusterConfig) *ClusterCheckResult {
+	agent, err := GetAgentOutOfClusterConfig(conf)
+
+	if err != nil {
+		return &ClusterCheckResult{
+			Checks: []ClusterCheck{
+				{
+					Name: "credentials",
+					Message: fmt.Sprintf(
+						"Could not load the credentials of the cluster: %v. Re-link the cluster "+
+							"or update its %s integration.",
+						err,
+						conf.Cluster.AuthMechanism,
+					),
+				},
+			},
+		}
+	}
+
+	return agent.CheckCluster()
+}
+
+// CheckCluster tries to authenticate with the API server, and then uses
+// SelfSubjectAccessReviews to check that the RequiredPermissions are granted. The
+// permission checks are skipped if the API server cannot be reached.
+func (a *Agent) CheckCluster() *ClusterCheckResult {
+	res := &ClusterCheckResult{
+		Checks: make([]ClusterCheck, 0),
+	}
+
+	version, err := a.Clientset.Discovery().ServerVersion()
+
+	if err != nil {
+		res.Checks = append(res.Checks, ClusterCheck{
+			Name:    "connection",
+			Message: connectionErrorMessage(err),
+		})
+
+		return res
+	}
+
+	res.ServerVersion = version.GitVersion
+	res.Checks = append(res.Checks, ClusterCheck{
+		Name:   "connection",
+		Passed: true,
+	})
+
+	res.Passed = true
+
+	for _, perm := range RequiredPermissions {
+		check := a.checkPermission(perm)
+
+		if !check.Passed {
+			res.Passed = false
+		}
+
+		res.Checks = append(res.Checks, check)
+	}
+
+	return res
+}
+
+func (a *Agent) checkPermission(perm RequiredPermission) ClusterCheck {
+	resource := perm.Resource
+
+	if perm.Subresource != "" {
+		resource += "/" + perm.Subresource
+	}
+
+	if perm.Group != "" {
+		resource += "." + perm.Group
+	}
+
+	check := ClusterCheck{
+		Name: fmt.Sprintf("%s %s", perm.Verb, resource),
+	}
+
+	review, err := a.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(
+		context.TODO(),
+		&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       perm.Group,
+					Resource:    perm.Resource,
+					Subresource: perm.Subresource,
+					Verb:        perm.Verb,
+				},
+			},
+		},
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		check.Message = fmt.Sprintf("Could not check permission: %v", err)
+		return check
+	}
+
+	if !review.Status.Allowed {
+		check.Message = fmt.Sprintf(
+			"Porter cannot %s %s in all namespaces, which is required because %s. "+
+				"Grant this permission to the cluster's user with a ClusterRole and ClusterRoleBinding.",
+			perm.Verb,
+			resource,
+			perm.Reason,
+		)
+
+		if review.Status.Reason != "" {
+			check.Message += fmt.Sprintf(" Reason: %s", review.Status.Reason)
+		}
+
+		return check
+	}
+
+	check.Passed = true
+
+	return check
+}
+
+// connectionErrorMessage suggests a fix for an error returned by the API server
+func connectionErrorMessage(err error) string {
+	switch {
+	case k8serrors.IsUnauthorized(err):
+		return fmt.Sprintf(
+			"The API server rejected the cluster's credentials: %v. The token or certificate may "+
+				"have expired or been revoked, so re-link the cluster with valid credentials.",
+			err,
+		)
+	case k8serrors.IsForbidden(err):
+		return fmt.Sprintf(
+			"The cluster's user is not allowed to read the server version: %v. Check that the "+
+				"user is bound to a role with discovery permissions.",
+			err,
+		)
+	}
+
+	if errors.Is(err, tunnel.ErrAgentNotConnected) {
+		return "The cluster's agent is not connected to Porter. Check that the agent is " +
+			"running in the cluster, and that it can reach the Porter server."
+	}
+
+	if urlErr, ok := err.(*url.Error); ok {
+		if strings.Contains(urlErr.Error(), "x509") {
+			return fmt.Sprintf(
+				"The API server's certificate could not be verified: %v. Check that the "+
+					"cluster's certificate authority data is correct.",
+				err,
+			)
+		}
+
+		return fmt.Sprintf(
+			"Could not reach the API server: %v. Check that the server address is correct, "+
+				"and that the API server is reachable from Porter (private endpoints require "+
+				"a proxy or bastion).",
+			err,
+		)
+	}
+
+	return fmt.Sprintf("Could not connect to the API server: %v", err)
+}
@@ -0,0 +1,63 @@
+package kubernetes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckCluster(t *testing.T) {
+	agent := newAgentFixture(t)
+	clientset := agent.Clientset.(*fake.Clientset)
+
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		GitVersion: "v1.18.8",
+	}
+
+	// allow everything except streaming pod logs
+	clientset.PrependReactor(
+		"create",
+		"selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attrs := review.Spec.ResourceAttributes
+
+			review.Status.Allowed = attrs.Resource != "pods" || attrs.Subresource != "log"
+
+			return true, review, nil
+		},
+	)
+
+	res := agent.CheckCluster()
+
+	if res.ServerVersion != "v1.18.8" {
+		t.Errorf("wrong server version: expected v1.18.8, got %s", res.ServerVersion)
+	}
+
+	if res.Passed {
+		t.Errorf("expected check to fail")
+	}
+
+	if len(res.Checks) != len(kubernetes.RequiredPermissions)+1 {
+		t.Fatalf("wrong number of checks: expected %d, got %d", len(kubernetes.RequiredPermissions)+1, len(res.Checks))
+	}
+
+	for _, check := range res.Checks {
+		expPassed := check.Name != "get pods/log"
+
+		if check.Passed != expPassed {
+			t.Errorf("check %s: expected passed to be %t, got %t", check.Name, expPassed, check.Passed)
+		}
+
+		if !check.Passed && !strings.Contains(check.Message, "ClusterRole") {
+			t.Errorf("check %s: expected actionable message, got %q", check.Name, check.Message)
+		}
+	}
+}
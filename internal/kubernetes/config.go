@@ -2,23 +2,31 @@ package kubernetes
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
 	diskcached "k8s.io/client-go/discovery/cached/disk"
 	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/util/homedir"
@@ -60,7 +68,13 @@ func GetAgentOutOfClusterConfig(conf *OutOfClusterConfig) (*Agent, error) {
 		return nil, err
 	}
 
-	return &Agent{conf, clientset}, nil
+	dynClient, err := dynamic.NewForConfig(restConf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{conf, clientset, dynClient}, nil
 }
 
 // GetAgentInClusterConfig uses the service account that kubernetes
@@ -75,12 +89,65 @@ func GetAgentInClusterConfig() (*Agent, error) {
 	restClientGetter := newRESTClientGetterFromInClusterConfig(conf)
 	clientset, err := kubernetes.NewForConfig(conf)
 
-	return &Agent{restClientGetter, clientset}, nil
+	if err != nil {
+		return nil, err
+	}
+
+	dynClient, err := dynamic.NewForConfig(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{restClientGetter, clientset, dynClient}, nil
 }
 
 // GetAgentTesting creates a new Agent using an optional existing storage class
 func GetAgentTesting(objects ...runtime.Object) *Agent {
-	return &Agent{&fakeRESTClientGetter{}, fake.NewSimpleClientset(objects...)}
+	return &Agent{
+		&fakeRESTClientGetter{},
+		fake.NewSimpleClientset(objects...),
+		NewDynamicClientTesting(),
+	}
+}
+
+// NewDynamicClientTesting creates a fake dynamic client that also handles
+// server-side apply, which the fake client does not support, by creating the
+// applied object or replacing the existing object with it
+func NewDynamicClientTesting(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+
+	// the reactor that reads and writes the objects of the fake client
+	objectReactor := client.ReactionChain[0]
+
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(k8stesting.PatchAction)
+
+		if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		obj := &unstructured.Unstructured{}
+
+		if err := obj.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+
+		gvr := patchAction.GetResource()
+		namespace := patchAction.GetNamespace()
+
+		_, _, err := objectReactor.React(k8stesting.NewGetAction(gvr, namespace, patchAction.GetName()))
+
+		if apierrors.IsNotFound(err) {
+			return objectReactor.React(k8stesting.NewCreateAction(gvr, namespace, obj))
+		} else if err != nil {
+			return true, nil, err
+		}
+
+		return objectReactor.React(k8stesting.NewUpdateAction(gvr, namespace, obj))
+	})
+
+	return client
 }
 
 // OutOfClusterConfig is the set of parameters required for an out-of-cluster connection.
@@ -88,6 +155,18 @@ func GetAgentTesting(objects ...runtime.Object) *Agent {
 type OutOfClusterConfig struct {
 	Cluster *models.Cluster
 	Repo    *repository.Repository
+
+	// DiscoveryCacheDir is the directory under which the discovery caches of
+	// each cluster are stored, see DiscoveryCacheDir. It is never read from a
+	// request, and is replaced by the directory of the AgentCache.
+	DiscoveryCacheDir string `json:"-"`
+
+	// Tunnels opens connections to clusters that use the agent auth mechanism
+	Tunnels TunnelDialer
+
+	// Impersonate is the Kubernetes identity that the requesting user's role is
+	// mapped to. If set, it replaces the impersonation configured on the cluster.
+	Impersonate *models.RoleImpersonation
 }
 
 // ToRESTConfig creates a kubernetes REST client factory -- it calls ClientConfig on
@@ -101,6 +180,11 @@ func (conf *OutOfClusterConfig) ToRESTConfig() (*rest.Config, error) {
 	}
 
 	rest.SetKubernetesDefaults(restConf)
+
+	if err := conf.configureDialer(restConf); err != nil {
+		return nil, err
+	}
+
 	return restConf, nil
 }
 
@@ -122,18 +206,39 @@ func (conf *OutOfClusterConfig) ToDiscoveryClient() (discovery.CachedDiscoveryIn
 		return nil, err
 	}
 
+	return conf.discoveryClientForConfig(restConf)
+}
+
+// discoveryClientForConfig creates a CachedDiscoveryInterface that caches discovery
+// information on disk, in a directory that belongs to the cluster
+func (conf *OutOfClusterConfig) discoveryClientForConfig(restConf *rest.Config) (discovery.CachedDiscoveryInterface, error) {
 	restConf.Burst = 100
-	defaultHTTPCacheDir := filepath.Join(homedir.HomeDir(), ".kube", "http-cache")
+	cacheDir := DiscoveryCacheDir(conf.DiscoveryCacheDir, conf.Cluster.ID)
 
-	// takes the parentDir and the host and comes up with a "usually non-colliding" name for the discoveryCacheDir
-	parentDir := filepath.Join(homedir.HomeDir(), ".kube", "cache", "discovery")
-	// strip the optional scheme from host if its there:
-	schemelessHost := strings.Replace(strings.Replace(restConf.Host, "https://", "", 1), "http://", "", 1)
-	// now do a simple collapse of non-AZ09 characters.  Collisions are possible but unlikely.  Even if we do collide the problem is short lived
-	safeHost := regexp.MustCompile(`[^(\w/\.)]`).ReplaceAllString(schemelessHost, "_")
-	discoveryCacheDir := filepath.Join(parentDir, safeHost)
+	return diskcached.NewCachedDiscoveryClientForConfig(
+		restConf,
+		filepath.Join(cacheDir, "discovery"),
+		filepath.Join(cacheDir, "http"),
+		time.Duration(10*time.Minute),
+	)
+}
 
-	return diskcached.NewCachedDiscoveryClientForConfig(restConf, discoveryCacheDir, defaultHTTPCacheDir, time.Duration(10*time.Minute))
+// DiscoveryCacheDir returns the directory in which the discovery and HTTP caches of a
+// cluster are stored. The caches are keyed by cluster ID rather than by host, since
+// multiple clusters may share an API server endpoint. If parentDir is empty, the
+// caches are stored under ~/.kube/cache/porter.
+func DiscoveryCacheDir(parentDir string, clusterID uint) string {
+	if parentDir == "" {
+		parentDir = filepath.Join(homedir.HomeDir(), ".kube", "cache", "porter")
+	}
+
+	return filepath.Join(parentDir, fmt.Sprintf("%d", clusterID))
+}
+
+// InvalidateDiscoveryCache removes the discovery and HTTP caches of a cluster, so
+// that the resources served by the API server are discovered again
+func InvalidateDiscoveryCache(parentDir string, clusterID uint) error {
+	return os.RemoveAll(DiscoveryCacheDir(parentDir, clusterID))
 }
 
 // ToRESTMapper returns a mapper
@@ -206,6 +311,11 @@ func (conf *OutOfClusterConfig) createRawConfigFromCluster() (*api.Config, error
 		authInfoMap[authInfoName].ImpersonateGroups = groups
 	}
 
+	if conf.Impersonate != nil {
+		authInfoMap[authInfoName].Impersonate = conf.Impersonate.User
+		authInfoMap[authInfoName].ImpersonateGroups = conf.Impersonate.GroupList()
+	}
+
 	switch cluster.AuthMechanism {
 	case models.X509:
 		kubeAuth, err := conf.Repo.KubeIntegration.ReadKubeIntegration(
@@ -276,6 +386,12 @@ func (conf *OutOfClusterConfig) createRawConfigFromCluster() (*api.Config, error
 
 		// add this as a bearer token
 		authInfoMap[authInfoName].Token = tok
+	case models.Agent:
+		// the agent authenticates requests with its own service account
+		clusterMap[cluster.Name] = &api.Cluster{
+			Server:           AgentTunnelServer,
+			LocationOfOrigin: cluster.ClusterLocationOfOrigin,
+		}
 	case models.AWS:
 		awsAuth, err := conf.Repo.AWSIntegration.ReadAWSIntegration(
 			cluster.AWSIntegrationID,
@@ -327,7 +443,16 @@ func (conf *OutOfClusterConfig) setTokenCache(token string, expiry time.Time) er
 		},
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	// keep the in-memory cluster in sync, so that its credential version reflects
+	// the new token
+	conf.Cluster.TokenCache.Token = []byte(token)
+	conf.Cluster.TokenCache.Expiry = expiry
+
+	return nil
 }
 
 // newRESTClientGetterFromInClusterConfig returns a RESTClientGetter using
@@ -370,5 +495,5 @@ func (f *fakeRESTClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInt
 }
 
 func (f *fakeRESTClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
-	return nil, nil
+	return testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme), nil
 }
@@ -0,0 +1,392 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+	"k8s.io/client-go/rest"
+)
+
+// DialFunc opens a connection to an address
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// TunnelDialer opens connections to the API servers of clusters through the
+// tunnels of their in-cluster agents
+type TunnelDialer interface {
+	DialCluster(ctx context.Context, clusterID uint) (net.Conn, error)
+}
+
+// AgentTunnelServer is the server address of clusters that use the agent auth
+// mechanism: requests are sent over the agent's tunnel as plain HTTP, and the
+// agent forwards them to the API server over TLS
+const AgentTunnelServer = "http://porter-agent"
+
+// bastionIdleTimeout is how long an SSH connection to a bastion is kept open
+// after the last connection tunneled through it is closed
+const bastionIdleTimeout = 5 * time.Minute
+
+var defaultDialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// configureDialer routes the connections of the REST config through the cluster's
+// proxy and SSH bastion, if they are set. When both are set, the proxy is used to
+// reach the bastion.
+func (conf *OutOfClusterConfig) configureDialer(restConf *rest.Config) error {
+	cluster := conf.Cluster
+
+	if cluster.AuthMechanism == models.Agent {
+		if conf.Tunnels == nil {
+			return fmt.Errorf("cluster %d is connected through an agent, but agent tunnels are not configured", cluster.ID)
+		}
+
+		setDialer(restConf, func(ctx context.Context, network, address string) (net.Conn, error) {
+			return conf.Tunnels.DialCluster(ctx, cluster.ID)
+		})
+
+		return nil
+	}
+
+	if cluster.ProxyURL == "" && cluster.BastionIntegrationID == 0 {
+		return nil
+	}
+
+	dial := DialFunc(defaultDialer.DialContext)
+
+	if cluster.ProxyURL != "" {
+		proxyDial, err := NewProxyDialer(cluster.ProxyURL, dial)
+
+		if err != nil {
+			return err
+		}
+
+		dial = proxyDial
+	}
+
+	if cluster.BastionIntegrationID != 0 {
+		bastion, err := conf.Repo.BastionIntegration.ReadBastionIntegration(
+			cluster.BastionIntegrationID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		bastionDial, err := NewBastionDialer(bastion, dial)
+
+		if err != nil {
+			return err
+		}
+
+		dial = bastionDial
+	}
+
+	setDialer(restConf, dial)
+
+	return nil
+}
+
+// setDialer sets the function used to open the connections of the REST config
+func setDialer(restConf *rest.Config, dial DialFunc) {
+	// client-go shares transports between configs with the same TLS options, and
+	// does not distinguish between dial functions, so the dialer is set on a copy of
+	// the transport rather than through restConf.Dial
+	restConf.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		transport, ok := rt.(*http.Transport)
+
+		if !ok {
+			return errorRoundTripper{fmt.Errorf("cannot set dialer on transport of type %T", rt)}
+		}
+
+		transport = transport.Clone()
+		transport.Proxy = nil
+		transport.DialContext = dial
+
+		return transport
+	}
+}
+
+type errorRoundTripper struct {
+	err error
+}
+
+func (rt errorRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, rt.err
+}
+
+// NewProxyDialer returns a DialFunc that connects through the proxy at proxyURL,
+// which uses forward to connect to the proxy. HTTP and HTTPS proxies are sent a
+// CONNECT request, and SOCKS5 proxies are supported through the socks5 and socks5h
+// schemes. Credentials may be passed as the user info of the URL.
+func NewProxyDialer(proxyURL string, forward DialFunc) (DialFunc, error) {
+	u, err := url.Parse(proxyURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %v", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return httpConnectDialer(u, forward), nil
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+
+		if u.User != nil {
+			password, _ := u.User.Password()
+
+			auth = &proxy.Auth{
+				User:     u.User.Username(),
+				Password: password,
+			}
+		}
+
+		dialer, err := proxy.SOCKS5("tcp", u.Host, auth, forwardDialer(forward))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return dialer.(proxy.ContextDialer).DialContext, nil
+	}
+
+	return nil, fmt.Errorf("unsupported proxy scheme %q: must be one of http, https, socks5", u.Scheme)
+}
+
+// forwardDialer adapts a DialFunc to a proxy.Dialer
+type forwardDialer DialFunc
+
+func (d forwardDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d forwardDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+// httpConnectDialer opens a tunnel through an HTTP proxy with a CONNECT request
+func httpConnectDialer(u *url.URL, forward DialFunc) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := forward(ctx, "tcp", u.Host)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if u.Scheme == "https" {
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+
+			if err := tlsConn.Handshake(); err != nil {
+				conn.Close()
+				return nil, err
+			}
+
+			conn = tlsConn
+		}
+
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: address},
+			Host:   address,
+			Header: make(http.Header),
+		}
+
+		if u.User != nil {
+			password, _ := u.User.Password()
+			creds := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+			req.Header.Set("Proxy-Authorization", "Basic "+creds)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+			defer conn.SetDeadline(time.Time{})
+		}
+
+		if err := req.Write(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		// the server does not send data on the tunnel before the client does, so
+		// the buffered reader cannot consume any data past the response
+		resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("proxy refused connection to %s: %s", address, resp.Status)
+		}
+
+		return conn, nil
+	}
+}
+
+// NewBastionDialer returns a DialFunc that tunnels connections through an SSH
+// bastion, which uses forward to connect to the bastion. A single SSH connection
+// is shared by all tunneled connections, and is closed once it is idle.
+func NewBastionDialer(bastion *ints.BastionIntegration, forward DialFunc) (DialFunc, error) {
+	sshConf, err := bastion.SSHClientConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := &bastionDialer{
+		addr:    bastion.Address(),
+		sshConf: sshConf,
+		forward: forward,
+	}
+
+	return d.DialContext, nil
+}
+
+type bastionDialer struct {
+	addr    string
+	sshConf *ssh.ClientConfig
+	forward DialFunc
+
+	mu        sync.Mutex
+	client    *ssh.Client
+	open      int
+	idleTimer *time.Timer
+}
+
+func (d *bastionDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	client, err := d.getClient(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial(network, address)
+
+	if _, ok := err.(*ssh.OpenChannelError); ok {
+		// the bastion rejected the tunnel, for example because the address is
+		// unreachable, which says nothing about the ssh connection
+		return nil, err
+	} else if err != nil {
+		// the ssh connection may have been closed by the bastion, in which case
+		// it is reconnected once. A live connection is kept, since it is shared
+		// by the other tunneled connections.
+		if _, _, kaErr := client.SendRequest("keepalive@openssh.com", true, nil); kaErr == nil {
+			return nil, err
+		}
+
+		d.closeClient(client)
+
+		if client, err = d.getClient(ctx); err != nil {
+			return nil, err
+		}
+
+		if conn, err = client.Dial(network, address); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.open++
+
+	if d.idleTimer != nil {
+		d.idleTimer.Stop()
+		d.idleTimer = nil
+	}
+
+	return &bastionConn{Conn: conn, dialer: d}, nil
+}
+
+func (d *bastionDialer) getClient(ctx context.Context) (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	conn, err := d.forward(ctx, "tcp", d.addr)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to bastion %s: %v", d.addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.addr, d.sshConf)
+
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not log in to bastion %s: %v", d.addr, err)
+	}
+
+	d.client = ssh.NewClient(sshConn, chans, reqs)
+
+	return d.client, nil
+}
+
+func (d *bastionDialer) closeClient(client *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == client {
+		d.client = nil
+	}
+
+	client.Close()
+}
+
+// release is called when a tunneled connection is closed, and closes the ssh
+// connection once it has been idle for bastionIdleTimeout
+func (d *bastionDialer) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.open--
+
+	if d.open > 0 || d.client == nil {
+		return
+	}
+
+	client := d.client
+
+	d.idleTimer = time.AfterFunc(bastionIdleTimeout, func() {
+		d.mu.Lock()
+		idle := d.open == 0 && d.client == client
+		d.mu.Unlock()
+
+		if idle {
+			d.closeClient(client)
+		}
+	})
+}
+
+// bastionConn is a connection tunneled through a bastion
+type bastionConn struct {
+	net.Conn
+
+	dialer *bastionDialer
+	once   sync.Once
+}
+
+func (c *bastionConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.dialer.release)
+
+	return err
+}
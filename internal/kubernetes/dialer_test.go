@@ -0,0 +1,314 @@
+package kubernetes_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"golang.org/x/crypto/ssh"
+)
+
+var direct = kubernetes.DialFunc((&net.Dialer{}).DialContext)
+
+// httpGetThrough sends a GET request to the target server over a connection opened
+// with dial, and returns the response body
+func httpGetThrough(t *testing.T, dial kubernetes.DialFunc, target string) string {
+	t.Helper()
+
+	client := &http.Client{
+		Transport: &http.Transport{DialContext: dial},
+	}
+
+	resp, err := client.Get(target)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return string(body)
+}
+
+func newTargetServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+}
+
+// newConnectProxy starts an HTTP proxy that only supports CONNECT, and records the
+// requested addresses
+func newConnectProxy(t *testing.T, requested *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		*requested = append(*requested, r.Host)
+
+		target, err := net.Dial("tcp", r.Host)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+
+		if err != nil {
+			t.Errorf("could not hijack connection: %v", err)
+			return
+		}
+
+		fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+		go pipe(conn, target)
+	}))
+}
+
+func pipe(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+
+	io.Copy(b, a)
+	b.Close()
+}
+
+func TestProxyDialerHTTPConnect(t *testing.T) {
+	target := newTargetServer()
+	defer target.Close()
+
+	requested := make([]string, 0)
+	proxy := newConnectProxy(t, &requested)
+	defer proxy.Close()
+
+	dial, err := kubernetes.NewProxyDialer(proxy.URL, direct)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if body := httpGetThrough(t, dial, target.URL); body != "ok" {
+		t.Errorf("wrong body: expected ok, got %s", body)
+	}
+
+	targetHost := strings.TrimPrefix(target.URL, "http://")
+
+	if len(requested) != 1 || requested[0] != targetHost {
+		t.Errorf("wrong CONNECT requests: expected [%s], got %v", targetHost, requested)
+	}
+}
+
+func TestProxyDialerUnsupportedScheme(t *testing.T) {
+	_, err := kubernetes.NewProxyDialer("ftp://proxy.example.com", direct)
+
+	if err == nil {
+		t.Errorf("expected error for unsupported proxy scheme, got nil")
+	}
+}
+
+func newRSAKeyPEM(t *testing.T) ([]byte, ssh.Signer) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), signer
+}
+
+// newSSHBastion starts an SSH server that accepts the client key and forwards
+// direct-tcpip channels, and returns its address
+func newSSHBastion(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) string {
+	t.Helper()
+
+	conf := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == "porter" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("unknown key for %s", meta.User())
+		},
+	}
+
+	conf.AddHostKey(hostKey)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, conf)
+
+				if err != nil {
+					return
+				}
+
+				go ssh.DiscardRequests(reqs)
+
+				for newChan := range chans {
+					var payload struct {
+						Host       string
+						Port       uint32
+						OriginHost string
+						OriginPort uint32
+					}
+
+					if newChan.ChannelType() != "direct-tcpip" ||
+						ssh.Unmarshal(newChan.ExtraData(), &payload) != nil {
+						newChan.Reject(ssh.UnknownChannelType, "unsupported channel")
+						continue
+					}
+
+					target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+
+					if err != nil {
+						newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+
+					ch, chReqs, err := newChan.Accept()
+
+					if err != nil {
+						target.Close()
+						continue
+					}
+
+					go ssh.DiscardRequests(chReqs)
+
+					go func() {
+						go func() {
+							io.Copy(ch, target)
+							ch.Close()
+						}()
+
+						io.Copy(target, ch)
+						target.Close()
+					}()
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestBastionDialer(t *testing.T) {
+	target := newTargetServer()
+	defer target.Close()
+
+	_, hostKey := newRSAKeyPEM(t)
+	clientPEM, clientKey := newRSAKeyPEM(t)
+
+	addr := newSSHBastion(t, hostKey, clientKey.PublicKey())
+	host, port, _ := net.SplitHostPort(addr)
+
+	portNum, _ := strconv.Atoi(port)
+
+	bastion := &ints.BastionIntegration{
+		Host:       host,
+		Port:       portNum,
+		User:       "porter",
+		HostKey:    string(ssh.MarshalAuthorizedKey(hostKey.PublicKey())),
+		PrivateKey: clientPEM,
+	}
+
+	dial, err := kubernetes.NewBastionDialer(bastion, direct)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if body := httpGetThrough(t, dial, target.URL); body != "ok" {
+		t.Errorf("wrong body: expected ok, got %s", body)
+	}
+
+	// a tunnel rejected by the bastion does not close the other tunnels
+	conn, err := dial(context.Background(), "tcp", strings.TrimPrefix(target.URL, "http://"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer conn.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	closed.Close()
+
+	if _, err := dial(context.Background(), "tcp", closed.Addr().String()); err == nil {
+		t.Errorf("expected error dialing unreachable address, got nil")
+	}
+
+	if _, err := conn.Write([]byte("GET / HTTP/1.0\r\n\r\n")); err != nil {
+		t.Fatalf("tunnel closed after rejected tunnel: %v", err)
+	}
+
+	if resp, err := ioutil.ReadAll(conn); err != nil || !strings.HasSuffix(string(resp), "ok") {
+		t.Errorf("wrong response after rejected tunnel: %q, %v", resp, err)
+	}
+
+	// a bastion presenting a different host key is rejected
+	_, otherHostKey := newRSAKeyPEM(t)
+	bastion.HostKey = string(ssh.MarshalAuthorizedKey(otherHostKey.PublicKey()))
+
+	dial, err = kubernetes.NewBastionDialer(bastion, direct)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := dial(context.Background(), "tcp", strings.TrimPrefix(target.URL, "http://")); err == nil {
+		t.Errorf("expected host key mismatch error, got nil")
+	}
+}
@@ -0,0 +1,253 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/networking/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Endpoint is an address at which a release can be reached from outside the cluster
+type Endpoint struct {
+	// Kind is the type of endpoint: one of Ingress, LoadBalancer or NodePort
+	Kind string `json:"kind"`
+
+	// Name is the name of the Ingress or Service that exposes the endpoint
+	Name string `json:"name"`
+
+	URL  string `json:"url,omitempty"`
+	Host string `json:"host,omitempty"`
+	Path string `json:"path,omitempty"`
+	Port int32  `json:"port,omitempty"`
+
+	// TLS is true if the Ingress spec configures TLS for the host
+	TLS       bool   `json:"tls"`
+	TLSSecret string `json:"tls_secret,omitempty"`
+
+	// Pending is true if the load balancer has not been assigned an address yet
+	Pending bool `json:"pending"`
+}
+
+// GetEndpoints resolves the addresses at which the passed objects can be reached, using
+// the hosts and paths of Ingresses and the external addresses of LoadBalancer and
+// NodePort Services. The live objects are read from the cluster, since load balancer
+// addresses and node ports are assigned after deployment.
+func (a *Agent) GetEndpoints(objs []grapher.Object, namespace string) ([]Endpoint, error) {
+	res := make([]Endpoint, 0)
+
+	var nodeAddrs []string
+
+	for _, obj := range objs {
+		switch obj.Kind {
+		case "Ingress":
+			ingress, err := a.Clientset.NetworkingV1beta1().Ingresses(namespace).Get(
+				context.TODO(),
+				obj.Name,
+				metav1.GetOptions{},
+			)
+
+			if k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			res = append(res, getIngressEndpoints(ingress)...)
+		case "Service":
+			svc, err := a.Clientset.CoreV1().Services(namespace).Get(
+				context.TODO(),
+				obj.Name,
+				metav1.GetOptions{},
+			)
+
+			if k8serrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			if svc.Spec.Type == v1.ServiceTypeNodePort && nodeAddrs == nil {
+				nodeAddrs, err = a.getNodeAddresses()
+
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			res = append(res, getServiceEndpoints(svc, nodeAddrs)...)
+		}
+	}
+
+	return res, nil
+}
+
+func getIngressEndpoints(ingress *v1beta1.Ingress) []Endpoint {
+	res := make([]Endpoint, 0)
+
+	tlsSecrets := make(map[string]string)
+
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsSecrets[host] = tls.SecretName
+		}
+	}
+
+	lbAddrs := getLoadBalancerAddresses(ingress.Status.LoadBalancer)
+
+	for _, rule := range ingress.Spec.Rules {
+		hosts := []string{rule.Host}
+
+		// rules without a host are served on the ingress controller's address
+		if rule.Host == "" {
+			hosts = lbAddrs
+		}
+
+		paths := []string{"/"}
+
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			paths = make([]string, 0)
+
+			for _, path := range rule.HTTP.Paths {
+				p := path.Path
+
+				if p == "" {
+					p = "/"
+				}
+
+				paths = append(paths, p)
+			}
+		}
+
+		if len(hosts) == 0 {
+			res = append(res, Endpoint{
+				Kind:    "Ingress",
+				Name:    ingress.Name,
+				Pending: true,
+			})
+
+			continue
+		}
+
+		for _, host := range hosts {
+			secret, tls := tlsSecrets[host]
+			scheme := "http"
+
+			if tls {
+				scheme = "https"
+			}
+
+			for _, path := range paths {
+				res = append(res, Endpoint{
+					Kind:      "Ingress",
+					Name:      ingress.Name,
+					URL:       fmt.Sprintf("%s://%s%s", scheme, host, path),
+					Host:      host,
+					Path:      path,
+					TLS:       tls,
+					TLSSecret: secret,
+				})
+			}
+		}
+	}
+
+	return res
+}
+
+func getServiceEndpoints(svc *v1.Service, nodeAddrs []string) []Endpoint {
+	res := make([]Endpoint, 0)
+
+	switch svc.Spec.Type {
+	case v1.ServiceTypeLoadBalancer:
+		addrs := getLoadBalancerAddresses(svc.Status.LoadBalancer)
+
+		if len(addrs) == 0 {
+			return append(res, Endpoint{
+				Kind:    "LoadBalancer",
+				Name:    svc.Name,
+				Pending: true,
+			})
+		}
+
+		for _, addr := range addrs {
+			for _, port := range svc.Spec.Ports {
+				res = append(res, Endpoint{
+					Kind: "LoadBalancer",
+					Name: svc.Name,
+					URL:  fmt.Sprintf("%s:%d", addr, port.Port),
+					Host: addr,
+					Port: port.Port,
+				})
+			}
+		}
+	case v1.ServiceTypeNodePort:
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort == 0 {
+				continue
+			}
+
+			if len(nodeAddrs) == 0 {
+				res = append(res, Endpoint{
+					Kind: "NodePort",
+					Name: svc.Name,
+					Port: port.NodePort,
+				})
+
+				continue
+			}
+
+			for _, addr := range nodeAddrs {
+				res = append(res, Endpoint{
+					Kind: "NodePort",
+					Name: svc.Name,
+					URL:  fmt.Sprintf("%s:%d", addr, port.NodePort),
+					Host: addr,
+					Port: port.NodePort,
+				})
+			}
+		}
+	}
+
+	return res
+}
+
+func getLoadBalancerAddresses(status v1.LoadBalancerStatus) []string {
+	res := make([]string, 0)
+
+	for _, ingress := range status.Ingress {
+		if ingress.Hostname != "" {
+			res = append(res, ingress.Hostname)
+		} else if ingress.IP != "" {
+			res = append(res, ingress.IP)
+		}
+	}
+
+	return res
+}
+
+// getNodeAddresses returns the external IPs of the cluster's nodes
+func (a *Agent) getNodeAddresses() ([]string, error) {
+	nodes, err := a.Clientset.CoreV1().Nodes().List(
+		context.TODO(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+
+	for _, node := range nodes.Items {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == v1.NodeExternalIP {
+				res = append(res, addr.Address)
+			}
+		}
+	}
+
+	return res, nil
+}
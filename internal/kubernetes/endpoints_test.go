@@ -0,0 +1,157 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetEndpoints(t *testing.T) {
+	agent := newAgentFixture(
+		t,
+		&v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+			},
+			Spec: v1beta1.IngressSpec{
+				TLS: []v1beta1.IngressTLS{
+					v1beta1.IngressTLS{
+						Hosts:      []string{"app.example.com"},
+						SecretName: "app-tls",
+					},
+				},
+				Rules: []v1beta1.IngressRule{
+					v1beta1.IngressRule{
+						Host: "app.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{
+							HTTP: &v1beta1.HTTPIngressRuleValue{
+								Paths: []v1beta1.HTTPIngressPath{
+									v1beta1.HTTPIngressPath{Path: "/api"},
+								},
+							},
+						},
+					},
+					v1beta1.IngressRule{
+						Host: "plain.example.com",
+					},
+				},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lb",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{
+					v1.ServicePort{Port: 80},
+				},
+			},
+			Status: v1.ServiceStatus{
+				LoadBalancer: v1.LoadBalancerStatus{
+					Ingress: []v1.LoadBalancerIngress{
+						v1.LoadBalancerIngress{IP: "1.2.3.4"},
+					},
+				},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pending-lb",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "np",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeNodePort,
+				Ports: []v1.ServicePort{
+					v1.ServicePort{Port: 80, NodePort: 30080},
+				},
+			},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-0",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					v1.NodeAddress{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+					v1.NodeAddress{Type: v1.NodeExternalIP, Address: "5.6.7.8"},
+				},
+			},
+		},
+	)
+
+	objs := []grapher.Object{
+		grapher.Object{Kind: "Ingress", Name: "web"},
+		grapher.Object{Kind: "Service", Name: "lb"},
+		grapher.Object{Kind: "Service", Name: "pending-lb"},
+		grapher.Object{Kind: "Service", Name: "np"},
+		// objects that are not in the cluster are ignored
+		grapher.Object{Kind: "Service", Name: "missing"},
+		grapher.Object{Kind: "Deployment", Name: "web"},
+	}
+
+	endpoints, err := agent.GetEndpoints(objs, "default")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := []kubernetes.Endpoint{
+		kubernetes.Endpoint{
+			Kind:      "Ingress",
+			Name:      "web",
+			URL:       "https://app.example.com/api",
+			Host:      "app.example.com",
+			Path:      "/api",
+			TLS:       true,
+			TLSSecret: "app-tls",
+		},
+		kubernetes.Endpoint{
+			Kind: "Ingress",
+			Name: "web",
+			URL:  "http://plain.example.com/",
+			Host: "plain.example.com",
+			Path: "/",
+		},
+		kubernetes.Endpoint{
+			Kind: "LoadBalancer",
+			Name: "lb",
+			URL:  "1.2.3.4:80",
+			Host: "1.2.3.4",
+			Port: 80,
+		},
+		kubernetes.Endpoint{
+			Kind:    "LoadBalancer",
+			Name:    "pending-lb",
+			Pending: true,
+		},
+		kubernetes.Endpoint{
+			Kind: "NodePort",
+			Name: "np",
+			URL:  "5.6.7.8:30080",
+			Host: "5.6.7.8",
+			Port: 30080,
+		},
+	}
+
+	if diff := deep.Equal(endpoints, expected); diff != nil {
+		t.Errorf("endpoints not equal:")
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,432 @@
+package informer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	di "k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultIdleTimeout is how long an informer keeps running after its last
+// subscriber has left, so that reconnecting clients do not trigger a full relist
+const DefaultIdleTimeout = 5 * time.Minute
+
+// DefaultSyncTimeout is how long Subscribe waits for a new informer to list the
+// resource before giving up
+const DefaultSyncTimeout = 30 * time.Second
+
+// resyncPeriod is the resync period of every informer. Each resync delivers an
+// update for every object to all subscribers.
+const resyncPeriod = 10 * time.Second
+
+// Key identifies a shared informer: informers are shared between subscribers that
+// watch the same resource in the same namespace of the same cluster as the same
+// identity
+type Key struct {
+	ClusterID uint
+	GVR       schema.GroupVersionResource
+
+	// Identity is the Kubernetes identity that the informer's client acts as, so
+	// that subscribers never receive objects that their own identity cannot list
+	Identity string
+
+	// Namespace is empty for cluster-scoped resources, or to watch all namespaces
+	Namespace string
+}
+
+// Subscriber registers event handlers on shared informers for a single cluster
+type Subscriber interface {
+	// Subscribe adds a handler for the resource in the namespace, starting an
+	// informer if one is not already running. Objects that are already in the
+	// informer's cache are delivered to the handler as adds. Events are delivered
+	// to each handler from its own goroutine, in order. The returned function
+	// removes the handler, and must be called when the handler is no longer needed.
+	Subscribe(
+		gvr schema.GroupVersionResource,
+		namespace string,
+		handler cache.ResourceEventHandler,
+	) (unsubscribe func(), err error)
+}
+
+// Registry is a reference-counted set of dynamic informers, shared across all
+// connections to the same cluster. An informer is started by its first subscriber,
+// and is stopped once it has had no subscribers for the idle timeout.
+type Registry struct {
+	IdleTimeout time.Duration
+	SyncTimeout time.Duration
+
+	mu        sync.Mutex
+	informers map[Key]*sharedInformer
+}
+
+// NewRegistry creates a new Registry that stops informers after they have been
+// idle for idleTimeout
+func NewRegistry(idleTimeout time.Duration) *Registry {
+	return &Registry{
+		IdleTimeout: idleTimeout,
+		SyncTimeout: DefaultSyncTimeout,
+		informers:   make(map[Key]*sharedInformer),
+	}
+}
+
+// ForCluster returns a Subscriber for the cluster with the passed ID, which starts
+// informers using the passed client. The client is only used to start informers,
+// so subscribers joining a running informer share the client it was started with.
+func (r *Registry) ForCluster(clusterID uint, client dynamic.Interface) Subscriber {
+	return r.ForIdentity(clusterID, "", client)
+}
+
+// ForIdentity returns a Subscriber like ForCluster, whose informers are only shared
+// with subscribers that act as the same Kubernetes identity in the cluster
+func (r *Registry) ForIdentity(clusterID uint, identity string, client dynamic.Interface) Subscriber {
+	return &clusterSubscriber{
+		registry:  r,
+		clusterID: clusterID,
+		identity:  identity,
+		client:    client,
+	}
+}
+
+// Active returns the number of informers that are currently running
+func (r *Registry) Active() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.informers)
+}
+
+// Subscribe adds a handler to the informer identified by key, see Subscriber
+func (r *Registry) Subscribe(
+	key Key,
+	client dynamic.Interface,
+	handler cache.ResourceEventHandler,
+) (func(), error) {
+	r.mu.Lock()
+
+	s, ok := r.informers[key]
+
+	if !ok {
+		s = newSharedInformer(client, key)
+		r.informers[key] = s
+
+		go s.informer.Run(s.stopCh)
+	}
+
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+
+	id := s.addHandler(handler)
+
+	r.mu.Unlock()
+
+	var once sync.Once
+
+	unsubscribe := func() {
+		once.Do(func() {
+			r.unsubscribe(key, s, id)
+		})
+	}
+
+	timeoutCh := make(chan struct{})
+	timer := time.AfterFunc(r.SyncTimeout, func() { close(timeoutCh) })
+	defer timer.Stop()
+
+	if !cache.WaitForCacheSync(timeoutCh, s.informer.HasSynced) {
+		unsubscribe()
+
+		return nil, fmt.Errorf(
+			"timed out waiting for %s to sync in cluster %d",
+			key.GVR.String(),
+			key.ClusterID,
+		)
+	}
+
+	return unsubscribe, nil
+}
+
+// unsubscribe removes a handler, and starts the idle timer of the informer if
+// there are no handlers left
+func (r *Registry) unsubscribe(key Key, s *sharedInformer, id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s.removeHandler(id) > 0 {
+		return
+	}
+
+	s.idleTimer = time.AfterFunc(r.IdleTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// a subscriber may have joined while the timer was firing
+		if r.informers[key] != s || s.count() > 0 {
+			return
+		}
+
+		delete(r.informers, key)
+		close(s.stopCh)
+	})
+}
+
+type clusterSubscriber struct {
+	registry  *Registry
+	clusterID uint
+	identity  string
+	client    dynamic.Interface
+}
+
+func (c *clusterSubscriber) Subscribe(
+	gvr schema.GroupVersionResource,
+	namespace string,
+	handler cache.ResourceEventHandler,
+) (func(), error) {
+	return c.registry.Subscribe(
+		Key{
+			ClusterID: c.clusterID,
+			GVR:       gvr,
+			Identity:  c.identity,
+			Namespace: namespace,
+		},
+		c.client,
+		handler,
+	)
+}
+
+// sharedInformer is a single running informer, which fans its events out to every
+// subscribed handler. Each handler has its own queue, so that a slow handler does
+// not block the informer or the other handlers.
+type sharedInformer struct {
+	informer cache.SharedIndexInformer
+	stopCh   chan struct{}
+
+	// idleTimer is guarded by the registry's mutex
+	idleTimer *time.Timer
+
+	mu       sync.RWMutex
+	handlers map[int]*subscriber
+	nextID   int
+}
+
+func newSharedInformer(client dynamic.Interface, key Key) *sharedInformer {
+	s := &sharedInformer{
+		informer: di.NewFilteredDynamicInformer(
+			client,
+			key.GVR,
+			key.Namespace,
+			resyncPeriod,
+			cache.Indexers{},
+			nil,
+		).Informer(),
+		stopCh:   make(chan struct{}),
+		handlers: make(map[int]*subscriber),
+	}
+
+	s.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+
+			for _, sub := range s.handlers {
+				sub.pushAdd(obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			s.enqueue(newObj, func(h cache.ResourceEventHandler) { h.OnUpdate(oldObj, newObj) })
+		},
+		DeleteFunc: func(obj interface{}) {
+			s.enqueue(obj, func(h cache.ResourceEventHandler) { h.OnDelete(obj) })
+		},
+	})
+
+	return s
+}
+
+// enqueue queues an update or delete of an object for every subscribed handler
+func (s *sharedInformer) enqueue(obj interface{}, event func(h cache.ResourceEventHandler)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, sub := range s.handlers {
+		sub.forget(obj)
+		sub.push(event)
+	}
+}
+
+// addHandler registers a handler and returns its id. The objects in the informer's
+// cache are always replayed to the handler as adds, under the same lock that events
+// are queued under, so the handler misses no object even while the informer syncs.
+func (s *sharedInformer) addHandler(handler cache.ResourceEventHandler) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := newSubscriber(handler)
+
+	for _, obj := range s.informer.GetStore().List() {
+		sub.replay(obj)
+	}
+
+	id := s.nextID
+	s.nextID++
+	s.handlers[id] = sub
+
+	return id
+}
+
+// removeHandler removes a handler and returns the number of handlers left. Events
+// still queued for the handler are dropped.
+func (s *sharedInformer) removeHandler(id int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.handlers[id]; ok {
+		sub.stop()
+		delete(s.handlers, id)
+	}
+
+	return len(s.handlers)
+}
+
+func (s *sharedInformer) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.handlers)
+}
+
+// subscriber delivers the events of an informer to a single handler from its own
+// goroutine. Its queue is unbounded, so pushing an event never blocks.
+type subscriber struct {
+	handler cache.ResourceEventHandler
+
+	mu    sync.Mutex
+	queue []func(h cache.ResourceEventHandler)
+
+	// replayed has the resource version of each replayed object by its key, until
+	// the informer delivers an event for the object. The cache is updated before
+	// the informer delivers the add of an object, so an add of a replayed object
+	// at the same version is dropped.
+	replayed map[string]string
+
+	signal chan struct{}
+	done   chan struct{}
+}
+
+func newSubscriber(handler cache.ResourceEventHandler) *subscriber {
+	sub := &subscriber{
+		handler:  handler,
+		replayed: make(map[string]string),
+		signal:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go sub.run()
+
+	return sub
+}
+
+func (sub *subscriber) push(event func(h cache.ResourceEventHandler)) {
+	sub.mu.Lock()
+	sub.queue = append(sub.queue, event)
+	sub.mu.Unlock()
+
+	select {
+	case sub.signal <- struct{}{}:
+	default:
+	}
+}
+
+// replay queues an add of an object that was in the informer's cache when the
+// handler subscribed
+func (sub *subscriber) replay(obj interface{}) {
+	if key, version, ok := objectVersion(obj); ok {
+		sub.mu.Lock()
+		sub.replayed[key] = version
+		sub.mu.Unlock()
+	}
+
+	sub.push(func(h cache.ResourceEventHandler) { h.OnAdd(obj) })
+}
+
+// pushAdd queues an add from the informer, unless the object was already replayed
+// at the same version
+func (sub *subscriber) pushAdd(obj interface{}) {
+	if key, version, ok := objectVersion(obj); ok {
+		sub.mu.Lock()
+		replayedVersion, wasReplayed := sub.replayed[key]
+		delete(sub.replayed, key)
+		sub.mu.Unlock()
+
+		if wasReplayed && replayedVersion == version {
+			return
+		}
+	}
+
+	sub.push(func(h cache.ResourceEventHandler) { h.OnAdd(obj) })
+}
+
+// forget stops dropping adds of a replayed object, once the informer has delivered
+// another event for it
+func (sub *subscriber) forget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	if key, _, ok := objectVersion(obj); ok {
+		sub.mu.Lock()
+		delete(sub.replayed, key)
+		sub.mu.Unlock()
+	}
+}
+
+func (sub *subscriber) stop() {
+	close(sub.done)
+}
+
+func (sub *subscriber) run() {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-sub.signal:
+		}
+
+		sub.mu.Lock()
+		events := sub.queue
+		sub.queue = nil
+		sub.mu.Unlock()
+
+		for _, event := range events {
+			select {
+			case <-sub.done:
+				return
+			default:
+			}
+
+			event(sub.handler)
+		}
+	}
+}
+
+// objectVersion returns the cache key and the resource version of an object
+func objectVersion(obj interface{}) (string, string, bool) {
+	accessor, err := meta.Accessor(obj)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	return key, accessor.GetResourceVersion(), true
+}
@@ -0,0 +1,250 @@
+package informer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newUnstructuredConfigMap(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+		},
+	}
+}
+
+// addRecorder sends the name of every added object on a channel
+func addRecorder() (cache.ResourceEventHandler, chan string) {
+	names := make(chan string, 10)
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			names <- obj.(*unstructured.Unstructured).GetName()
+		},
+	}, names
+}
+
+func expectAdd(t *testing.T, names chan string, expName string) {
+	t.Helper()
+
+	select {
+	case name := <-names:
+		if name != expName {
+			t.Errorf("wrong object added: expected %s, got %s", expName, name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("timed out waiting for %s to be added", expName)
+	}
+}
+
+func TestRegistrySharesInformers(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newUnstructuredConfigMap("default", "config-0"),
+	)
+
+	registry := informer.NewRegistry(informer.DefaultIdleTimeout)
+	subscriber := registry.ForCluster(1, client)
+
+	handler0, names0 := addRecorder()
+	unsubscribe0, err := subscriber.Subscribe(configMapsGVR, "default", handler0)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe0()
+
+	expectAdd(t, names0, "config-0")
+
+	// the second subscriber joins the running informer, and has the cached
+	// objects replayed
+	handler1, names1 := addRecorder()
+	unsubscribe1, err := subscriber.Subscribe(configMapsGVR, "default", handler1)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe1()
+
+	expectAdd(t, names1, "config-0")
+
+	if active := registry.Active(); active != 1 {
+		t.Errorf("wrong number of active informers: expected 1, got %d", active)
+	}
+
+	_, err = client.Resource(configMapsGVR).Namespace("default").Create(
+		context.TODO(),
+		newUnstructuredConfigMap("default", "config-1"),
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectAdd(t, names0, "config-1")
+	expectAdd(t, names1, "config-1")
+
+	// a different cluster gets its own informer
+	handler2, names2 := addRecorder()
+	unsubscribe2, err := registry.ForCluster(2, client).Subscribe(configMapsGVR, "default", handler2)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe2()
+
+	expectAdd(t, names2, "config-0")
+	expectAdd(t, names2, "config-1")
+
+	if active := registry.Active(); active != 2 {
+		t.Errorf("wrong number of active informers: expected 2, got %d", active)
+	}
+
+	// a different identity in the same cluster gets its own informer
+	handler3, names3 := addRecorder()
+	unsubscribe3, err := registry.ForIdentity(1, "porter-viewer", client).Subscribe(configMapsGVR, "default", handler3)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe3()
+
+	expectAdd(t, names3, "config-0")
+	expectAdd(t, names3, "config-1")
+
+	if active := registry.Active(); active != 3 {
+		t.Errorf("wrong number of active informers: expected 3, got %d", active)
+	}
+}
+
+func TestRegistryStopsIdleInformers(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newUnstructuredConfigMap("default", "config-0"),
+	)
+
+	registry := informer.NewRegistry(10 * time.Millisecond)
+	subscriber := registry.ForCluster(1, client)
+
+	handler0, _ := addRecorder()
+	unsubscribe0, err := subscriber.Subscribe(configMapsGVR, "default", handler0)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	handler1, _ := addRecorder()
+	unsubscribe1, err := subscriber.Subscribe(configMapsGVR, "default", handler1)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the informer is kept running while it has a subscriber
+	unsubscribe0()
+	time.Sleep(50 * time.Millisecond)
+
+	if active := registry.Active(); active != 1 {
+		t.Errorf("wrong number of active informers: expected 1, got %d", active)
+	}
+
+	unsubscribe1()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for registry.Active() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle informer was not stopped")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// subscribing again starts a new informer
+	handler2, names2 := addRecorder()
+	unsubscribe2, err := subscriber.Subscribe(configMapsGVR, "default", handler2)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe2()
+
+	expectAdd(t, names2, "config-0")
+}
+
+func TestRegistrySlowSubscriber(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newUnstructuredConfigMap("default", "config-0"),
+	)
+
+	registry := informer.NewRegistry(informer.DefaultIdleTimeout)
+	subscriber := registry.ForCluster(1, client)
+
+	// the slow subscriber blocks on its first event until the test ends
+	block := make(chan struct{})
+	defer close(block)
+
+	unsubscribeSlow, err := subscriber.Subscribe(configMapsGVR, "default", cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			<-block
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribeSlow()
+
+	handler, names := addRecorder()
+	unsubscribe, err := subscriber.Subscribe(configMapsGVR, "default", handler)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer unsubscribe()
+
+	expectAdd(t, names, "config-0")
+
+	_, err = client.Resource(configMapsGVR).Namespace("default").Create(
+		context.TODO(),
+		newUnstructuredConfigMap("default", "config-1"),
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expectAdd(t, names, "config-1")
+
+	select {
+	case name := <-names:
+		t.Errorf("unexpected add of %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,360 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NodeSummary describes a node's status, its capacity, and the sum of the requests
+// and limits of the pods scheduled on it
+type NodeSummary struct {
+	Name           string             `json:"name"`
+	Labels         map[string]string  `json:"labels"`
+	Taints         []v1.Taint         `json:"taints"`
+	Conditions     []v1.NodeCondition `json:"conditions"`
+	KubeletVersion string             `json:"kubelet_version"`
+	Unschedulable  bool               `json:"unschedulable"`
+	Capacity       v1.ResourceList    `json:"capacity"`
+	Allocatable    v1.ResourceList    `json:"allocatable"`
+	Requests       v1.ResourceList    `json:"requests"`
+	Limits         v1.ResourceList    `json:"limits"`
+	PodCount       int                `json:"pod_count"`
+}
+
+// ClusterCapacity is the list of nodes in a cluster along with the total capacity,
+// allocatable resources and pod requests across all nodes
+type ClusterCapacity struct {
+	Nodes       []NodeSummary   `json:"nodes"`
+	Capacity    v1.ResourceList `json:"capacity"`
+	Allocatable v1.ResourceList `json:"allocatable"`
+	Requests    v1.ResourceList `json:"requests"`
+	Limits      v1.ResourceList `json:"limits"`
+}
+
+// DrainOptions are the options for draining a node
+type DrainOptions struct {
+	// Force evicts pods that are not managed by a controller
+	Force bool `json:"force"`
+
+	// DeleteLocalData evicts pods that use emptyDir volumes
+	DeleteLocalData bool `json:"delete_local_data"`
+}
+
+// PodDrainStatus is the result of attempting to evict a single pod
+type PodDrainStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// DrainResult lists the pods that were evicted from a node, the pods that were
+// skipped, and the pods whose eviction was blocked by a PodDisruptionBudget
+type DrainResult struct {
+	Evicted []PodDrainStatus `json:"evicted"`
+	Skipped []PodDrainStatus `json:"skipped"`
+	Blocked []PodDrainStatus `json:"blocked"`
+}
+
+// GetClusterCapacity lists the nodes in the cluster, and sums the requests and
+// limits of the running pods scheduled on each node
+func (a *Agent) GetClusterCapacity() (*ClusterCapacity, error) {
+	nodes, err := a.Clientset.CoreV1().Nodes().List(
+		context.TODO(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := a.Clientset.CoreV1().Pods("").List(
+		context.TODO(),
+		metav1.ListOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ClusterCapacity{
+		Nodes:       make([]NodeSummary, 0),
+		Capacity:    make(v1.ResourceList),
+		Allocatable: make(v1.ResourceList),
+		Requests:    make(v1.ResourceList),
+		Limits:      make(v1.ResourceList),
+	}
+
+	summaries := make(map[string]*NodeSummary)
+
+	for _, node := range nodes.Items {
+		summaries[node.Name] = &NodeSummary{
+			Name:           node.Name,
+			Labels:         node.Labels,
+			Taints:         node.Spec.Taints,
+			Conditions:     node.Status.Conditions,
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+			Unschedulable:  node.Spec.Unschedulable,
+			Capacity:       node.Status.Capacity,
+			Allocatable:    node.Status.Allocatable,
+			Requests:       make(v1.ResourceList),
+			Limits:         make(v1.ResourceList),
+		}
+
+		addResourceList(res.Capacity, node.Status.Capacity)
+		addResourceList(res.Allocatable, node.Status.Allocatable)
+	}
+
+	for _, pod := range pods.Items {
+		summary, ok := summaries[pod.Spec.NodeName]
+
+		// skip unscheduled and terminated pods, since they do not consume resources
+		if !ok || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		reqs, limits := podRequestsAndLimits(&pod)
+
+		addResourceList(summary.Requests, reqs)
+		addResourceList(summary.Limits, limits)
+		addResourceList(res.Requests, reqs)
+		addResourceList(res.Limits, limits)
+		summary.PodCount++
+	}
+
+	for _, node := range nodes.Items {
+		res.Nodes = append(res.Nodes, *summaries[node.Name])
+	}
+
+	return res, nil
+}
+
+// CordonNode marks a node as unschedulable, or schedulable if unschedulable is false
+func (a *Agent) CordonNode(name string, unschedulable bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable))
+
+	_, err := a.Clientset.CoreV1().Nodes().Patch(
+		context.TODO(),
+		name,
+		types.StrategicMergePatchType,
+		patch,
+		metav1.PatchOptions{},
+	)
+
+	return err
+}
+
+// DrainNode cordons a node and evicts the pods running on it through the eviction
+// API, so that PodDisruptionBudgets are respected. DaemonSet and mirror pods are
+// always skipped. Pods that a PodDisruptionBudget does not currently allow to be
+// disrupted are reported as blocked, and are not evicted. This does not wait for the
+// evicted pods to terminate.
+func (a *Agent) DrainNode(name string, opts DrainOptions) (*DrainResult, error) {
+	if err := a.CordonNode(name, true); err != nil {
+		return nil, err
+	}
+
+	pods, err := a.Clientset.CoreV1().Pods("").List(
+		context.TODO(),
+		metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + name,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &DrainResult{
+		Evicted: make([]PodDrainStatus, 0),
+		Skipped: make([]PodDrainStatus, 0),
+		Blocked: make([]PodDrainStatus, 0),
+	}
+
+	// the number of disruptions each pdb allows, keyed by namespace/name, which
+	// is decremented as pods are evicted
+	pdbs := make(map[string][]policyv1beta1.PodDisruptionBudget)
+	allowed := make(map[string]int32)
+
+	for _, pod := range pods.Items {
+		status := PodDrainStatus{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		}
+
+		// the field selector is not supported by every client, so check the node name
+		if pod.Spec.NodeName != name {
+			continue
+		}
+
+		if reason := drainSkipReason(&pod, opts); reason != "" {
+			status.Reason = reason
+			res.Skipped = append(res.Skipped, status)
+			continue
+		}
+
+		if _, ok := pdbs[pod.Namespace]; !ok {
+			pdbList, err := a.Clientset.PolicyV1beta1().PodDisruptionBudgets(pod.Namespace).List(
+				context.TODO(),
+				metav1.ListOptions{},
+			)
+
+			if err != nil {
+				return res, err
+			}
+
+			pdbs[pod.Namespace] = pdbList.Items
+
+			for _, pdb := range pdbList.Items {
+				allowed[pdb.Namespace+"/"+pdb.Name] = pdb.Status.DisruptionsAllowed
+			}
+		}
+
+		matching := matchingPDBs(&pod, pdbs[pod.Namespace])
+		blockedBy := ""
+
+		for _, pdb := range matching {
+			if allowed[pdb.Namespace+"/"+pdb.Name] <= 0 {
+				blockedBy = pdb.Name
+				break
+			}
+		}
+
+		if blockedBy != "" {
+			status.Reason = fmt.Sprintf("PodDisruptionBudget %s does not allow further disruptions", blockedBy)
+			res.Blocked = append(res.Blocked, status)
+			continue
+		}
+
+		err := a.Clientset.PolicyV1beta1().Evictions(pod.Namespace).Evict(
+			context.TODO(),
+			&policyv1beta1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			},
+		)
+
+		if k8serrors.IsTooManyRequests(err) {
+			status.Reason = err.Error()
+			res.Blocked = append(res.Blocked, status)
+			continue
+		} else if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return res, err
+		}
+
+		for _, pdb := range matching {
+			allowed[pdb.Namespace+"/"+pdb.Name]--
+		}
+
+		res.Evicted = append(res.Evicted, status)
+	}
+
+	return res, nil
+}
+
+// drainSkipReason returns the reason a pod should not be evicted during a drain,
+// or an empty string if it should be evicted
+func drainSkipReason(pod *v1.Pod, opts DrainOptions) string {
+	if _, ok := pod.Annotations[v1.MirrorPodAnnotationKey]; ok {
+		return "mirror pod"
+	}
+
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return "pod has terminated"
+	}
+
+	controller := metav1.GetControllerOf(pod)
+
+	if controller != nil && controller.Kind == "DaemonSet" {
+		return "managed by DaemonSet"
+	}
+
+	if controller == nil && !opts.Force {
+		return "not managed by a controller (use force to evict)"
+	}
+
+	if !opts.DeleteLocalData {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.EmptyDir != nil {
+				return "uses local storage (use delete_local_data to evict)"
+			}
+		}
+	}
+
+	return ""
+}
+
+// matchingPDBs returns the PodDisruptionBudgets whose selector matches the pod
+func matchingPDBs(pod *v1.Pod, pdbs []policyv1beta1.PodDisruptionBudget) []policyv1beta1.PodDisruptionBudget {
+	res := make([]policyv1beta1.PodDisruptionBudget, 0)
+
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+
+		// an empty selector matches nothing for pdbs
+		if err != nil || selector.Empty() {
+			continue
+		}
+
+		if selector.Matches(labels.Set(pod.Labels)) {
+			res = append(res, pdb)
+		}
+	}
+
+	return res
+}
+
+// podRequestsAndLimits computes the effective requests and limits of a pod: the sum
+// over its containers, or the largest init container if that is higher, plus any
+// pod overhead
+func podRequestsAndLimits(pod *v1.Pod) (reqs v1.ResourceList, limits v1.ResourceList) {
+	reqs, limits = make(v1.ResourceList), make(v1.ResourceList)
+
+	for _, container := range pod.Spec.Containers {
+		addResourceList(reqs, container.Resources.Requests)
+		addResourceList(limits, container.Resources.Limits)
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		maxResourceList(reqs, container.Resources.Requests)
+		maxResourceList(limits, container.Resources.Limits)
+	}
+
+	if pod.Spec.Overhead != nil {
+		addResourceList(reqs, pod.Spec.Overhead)
+		addResourceList(limits, pod.Spec.Overhead)
+	}
+
+	return reqs, limits
+}
+
+// addResourceList adds the resources in newList to list
+func addResourceList(list, newList v1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok {
+			list[name] = quantity.DeepCopy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
+}
+
+// maxResourceList sets list to the greater of list and newList for each resource
+func maxResourceList(list, newList v1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
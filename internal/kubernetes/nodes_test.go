@@ -0,0 +1,204 @@
+package kubernetes_test
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+)
+
+func newNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("1900m"),
+				v1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+			NodeInfo: v1.NodeSystemInfo{
+				KubeletVersion: "v1.18.8",
+			},
+		},
+	}
+}
+
+func newNodePod(name, node, cpu string, labels map[string]string, controllerKind string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Spec: v1.PodSpec{
+			NodeName: node,
+			Containers: []v1.Container{
+				v1.Container{
+					Name: "app",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU: resource.MustParse(cpu),
+						},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+		},
+	}
+
+	if controllerKind != "" {
+		isController := true
+
+		pod.OwnerReferences = []metav1.OwnerReference{
+			metav1.OwnerReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       controllerKind,
+				Name:       name + "-owner",
+				Controller: &isController,
+			},
+		}
+	}
+
+	return pod
+}
+
+func TestGetClusterCapacity(t *testing.T) {
+	agent := newAgentFixture(
+		t,
+		newNode("node-0"),
+		newNode("node-1"),
+		newNodePod("pod-0", "node-0", "250m", nil, "ReplicaSet"),
+		newNodePod("pod-1", "node-0", "500m", nil, "ReplicaSet"),
+		newNodePod("pod-2", "node-1", "100m", nil, "ReplicaSet"),
+		// unscheduled pods should not be counted
+		newNodePod("pod-3", "", "1", nil, "ReplicaSet"),
+	)
+
+	capacity, err := agent.GetClusterCapacity()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(capacity.Nodes) != 2 {
+		t.Fatalf("wrong number of nodes: expected %d, got %d", 2, len(capacity.Nodes))
+	}
+
+	node0 := capacity.Nodes[0]
+
+	if cpu := node0.Requests[v1.ResourceCPU]; cpu.String() != "750m" {
+		t.Errorf("wrong cpu requests for node-0: expected %s, got %s", "750m", cpu.String())
+	}
+
+	if node0.PodCount != 2 {
+		t.Errorf("wrong pod count for node-0: expected %d, got %d", 2, node0.PodCount)
+	}
+
+	if node0.KubeletVersion != "v1.18.8" {
+		t.Errorf("wrong kubelet version: expected %s, got %s", "v1.18.8", node0.KubeletVersion)
+	}
+
+	if cpu := capacity.Requests[v1.ResourceCPU]; cpu.String() != "850m" {
+		t.Errorf("wrong total cpu requests: expected %s, got %s", "850m", cpu.String())
+	}
+
+	if cpu := capacity.Capacity[v1.ResourceCPU]; cpu.String() != "4" {
+		t.Errorf("wrong total cpu capacity: expected %s, got %s", "4", cpu.String())
+	}
+}
+
+func TestDrainNode(t *testing.T) {
+	minAvailable := 1
+
+	agent := newAgentFixture(
+		t,
+		newNode("node-0"),
+		newNodePod("web-0", "node-0", "100m", map[string]string{"app": "web"}, "ReplicaSet"),
+		newNodePod("db-0", "node-0", "100m", map[string]string{"app": "db"}, "StatefulSet"),
+		newNodePod("logs-0", "node-0", "100m", nil, "DaemonSet"),
+		newNodePod("bare-0", "node-0", "100m", nil, ""),
+		&policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "db",
+				Namespace: "default",
+			},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: intOrStringPtr(minAvailable),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "db"},
+				},
+			},
+			Status: policyv1beta1.PodDisruptionBudgetStatus{
+				DisruptionsAllowed: 0,
+			},
+		},
+	)
+
+	evicted := make([]string, 0)
+
+	// the fake clientset does not support the eviction subresource
+	agent.Clientset.(*fake.Clientset).PrependReactor(
+		"create",
+		"pods",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+
+			eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+			evicted = append(evicted, eviction.Name)
+
+			return true, nil, nil
+		},
+	)
+
+	res, err := agent.DrainNode("node-0", kubernetes.DrainOptions{})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(evicted) != 1 || evicted[0] != "web-0" {
+		t.Errorf("wrong pods evicted: expected [web-0], got %v", evicted)
+	}
+
+	if len(res.Blocked) != 1 || res.Blocked[0].Name != "db-0" {
+		t.Errorf("wrong pods blocked: expected [db-0], got %v", res.Blocked)
+	}
+
+	if len(res.Skipped) != 2 {
+		t.Errorf("wrong number of pods skipped: expected %d, got %v", 2, res.Skipped)
+	}
+
+	node, err := agent.Clientset.CoreV1().Nodes().Get(context.TODO(), "node-0", metav1.GetOptions{})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !node.Spec.Unschedulable {
+		t.Errorf("expected node to be cordoned")
+	}
+}
+
+func intOrStringPtr(val int) *intstr.IntOrString {
+	res := intstr.FromInt(val)
+	return &res
+}
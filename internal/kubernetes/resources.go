@@ -0,0 +1,232 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// FieldManager is the field manager that Porter uses for server-side apply
+const FieldManager = "porter"
+
+// ListAPIResources lists the preferred version of every resource that the
+// api server serves, including custom resources. If some API groups cannot be
+// discovered, for example because their aggregated API server is down, the
+// resources of the other groups are still listed.
+func (a *Agent) ListAPIResources() ([]*metav1.APIResourceList, error) {
+	resources, err := a.Clientset.Discovery().ServerPreferredResources()
+
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// ListResources lists objects of an arbitrary resource. The group and version may
+// be left empty, in which case they are resolved through the REST mapper.
+func (a *Agent) ListResources(
+	gvr schema.GroupVersionResource,
+	namespace string,
+	opts ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	client, err := a.getResourceClient(gvr, namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.List(
+		context.TODO(),
+		metav1.ListOptions{
+			LabelSelector: opts.LabelSelector,
+			FieldSelector: opts.FieldSelector,
+		},
+	)
+}
+
+// GetResource gets a single object of an arbitrary resource by name
+func (a *Agent) GetResource(
+	gvr schema.GroupVersionResource,
+	namespace string,
+	name string,
+) (*unstructured.Unstructured, error) {
+	client, err := a.getResourceClient(gvr, namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// DeleteResource deletes a single object of an arbitrary resource by name
+func (a *Agent) DeleteResource(
+	gvr schema.GroupVersionResource,
+	namespace string,
+	name string,
+) error {
+	client, err := a.getResourceClient(gvr, namespace)
+
+	if err != nil {
+		return err
+	}
+
+	return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// ApplyResources reads a (possibly multi-document) YAML or JSON manifest and applies
+// each object using server-side apply, with Porter as the field manager. Namespaced
+// objects that do not specify a namespace are applied to the passed namespace.
+func (a *Agent) ApplyResources(manifest []byte, namespace string) ([]*unstructured.Unstructured, error) {
+	objs, err := decodeManifest(manifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, err := a.RESTClientGetter.ToRESTMapper()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyObjects(a.DynamicClient, mapper, namespace, objs)
+}
+
+// ApplyObjects applies each object using server-side apply, with Porter as the
+// field manager, and returns the applied objects. Namespaced objects that do not
+// specify a namespace are applied to the passed namespace.
+func ApplyObjects(
+	client dynamic.Interface,
+	mapper meta.RESTMapper,
+	namespace string,
+	objs []*unstructured.Unstructured,
+) ([]*unstructured.Unstructured, error) {
+	res := make([]*unstructured.Unstructured, 0)
+	force := true
+
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+
+		if err != nil {
+			return res, fmt.Errorf("could not find resource for %s: %v", gvk.String(), err)
+		}
+
+		var resource dynamic.ResourceInterface
+
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			}
+
+			resource = client.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		} else {
+			resource = client.Resource(mapping.Resource)
+		}
+
+		data, err := json.Marshal(obj)
+
+		if err != nil {
+			return res, err
+		}
+
+		applied, err := resource.Patch(
+			context.TODO(),
+			obj.GetName(),
+			types.ApplyPatchType,
+			data,
+			metav1.PatchOptions{
+				FieldManager: FieldManager,
+				Force:        &force,
+			},
+		)
+
+		if err != nil {
+			return res, fmt.Errorf("could not apply %s %s: %v", gvk.Kind, obj.GetName(), err)
+		}
+
+		res = append(res, applied)
+	}
+
+	return res, nil
+}
+
+// getResourceClient resolves a partially specified resource through the REST mapper,
+// and returns a client scoped to the namespace if the resource is namespaced
+func (a *Agent) getResourceClient(
+	gvr schema.GroupVersionResource,
+	namespace string,
+) (dynamic.ResourceInterface, error) {
+	mapper, err := a.RESTClientGetter.ToRESTMapper()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fullGVR, err := mapper.ResourceFor(gvr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, err := mapper.KindFor(fullGVR)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return a.DynamicClient.Resource(fullGVR).Namespace(namespace), nil
+	}
+
+	return a.DynamicClient.Resource(fullGVR), nil
+}
+
+// decodeManifest decodes a multi-document YAML or JSON manifest into a list of
+// unstructured objects, skipping empty documents
+func decodeManifest(manifest []byte) ([]*unstructured.Unstructured, error) {
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	res := make([]*unstructured.Unstructured, 0)
+
+	for {
+		obj := &unstructured.Unstructured{}
+
+		if err := dec.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return res, nil
+			}
+
+			return nil, err
+		}
+
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("every object in the manifest must have a kind and a name")
+		}
+
+		res = append(res, obj)
+	}
+}
@@ -0,0 +1,158 @@
+package kubernetes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func newUnstructuredConfigMap(namespace, name string, labels map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    labels,
+			},
+		},
+	}
+}
+
+func newDynamicAgentFixture(t *testing.T, objects ...runtime.Object) *kubernetes.Agent {
+	t.Helper()
+
+	agent := kubernetes.GetAgentTesting()
+	agent.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+
+	return agent
+}
+
+func TestListResources(t *testing.T) {
+	agent := newDynamicAgentFixture(
+		t,
+		newUnstructuredConfigMap("default", "config-0", map[string]interface{}{"app": "web"}),
+		newUnstructuredConfigMap("default", "config-1", map[string]interface{}{"app": "db"}),
+		newUnstructuredConfigMap("other", "config-2", map[string]interface{}{"app": "web"}),
+	)
+
+	// the version is resolved by the REST mapper
+	objs, err := agent.ListResources(
+		schema.GroupVersionResource{Resource: "configmaps"},
+		"default",
+		kubernetes.ListOptions{
+			LabelSelector: "app=web",
+		},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(objs.Items) != 1 || objs.Items[0].GetName() != "config-0" {
+		t.Errorf("wrong objects listed: expected [config-0], got %v", objs.Items)
+	}
+
+	_, err = agent.ListResources(
+		schema.GroupVersionResource{Resource: "doesnotexist"},
+		"default",
+		kubernetes.ListOptions{},
+	)
+
+	if err == nil {
+		t.Errorf("expected error listing unknown resource, got nil")
+	}
+}
+
+func TestGetAndDeleteResource(t *testing.T) {
+	agent := newDynamicAgentFixture(
+		t,
+		newUnstructuredConfigMap("default", "config-0", nil),
+	)
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	obj, err := agent.GetResource(gvr, "default", "config-0")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if obj.GetName() != "config-0" {
+		t.Errorf("wrong object: expected %s, got %s", "config-0", obj.GetName())
+	}
+
+	if err := agent.DeleteResource(gvr, "default", "config-0"); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err := agent.GetResource(gvr, "default", "config-0"); err == nil {
+		t.Errorf("expected object to be deleted")
+	}
+}
+
+func TestListAPIResourcesPartialDiscovery(t *testing.T) {
+	responses := map[string]interface{}{
+		"/api": &metav1.APIVersions{Versions: []string{"v1"}},
+		"/apis": &metav1.APIGroupList{
+			Groups: []metav1.APIGroup{
+				{
+					Name:             "metrics.k8s.io",
+					Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "metrics.k8s.io/v1beta1", Version: "v1beta1"}},
+					PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "metrics.k8s.io/v1beta1", Version: "v1beta1"},
+				},
+			},
+		},
+		"/api/v1": &metav1.APIResourceList{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Kind: "Pod", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+	}
+
+	// the metrics API server is unavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := responses[r.URL.Path]
+
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+
+	defer server.Close()
+
+	clientset, err := k8s.NewForConfig(&rest.Config{Host: server.URL})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	agent := kubernetes.GetAgentTesting()
+	agent.Clientset = clientset
+
+	resources, err := agent.ListAPIResources()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(resources) != 1 || resources[0].GroupVersion != "v1" {
+		t.Errorf("expected the resources of the core group, got %v", resources)
+	}
+}
@@ -0,0 +1,199 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/spdystream"
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/rest"
+)
+
+// ConnectPath returns the path of the endpoint at which the agent of a cluster
+// opens its tunnel
+func ConnectPath(projectID, clusterID uint) string {
+	return fmt.Sprintf("/api/projects/%d/clusters/%d/agent/connect", projectID, clusterID)
+}
+
+// Dial opens a tunnel from the agent of a cluster to the Porter server at
+// serverURL, authenticating with the agent's token
+func Dial(ctx context.Context, serverURL string, projectID, clusterID uint, token string) (*websocket.Conn, error) {
+	u, err := url.Parse(serverURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return nil, fmt.Errorf("unsupported server url scheme %q", u.Scheme)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + ConnectPath(projectID, clusterID)
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+
+	ws, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("could not open tunnel: server responded with %s", resp.Status)
+		}
+
+		return nil, fmt.Errorf("could not open tunnel: %v", err)
+	}
+
+	return ws, nil
+}
+
+// ServeAgent serves the connections that the server opens through the tunnel ws
+// with handler, and blocks until the tunnel is closed. The agent pings the server
+// every PingInterval, and closes the tunnel if the server stops responding.
+func ServeAgent(ws *websocket.Conn, handler http.Handler) error {
+	ws.SetReadDeadline(time.Now().Add(pingTimeout))
+
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(pingTimeout))
+	})
+
+	conn, err := spdystream.NewConnection(newWebsocketConn(ws), true)
+
+	if err != nil {
+		ws.Close()
+		return err
+	}
+
+	l := &streamListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+		addr:   ws.LocalAddr(),
+	}
+
+	go conn.Serve(func(stream *spdystream.Stream) {
+		if err := stream.SendReply(http.Header{}, false); err != nil {
+			return
+		}
+
+		select {
+		case l.conns <- newStreamConn(stream, ws.LocalAddr(), ws.RemoteAddr()):
+		case <-l.closed:
+			stream.Reset()
+		}
+	})
+
+	go func() {
+		ticker := time.NewTicker(PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+					ws.Close()
+					return
+				}
+			case <-conn.CloseChan():
+				l.Close()
+				return
+			}
+		}
+	}()
+
+	srv := &http.Server{Handler: handler}
+
+	err = srv.Serve(l)
+
+	// close the connections that are still being served, such as watches
+	srv.Close()
+	ws.Close()
+
+	if err == errListenerClosed {
+		return nil
+	}
+
+	return err
+}
+
+var errListenerClosed = errors.New("tunnel closed")
+
+// streamListener is a net.Listener that accepts the streams of a tunnel
+type streamListener struct {
+	conns chan net.Conn
+	addr  net.Addr
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (l *streamListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, errListenerClosed
+	}
+}
+
+func (l *streamListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *streamListener) Addr() net.Addr {
+	return l.addr
+}
+
+// NewAPIServerProxy returns a handler that proxies requests to the API server
+// configured in restConf, authenticating with the credentials of restConf.
+// Upgraded connections, such as those used to exec into pods, are supported.
+func NewAPIServerProxy(restConf *rest.Config) (http.Handler, error) {
+	restConf = rest.CopyConfig(restConf)
+
+	// connection upgrades are only supported over HTTP/1.1
+	restConf.TLSClientConfig.NextProtos = []string{"http/1.1"}
+
+	transport, err := rest.TransportFor(restConf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(restConf.Host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Scheme == "" {
+		target.Scheme = "https"
+	}
+
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = strings.TrimSuffix(target.Path, "/") + req.URL.Path
+			req.Host = ""
+
+			// requests are authenticated with the agent's service account
+			req.Header.Del("Authorization")
+		},
+		Transport: transport,
+		// flush immediately, so that watches and log streams are not buffered
+		FlushInterval: -1,
+	}, nil
+}
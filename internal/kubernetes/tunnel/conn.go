@@ -0,0 +1,293 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/docker/spdystream"
+	"github.com/gorilla/websocket"
+)
+
+// wsConn adapts a websocket connection to a net.Conn. Data is written as binary
+// messages, and message boundaries are ignored when reading.
+type wsConn struct {
+	ws *websocket.Conn
+
+	readMu sync.Mutex
+	reader io.Reader
+
+	writeMu sync.Mutex
+}
+
+// newWebsocketConn wraps a websocket connection in a net.Conn
+func newWebsocketConn(ws *websocket.Conn) net.Conn {
+	return &wsConn{ws: ws}
+}
+
+func (c *wsConn) Read(p []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for {
+		if c.reader == nil {
+			msgType, reader, err := c.ws.NextReader()
+
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return 0, io.EOF
+				}
+
+				return 0, err
+			}
+
+			if msgType != websocket.BinaryMessage {
+				continue
+			}
+
+			c.reader = reader
+		}
+
+		n, err := c.reader.Read(p)
+
+		if err == io.EOF {
+			c.reader = nil
+
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (c *wsConn) Close() error {
+	return c.ws.Close()
+}
+
+func (c *wsConn) LocalAddr() net.Addr {
+	return c.ws.LocalAddr()
+}
+
+func (c *wsConn) RemoteAddr() net.Addr {
+	return c.ws.RemoteAddr()
+}
+
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.ws.SetReadDeadline(t); err != nil {
+		return err
+	}
+
+	return c.ws.SetWriteDeadline(t)
+}
+
+func (c *wsConn) SetReadDeadline(t time.Time) error {
+	return c.ws.SetReadDeadline(t)
+}
+
+func (c *wsConn) SetWriteDeadline(t time.Time) error {
+	return c.ws.SetWriteDeadline(t)
+}
+
+// streamConn is a net.Conn over a single stream of a tunnel. The stream is read
+// in the background so that read deadlines, which the HTTP server relies on to
+// stop reading from connections, can be supported.
+type streamConn struct {
+	stream *spdystream.Stream
+
+	local, remote net.Addr
+
+	reads   chan readResult
+	pending []byte
+	readErr error
+
+	readDeadline deadline
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+func newStreamConn(stream *spdystream.Stream, local, remote net.Addr) *streamConn {
+	c := &streamConn{
+		stream:       stream,
+		local:        local,
+		remote:       remote,
+		reads:        make(chan readResult),
+		readDeadline: newDeadline(),
+		closed:       make(chan struct{}),
+	}
+
+	go c.readStream()
+
+	return c
+}
+
+func (c *streamConn) readStream() {
+	for {
+		buf := make([]byte, 32*1024)
+		n, err := c.stream.Read(buf)
+
+		select {
+		case c.reads <- readResult{buf[:n], err}:
+		case <-c.closed:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *streamConn) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 && c.readErr == nil {
+		select {
+		case res := <-c.reads:
+			c.pending, c.readErr = res.data, res.err
+		case <-c.readDeadline.wait():
+			return 0, timeoutError{}
+		case <-c.closed:
+			return 0, io.ErrClosedPipe
+		}
+	}
+
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+
+		return n, nil
+	}
+
+	return 0, c.readErr
+}
+
+func (c *streamConn) Write(p []byte) (int, error) {
+	return c.stream.Write(p)
+}
+
+// Close resets the stream rather than only closing the write side, so that the
+// stream is removed from the tunnel even if the other side never closes it
+func (c *streamConn) Close() error {
+	err := io.ErrClosedPipe
+
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.stream.Reset()
+	})
+
+	return err
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+// SetWriteDeadline is a no-op: writes are only blocked by the websocket, whose
+// write deadline is managed by the tunnel
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// deadline is a channel that is closed when a deadline expires, modelled on the
+// deadlines of net.Pipe
+type deadline struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+func newDeadline() deadline {
+	return deadline{cancel: make(chan struct{})}
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// wait for a running timer to close the channel
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel
+	}
+
+	d.timer = nil
+
+	closed := isClosed(d.cancel)
+
+	if t.IsZero() {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+
+		return
+	}
+
+	if dur := time.Until(t); dur > 0 {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+
+		cancel := d.cancel
+		d.timer = time.AfterFunc(dur, func() { close(cancel) })
+
+		return
+	}
+
+	if !closed {
+		close(d.cancel)
+	}
+}
+
+func (d *deadline) wait() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.cancel
+}
+
+func isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
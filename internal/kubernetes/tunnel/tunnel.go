@@ -0,0 +1,194 @@
+// Package tunnel implements the websocket tunnels through which in-cluster agents
+// expose the Kubernetes API servers of clusters that Porter cannot reach directly.
+//
+// The agent dials out to the Porter server and upgrades to a websocket, over which
+// a SPDY connection is run. Each connection that the server opens to the API
+// server is a SPDY stream, which the agent serves with a reverse proxy to the API
+// server.
+package tunnel
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/spdystream"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// PingInterval is how often the agent pings the server to keep the tunnel open
+	PingInterval = 30 * time.Second
+
+	// pingTimeout is how long the server waits for a ping before it considers the
+	// tunnel to be dead
+	pingTimeout = 3 * PingInterval
+
+	// writeTimeout bounds the writes of control messages to the websocket
+	writeTimeout = 10 * time.Second
+
+	// streamReplyTimeout is how long the server waits for the agent to accept a
+	// new stream
+	streamReplyTimeout = 30 * time.Second
+)
+
+// ErrAgentNotConnected is returned when dialing a cluster whose agent has no open
+// tunnel
+var ErrAgentNotConnected = errors.New("the cluster's agent is not connected")
+
+// Registry tracks the tunnels opened by the agents of each cluster
+type Registry struct {
+	mu      sync.Mutex
+	tunnels map[uint]*tunnel
+}
+
+type tunnel struct {
+	ws   *websocket.Conn
+	conn *spdystream.Connection
+
+	local, remote net.Addr
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		tunnels: make(map[uint]*tunnel),
+	}
+}
+
+// Serve runs the tunnel opened by the agent of a cluster over ws, and blocks until
+// the tunnel is closed. If the agent of the cluster already has an open tunnel, it
+// is replaced.
+func (r *Registry) Serve(clusterID uint, ws *websocket.Conn) error {
+	ws.SetReadDeadline(time.Now().Add(pingTimeout))
+
+	ws.SetPingHandler(func(data string) error {
+		ws.SetReadDeadline(time.Now().Add(pingTimeout))
+
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeTimeout))
+	})
+
+	conn, err := spdystream.NewConnection(newWebsocketConn(ws), false)
+
+	if err != nil {
+		ws.Close()
+		return err
+	}
+
+	go conn.Serve(spdystream.NoOpStreamHandler)
+
+	t := &tunnel{
+		ws:     ws,
+		conn:   conn,
+		local:  ws.LocalAddr(),
+		remote: ws.RemoteAddr(),
+	}
+
+	r.mu.Lock()
+	prev := r.tunnels[clusterID]
+	r.tunnels[clusterID] = t
+	r.mu.Unlock()
+
+	// closing the websocket ends the read loop of the SPDY connection, which
+	// resets its streams
+	if prev != nil {
+		prev.ws.Close()
+	}
+
+	<-conn.CloseChan()
+
+	ws.Close()
+
+	r.mu.Lock()
+
+	if r.tunnels[clusterID] == t {
+		delete(r.tunnels, clusterID)
+	}
+
+	r.mu.Unlock()
+
+	return nil
+}
+
+// IsConnected returns true if the agent of a cluster has an open tunnel
+func (r *Registry) IsConnected(clusterID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.tunnels[clusterID]
+
+	return ok
+}
+
+// DialCluster opens a connection to the API server of a cluster through the
+// tunnel of its agent
+func (r *Registry) DialCluster(ctx context.Context, clusterID uint) (net.Conn, error) {
+	r.mu.Lock()
+	t, ok := r.tunnels[clusterID]
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, ErrAgentNotConnected
+	}
+
+	stream, err := t.conn.CreateStream(http.Header{}, nil, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := streamReplyTimeout
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+
+		if timeout <= 0 {
+			stream.Reset()
+			return nil, context.DeadlineExceeded
+		}
+	}
+
+	if err := stream.WaitTimeout(timeout); err != nil {
+		stream.Reset()
+		return nil, fmt.Errorf("agent did not accept connection: %v", err)
+	}
+
+	return newStreamConn(stream, t.local, t.remote), nil
+}
+
+// NewAgentToken generates a token with which an agent authenticates to the server,
+// and the hash of the token that is stored
+func NewAgentToken() (token string, hash []byte, err error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashAgentToken(token), nil
+}
+
+// HashAgentToken returns the hash under which an agent token is stored
+func HashAgentToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// CheckAgentToken returns true if token matches the stored hash
+func CheckAgentToken(token string, hash []byte) bool {
+	if token == "" || len(hash) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(HashAgentToken(token), hash) == 1
+}
@@ -0,0 +1,343 @@
+package tunnel_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
+	"k8s.io/client-go/rest"
+)
+
+// newTunnelServer starts a server that opens a tunnel for every agent that
+// connects, on behalf of the cluster with id 1
+func newTunnelServer(t *testing.T, registry *tunnel.Registry) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tunnel.ConnectPath(1, 1) || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ws, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			return
+		}
+
+		registry.Serve(1, ws)
+	}))
+}
+
+// startAgent connects an agent that serves handler, and returns a function that
+// disconnects it
+func startAgent(t *testing.T, server *httptest.Server, registry *tunnel.Registry, handler http.Handler) func() {
+	t.Helper()
+
+	ws, err := tunnel.Dial(context.Background(), server.URL, 1, 1, "token")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		tunnel.ServeAgent(ws, handler)
+		close(done)
+	}()
+
+	waitFor(t, func() bool { return registry.IsConnected(1) })
+
+	return func() {
+		ws.Close()
+		<-done
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for condition")
+}
+
+func newClient(registry *tunnel.Registry) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return registry.DialCluster(ctx, 1)
+			},
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func get(t *testing.T, client *http.Client, path string) string {
+	t.Helper()
+
+	resp, err := client.Get("http://porter-agent" + path)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return string(body)
+}
+
+func TestTunnelRequests(t *testing.T) {
+	registry := tunnel.NewRegistry()
+	server := newTunnelServer(t, registry)
+	defer server.Close()
+
+	stop := startAgent(t, server, registry, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "path=%s", r.URL.Path)
+	}))
+	defer stop()
+
+	client := newClient(registry)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			path := fmt.Sprintf("/api/v1/namespaces/ns-%d", i)
+
+			if body := get(t, client, path); body != "path="+path {
+				t.Errorf("wrong body: expected path=%s, got %s", path, body)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestTunnelStreaming(t *testing.T) {
+	registry := tunnel.NewRegistry()
+	server := newTunnelServer(t, registry)
+	defer server.Close()
+
+	next := make(chan string)
+
+	stop := startAgent(t, server, registry, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		for line := range next {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer stop()
+
+	resp, err := newClient(registry).Get("http://porter-agent/watch")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+
+	// each line is received before the next one is sent
+	for _, exp := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		next <- exp
+
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if line != exp+"\n" {
+			t.Errorf("wrong line: expected %s, got %s", exp, line)
+		}
+	}
+
+	close(next)
+}
+
+func TestTunnelAgentDisconnected(t *testing.T) {
+	registry := tunnel.NewRegistry()
+	server := newTunnelServer(t, registry)
+	defer server.Close()
+
+	if _, err := registry.DialCluster(context.Background(), 1); err != tunnel.ErrAgentNotConnected {
+		t.Errorf("expected ErrAgentNotConnected, got %v", err)
+	}
+
+	stop := startAgent(t, server, registry, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "first")
+	}))
+
+	if body := get(t, newClient(registry), "/"); body != "first" {
+		t.Errorf("wrong body: expected first, got %s", body)
+	}
+
+	stop()
+
+	waitFor(t, func() bool { return !registry.IsConnected(1) })
+
+	// a reconnected agent is used by new clients
+	stop = startAgent(t, server, registry, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "second")
+	}))
+	defer stop()
+
+	if body := get(t, newClient(registry), "/"); body != "second" {
+		t.Errorf("wrong body: expected second, got %s", body)
+	}
+}
+
+func TestDialUnauthorized(t *testing.T) {
+	registry := tunnel.NewRegistry()
+	server := newTunnelServer(t, registry)
+	defer server.Close()
+
+	if _, err := tunnel.Dial(context.Background(), server.URL, 1, 1, "wrong"); err == nil {
+		t.Errorf("expected error for wrong token, got nil")
+	}
+}
+
+func TestAgentToken(t *testing.T) {
+	token, hash, err := tunnel.NewAgentToken()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !tunnel.CheckAgentToken(token, hash) {
+		t.Errorf("expected token to match its hash")
+	}
+
+	if tunnel.CheckAgentToken(token+"x", hash) || tunnel.CheckAgentToken("", nil) {
+		t.Errorf("expected wrong token not to match")
+	}
+}
+
+func TestAPIServerProxy(t *testing.T) {
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "" {
+			fmt.Fprintf(w, "path=%s auth=%s", r.URL.Path, r.Header.Get("Authorization"))
+			return
+		}
+
+		// echo lines back over the upgraded connection
+		conn, rw, err := w.(http.Hijacker).Hijack()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		fmt.Fprint(rw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+
+		line, _ := rw.ReadString('\n')
+		fmt.Fprint(rw, line)
+		rw.Flush()
+	}))
+	defer apiServer.Close()
+
+	proxy, err := tunnel.NewAPIServerProxy(&rest.Config{
+		Host:            apiServer.URL,
+		BearerToken:     "service-account-token",
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	registry := tunnel.NewRegistry()
+	server := newTunnelServer(t, registry)
+	defer server.Close()
+
+	stop := startAgent(t, server, registry, proxy)
+	defer stop()
+
+	// requests are authenticated with the agent's credentials
+	req, _ := http.NewRequest("GET", "http://porter-agent/api/v1/pods", nil)
+	req.Header.Set("Authorization", "Bearer other")
+
+	resp, err := newClient(registry).Do(req)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if exp := "path=/api/v1/pods auth=Bearer service-account-token"; string(body) != exp {
+		t.Errorf("wrong body: expected %s, got %s", exp, body)
+	}
+
+	// upgraded connections are proxied in both directions
+	conn, err := registry.DialCluster(context.Background(), 1)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	defer conn.Close()
+
+	fmt.Fprint(conn, "GET /api/v1/namespaces/default/pods/web/exec HTTP/1.1\r\n"+
+		"Host: porter-agent\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+
+	upgradeResp, err := http.ReadResponse(reader, nil)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if upgradeResp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("wrong status: expected 101, got %d", upgradeResp.StatusCode)
+	}
+
+	fmt.Fprint(conn, "hello\n")
+
+	line, err := reader.ReadString('\n')
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if line != "hello\n" {
+		t.Errorf("wrong echo: expected hello, got %q", line)
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"net/url"
 
 	"github.com/porter-dev/porter/internal/models/integrations"
 	"gorm.io/gorm"
@@ -19,6 +20,10 @@ const (
 	GCP    ClusterAuth = "gcp-sa"
 	AWS    ClusterAuth = "aws-sa"
 	Local  ClusterAuth = "local"
+
+	// Agent clusters are reached through a tunnel opened by an agent running
+	// inside the cluster, which authenticates with its own service account
+	Agent ClusterAuth = "agent"
 )
 
 // Cluster is an integration that can connect to a Kubernetes cluster via
@@ -57,6 +62,13 @@ type Cluster struct {
 	GCPIntegrationID  uint
 	AWSIntegrationID  uint
 
+	// The optional SSH bastion that API server traffic is tunneled through
+	BastionIntegrationID uint
+
+	// The hash of the token that the in-cluster agent authenticates with, for
+	// clusters that use the agent auth mechanism
+	AgentTokenHash []byte `json:"-"`
+
 	// A token cache that can be used by an auth mechanism, if desired
 	TokenCache integrations.TokenCache `json:"token_cache"`
 
@@ -79,6 +91,11 @@ type ClusterExternal struct {
 
 	// The integration service for this cluster
 	Service integrations.IntegrationService `json:"service"`
+
+	// The proxy and SSH bastion that API server traffic is routed through. The
+	// proxy URL is shared without its credentials.
+	ProxyURL             string `json:"proxy_url,omitempty"`
+	BastionIntegrationID uint   `json:"bastion_integration_id,omitempty"`
 }
 
 // Externalize generates an external Cluster to be shared over REST
@@ -97,9 +114,30 @@ func (c *Cluster) Externalize() *ClusterExternal {
 		Name:      c.Name,
 		Server:    c.Server,
 		Service:   serv,
+
+		ProxyURL:             stripUserInfo(c.ProxyURL),
+		BastionIntegrationID: c.BastionIntegrationID,
 	}
 }
 
+// stripUserInfo removes the username and password from a URL. A URL that cannot
+// be parsed is dropped, since it may contain credentials.
+func stripUserInfo(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return ""
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
 // ClusterCandidate is a cluster integration that requires additional action
 // from the user to set up.
 type ClusterCandidate struct {
@@ -0,0 +1,117 @@
+package integrations
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+// BastionIntegration is an SSH bastion host through which traffic to a cluster's
+// API server is tunneled, for clusters with private endpoints
+type BastionIntegration struct {
+	gorm.Model
+
+	// The id of the user that linked this bastion
+	UserID uint `json:"user_id"`
+
+	// The project that this integration belongs to
+	ProjectID uint `json:"project_id"`
+
+	// Host and Port are the address of the bastion's SSH server
+	Host string `json:"host"`
+	Port int    `json:"port"`
+
+	// User is the SSH user to log in as
+	User string `json:"user"`
+
+	// HostKey is the bastion's public key in authorized_keys format, which the
+	// bastion's host key is verified against
+	HostKey string `json:"host_key"`
+
+	// ------------------------------------------------------------------
+	// All fields encrypted before storage.
+	// ------------------------------------------------------------------
+
+	// PrivateKey is the PEM-encoded private key used to log in
+	PrivateKey []byte `json:"private_key"`
+
+	// Passphrase decrypts the private key, if it is encrypted
+	Passphrase []byte `json:"passphrase"`
+}
+
+// BastionIntegrationExternal is a BastionIntegration to be shared over REST
+type BastionIntegrationExternal struct {
+	ID uint `json:"id"`
+
+	// The id of the user that linked this bastion
+	UserID uint `json:"user_id"`
+
+	// The project that this integration belongs to
+	ProjectID uint `json:"project_id"`
+
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	User    string `json:"user"`
+	HostKey string `json:"host_key"`
+}
+
+// Externalize generates an external BastionIntegration to be shared over REST
+func (b *BastionIntegration) Externalize() *BastionIntegrationExternal {
+	return &BastionIntegrationExternal{
+		ID:        b.ID,
+		UserID:    b.UserID,
+		ProjectID: b.ProjectID,
+		Host:      b.Host,
+		Port:      b.Port,
+		User:      b.User,
+		HostKey:   b.HostKey,
+	}
+}
+
+// Address returns the host and port of the bastion's SSH server
+func (b *BastionIntegration) Address() string {
+	port := b.Port
+
+	if port == 0 {
+		port = 22
+	}
+
+	return net.JoinHostPort(b.Host, strconv.Itoa(port))
+}
+
+// SSHClientConfig creates the configuration used to log in to the bastion
+func (b *BastionIntegration) SSHClientConfig() (*ssh.ClientConfig, error) {
+	var signer ssh.Signer
+	var err error
+
+	if len(b.Passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(b.PrivateKey, b.Passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(b.PrivateKey)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse bastion private key: %v", err)
+	}
+
+	if b.HostKey == "" {
+		return nil, fmt.Errorf("bastion has no host key to verify")
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(b.HostKey))
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse bastion host key: %v", err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            b.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         30 * time.Second,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// the kinds of manifest releases
+const (
+	// ManifestKindRaw is a release of raw Kubernetes manifests
+	ManifestKindRaw = "manifests"
+
+	// ManifestKindKustomize is a release of a Kustomize base and overlay
+	ManifestKindKustomize = "kustomize"
+)
+
+// ManifestRelease is a release that is deployed from a set of raw Kubernetes
+// manifests, or from a Kustomize base and overlay, rather than from a Helm chart.
+// Porter stores the files of the release, since there is no Helm release in the
+// cluster to read them from.
+type ManifestRelease struct {
+	gorm.Model
+
+	// The project and cluster that the release is deployed to
+	ProjectID uint `json:"project_id"`
+	ClusterID uint `json:"cluster_id"`
+
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// Kind is either "manifests" or "kustomize"
+	Kind string `json:"kind"`
+
+	// Overlay is the directory of the Kustomize overlay that is built, for
+	// releases of the kustomize kind
+	Overlay string `json:"overlay,omitempty"`
+
+	// Revision is incremented every time the release is upgraded
+	Revision uint `json:"revision"`
+
+	// Files are the manifests, or the files of the Kustomize base and overlay,
+	// and may include a form.yaml
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file of a manifest release
+type ManifestFile struct {
+	gorm.Model
+
+	ManifestReleaseID uint `json:"-"`
+
+	// Path is the path of the file, relative to the root of the release's files
+	Path string `json:"path"`
+	Data string `json:"data"`
+}
+
+// ManifestReleaseExternal is a manifest release to be shared over REST
+type ManifestReleaseExternal struct {
+	ID        uint   `json:"id"`
+	ProjectID uint   `json:"project_id"`
+	ClusterID uint   `json:"cluster_id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Overlay   string `json:"overlay,omitempty"`
+	Revision  uint   `json:"revision"`
+
+	// Files maps the path of each file to its data
+	Files map[string]string `json:"files"`
+}
+
+// Externalize generates an external ManifestRelease to be shared over REST
+func (r *ManifestRelease) Externalize() *ManifestReleaseExternal {
+	files := make(map[string]string)
+
+	for _, file := range r.Files {
+		files[file.Path] = file.Data
+	}
+
+	return &ManifestReleaseExternal{
+		ID:        r.ID,
+		ProjectID: r.ProjectID,
+		ClusterID: r.ClusterID,
+		Name:      r.Name,
+		Namespace: r.Namespace,
+		Kind:      r.Kind,
+		Overlay:   r.Overlay,
+		Revision:  r.Revision,
+		Files:     files,
+	}
+}
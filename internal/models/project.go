@@ -13,6 +13,9 @@ type Project struct {
 	Name  string `json:"name"`
 	Roles []Role `json:"roles"`
 
+	// the Kubernetes identities that each role acts as in the project's clusters
+	RoleImpersonations []RoleImpersonation `json:"role_impersonations,omitempty"`
+
 	// linked repos
 	GitRepos []GitRepo `json:"git_repos,omitempty"`
 
@@ -23,12 +26,21 @@ type Project struct {
 	Clusters          []Cluster          `json:"clusters"`
 	ClusterCandidates []ClusterCandidate `json:"cluster_candidates"`
 
+	// releases deployed from raw manifests or Kustomize
+	ManifestReleases []ManifestRelease `json:"manifest_releases,omitempty"`
+
+	// stacks deployed from stack templates
+	Stacks []Stack `json:"stacks,omitempty"`
+
 	// auth mechanisms
 	KubeIntegrations  []ints.KubeIntegration  `json:"kube_integrations"`
 	OIDCIntegrations  []ints.OIDCIntegration  `json:"oidc_integrations"`
 	OAuthIntegrations []ints.OAuthIntegration `json:"oauth_integrations"`
 	AWSIntegrations   []ints.AWSIntegration   `json:"aws_integrations"`
 	GCPIntegrations   []ints.GCPIntegration   `json:"gcp_integrations"`
+
+	// bastions used to reach private clusters
+	BastionIntegrations []ints.BastionIntegration `json:"bastion_integrations"`
 }
 
 // ProjectExternal represents the Project type that is sent over REST
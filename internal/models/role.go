@@ -1,6 +1,8 @@
 package models
 
 import (
+	"strings"
+
 	"gorm.io/gorm"
 )
 
@@ -36,3 +38,60 @@ func (r *Role) Externalize() *RoleExternal {
 		ProjectID: r.ProjectID,
 	}
 }
+
+// RoleImpersonation maps a project role to the Kubernetes user and groups that
+// are impersonated when a member with that role calls a cluster of the project,
+// so that Kubernetes RBAC enforces the permissions of the role
+type RoleImpersonation struct {
+	gorm.Model
+
+	ProjectID uint `json:"project_id"`
+
+	// RoleKind is the kind of role that is mapped, such as admin or viewer
+	RoleKind string `json:"role_kind"`
+
+	// User is the Kubernetes user to impersonate
+	User string `json:"user"`
+
+	// Groups is a comma-separated list of Kubernetes groups to impersonate
+	Groups string `json:"groups"`
+}
+
+// RoleImpersonationExternal represents the RoleImpersonation type that is sent
+// over REST
+type RoleImpersonationExternal struct {
+	ID        uint     `json:"id"`
+	ProjectID uint     `json:"project_id"`
+	RoleKind  string   `json:"role_kind"`
+	User      string   `json:"user"`
+	Groups    []string `json:"groups"`
+}
+
+// GroupList returns the impersonated groups as a list
+func (ri *RoleImpersonation) GroupList() []string {
+	groups := make([]string, 0)
+
+	for _, group := range strings.Split(ri.Groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// Identity returns a string that identifies the impersonated user and groups
+func (ri *RoleImpersonation) Identity() string {
+	return ri.User + ":" + strings.Join(ri.GroupList(), ",")
+}
+
+// Externalize generates an external RoleImpersonation to be shared over REST
+func (ri *RoleImpersonation) Externalize() *RoleImpersonationExternal {
+	return &RoleImpersonationExternal{
+		ID:        ri.ID,
+		ProjectID: ri.ProjectID,
+		RoleKind:  ri.RoleKind,
+		User:      ri.User,
+		Groups:    ri.GroupList(),
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Stack is a deployment of a stack template, which deploys each chart of the
+// template as its own release. Porter stores the stack.yaml of the template, so
+// that the releases of the stack can be read and upgraded together.
+type Stack struct {
+	gorm.Model
+
+	// The project and cluster that the stack is deployed to
+	ProjectID uint `json:"project_id"`
+	ClusterID uint `json:"cluster_id"`
+
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// The stack template that the stack was last deployed from
+	TemplateName    string `json:"template_name"`
+	TemplateVersion string `json:"template_version"`
+	RepoURL         string `json:"repo_url"`
+
+	// Revision is incremented every time the stack is deployed
+	Revision uint `json:"revision"`
+
+	// Spec is the stack.yaml of the stack template
+	Spec string `json:"spec"`
+}
+
+// StackExternal is a stack to be shared over REST
+type StackExternal struct {
+	ID              uint   `json:"id"`
+	ProjectID       uint   `json:"project_id"`
+	ClusterID       uint   `json:"cluster_id"`
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	TemplateName    string `json:"template_name"`
+	TemplateVersion string `json:"template_version"`
+	Revision        uint   `json:"revision"`
+}
+
+// Externalize generates an external Stack to be shared over REST
+func (s *Stack) Externalize() *StackExternal {
+	return &StackExternal{
+		ID:              s.ID,
+		ProjectID:       s.ProjectID,
+		ClusterID:       s.ClusterID,
+		Name:            s.Name,
+		Namespace:       s.Namespace,
+		TemplateName:    s.TemplateName,
+		TemplateVersion: s.TemplateVersion,
+		Revision:        s.Revision,
+	}
+}
@@ -16,6 +16,9 @@ type PorterChartRead struct {
 	Metadata *chart.Metadata        `json:"metadata"`
 	Values   map[string]interface{} `json:"values"`
 	Form     *FormYAML              `json:"form"`
+
+	// Stack is set if the chart is a stack template
+	Stack *StackYAML `json:"stack,omitempty"`
 }
 
 // FormContext is the target context
@@ -38,20 +41,62 @@ type FormSection struct {
 	Name     string         `yaml:"name" json:"name"`
 	ShowIf   string         `yaml:"show_if" json:"show_if"`
 	Contents []*FormContent `yaml:"contents" json:"contents,omitempty"`
+
+	// Hidden is set when show_if evaluates to false for the values the form is
+	// read with
+	Hidden bool `yaml:"-" json:"hidden,omitempty"`
 }
 
 // FormContent is a form's atomic unit
 type FormContent struct {
-	Context  *FormContext `yaml:"context" json:"context"`
-	Type     string       `yaml:"type" json:"type"`
-	Label    string       `yaml:"label" json:"label"`
-	Name     string       `yaml:"name,omitempty" json:"name,omitempty"`
-	Variable string       `yaml:"variable,omitempty" json:"variable,omitempty"`
-	Value    interface{}  `yaml:"value,omitempty" json:"value,omitempty"`
-	Settings struct {
-		Default interface{} `yaml:"default,omitempty" json:"default,omitempty"`
-		Unit    interface{} `yaml:"unit,omitempty" json:"unit,omitempty"`
-	} `yaml:"settings,omitempty" json:"settings,omitempty"`
+	Context  *FormContext        `yaml:"context" json:"context"`
+	Type     string              `yaml:"type" json:"type"`
+	Label    string              `yaml:"label" json:"label"`
+	Name     string              `yaml:"name,omitempty" json:"name,omitempty"`
+	Variable string              `yaml:"variable,omitempty" json:"variable,omitempty"`
+	Value    interface{}         `yaml:"value,omitempty" json:"value,omitempty"`
+	Settings FormContentSettings `yaml:"settings,omitempty" json:"settings,omitempty"`
+
+	// Compute is an expression that computes the value of the content's variable
+	// from the other values
+	Compute string `yaml:"compute,omitempty" json:"compute,omitempty"`
+}
+
+// FormContentSettings configures how a form content is rendered, and validates the
+// value of the content
+type FormContentSettings struct {
+	Default interface{}   `yaml:"default,omitempty" json:"default,omitempty"`
+	Unit    interface{}   `yaml:"unit,omitempty" json:"unit,omitempty"`
+	Options []*FormOption `yaml:"options,omitempty" json:"options,omitempty"`
+
+	// OptionsFrom reads the options of a select content from a context, such as
+	// the namespaces of the cluster, which are set as the options when the form
+	// is read
+	OptionsFrom *FormOptionsFrom `yaml:"options_from,omitempty" json:"options_from,omitempty"`
+
+	// The validation rules of the value, which are checked before the
+	// values are deployed
+	Required  bool     `yaml:"required,omitempty" json:"required,omitempty"`
+	Pattern   string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Min       *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max       *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+	MinLength *int     `yaml:"min_length,omitempty" json:"min_length,omitempty"`
+	MaxLength *int     `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+}
+
+// FormOption is an option of a select content, the values of which are the only
+// values the content accepts
+type FormOption struct {
+	Label string      `yaml:"label" json:"label"`
+	Value interface{} `yaml:"value" json:"value"`
+}
+
+// FormOptionsFrom is a query that reads the options of a content from a context,
+// which defaults to the context of the content. Each value the query matches is an
+// option, and lists of values are options for each value in the list.
+type FormOptionsFrom struct {
+	Context *FormContext `yaml:"context" json:"context"`
+	Query   string       `yaml:"query" json:"query"`
 }
 
 // FormYAML represents a chart's values.yaml form abstraction
@@ -61,4 +106,45 @@ type FormYAML struct {
 	Description string     `yaml:"description" json:"description"`
 	Tags        []string   `yaml:"tags" json:"tags"`
 	Tabs        []*FormTab `yaml:"tabs" json:"tabs,omitempty"`
+
+	// Generated is set if the form was generated from the values of a chart
+	// without a form.yaml
+	Generated bool `yaml:"generated,omitempty" json:"generated,omitempty"`
+}
+
+// StackYAML represents the stack.yaml of a stack template, which deploys several
+// charts as a unit. Each chart is deployed as its own release, after the charts it
+// depends on.
+type StackYAML struct {
+	Name        string        `yaml:"name" json:"name"`
+	Description string        `yaml:"description" json:"description"`
+	Charts      []*StackChart `yaml:"charts" json:"charts"`
+}
+
+// StackChart is a chart of a stack template. The chart is read from the
+// dependencies of the stack template if it is vendored there, and otherwise is
+// loaded from the repo of the stack template, unless RepoURL is set.
+type StackChart struct {
+	// Name identifies the chart in the stack, and is appended to the name of the
+	// stack to name the chart's release
+	Name    string `yaml:"name" json:"name"`
+	Chart   string `yaml:"chart" json:"chart"`
+	Version string `yaml:"version" json:"version"`
+	RepoURL string `yaml:"repo_url" json:"repo_url,omitempty"`
+
+	// DependsOn are the names of the charts in the stack that are deployed before
+	// this chart
+	DependsOn []string `yaml:"depends_on" json:"depends_on,omitempty"`
+
+	Values     map[string]interface{} `yaml:"values" json:"values,omitempty"`
+	ValuesFrom []*StackValueReference `yaml:"values_from" json:"values_from,omitempty"`
+}
+
+// StackValueReference sets the value of a variable from the release of another
+// chart in the stack, which the chart then depends on. The query is a jsonpath
+// query against the name, namespace, values and manifests of the other release.
+type StackValueReference struct {
+	Variable string `yaml:"variable" json:"variable"`
+	Chart    string `yaml:"chart" json:"chart"`
+	Query    string `yaml:"query" json:"query"`
 }
@@ -907,3 +907,143 @@ func (repo *AWSIntegrationRepository) DecryptAWSIntegrationData(
 
 	return nil
 }
+
+// BastionIntegrationRepository uses gorm.DB for querying the database
+type BastionIntegrationRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewBastionIntegrationRepository returns a BastionIntegrationRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// sensitive data
+func NewBastionIntegrationRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.BastionIntegrationRepository {
+	return &BastionIntegrationRepository{db, key}
+}
+
+// CreateBastionIntegration creates a new bastion integration
+func (repo *BastionIntegrationRepository) CreateBastionIntegration(
+	am *ints.BastionIntegration,
+) (*ints.BastionIntegration, error) {
+	err := repo.EncryptBastionIntegrationData(am, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	project := &models.Project{}
+
+	if err := repo.db.Where("id = ?", am.ProjectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	assoc := repo.db.Model(&project).Association("BastionIntegrations")
+
+	if assoc.Error != nil {
+		return nil, assoc.Error
+	}
+
+	if err := assoc.Append(am); err != nil {
+		return nil, err
+	}
+
+	return am, nil
+}
+
+// ReadBastionIntegration finds a bastion integration by id
+func (repo *BastionIntegrationRepository) ReadBastionIntegration(
+	id uint,
+) (*ints.BastionIntegration, error) {
+	bastion := &ints.BastionIntegration{}
+
+	if err := repo.db.Where("id = ?", id).First(&bastion).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptBastionIntegrationData(bastion, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bastion, nil
+}
+
+// ListBastionIntegrationsByProjectID finds all bastion integrations
+// for a given project id
+func (repo *BastionIntegrationRepository) ListBastionIntegrationsByProjectID(
+	projectID uint,
+) ([]*ints.BastionIntegration, error) {
+	bastions := []*ints.BastionIntegration{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&bastions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, bastion := range bastions {
+		repo.DecryptBastionIntegrationData(bastion, repo.key)
+	}
+
+	return bastions, nil
+}
+
+// EncryptBastionIntegrationData will encrypt the bastion integration data before
+// writing to the DB
+func (repo *BastionIntegrationRepository) EncryptBastionIntegrationData(
+	bastion *ints.BastionIntegration,
+	key *[32]byte,
+) error {
+	if len(bastion.PrivateKey) > 0 {
+		cipherData, err := repository.Encrypt(bastion.PrivateKey, key)
+
+		if err != nil {
+			return err
+		}
+
+		bastion.PrivateKey = cipherData
+	}
+
+	if len(bastion.Passphrase) > 0 {
+		cipherData, err := repository.Encrypt(bastion.Passphrase, key)
+
+		if err != nil {
+			return err
+		}
+
+		bastion.Passphrase = cipherData
+	}
+
+	return nil
+}
+
+// DecryptBastionIntegrationData will decrypt the bastion integration data before
+// returning it from the DB
+func (repo *BastionIntegrationRepository) DecryptBastionIntegrationData(
+	bastion *ints.BastionIntegration,
+	key *[32]byte,
+) error {
+	if len(bastion.PrivateKey) > 0 {
+		plaintext, err := repository.Decrypt(bastion.PrivateKey, key)
+
+		if err != nil {
+			return err
+		}
+
+		bastion.PrivateKey = plaintext
+	}
+
+	if len(bastion.Passphrase) > 0 {
+		plaintext, err := repository.Decrypt(bastion.Passphrase, key)
+
+		if err != nil {
+			return err
+		}
+
+		bastion.Passphrase = plaintext
+	}
+
+	return nil
+}
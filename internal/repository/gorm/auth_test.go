@@ -459,3 +459,56 @@ func TestListAWSIntegrationsByProjectID(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestCreateBastionIntegration(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_bastion.db",
+	}
+
+	setupTestEnv(tester, t)
+	initUser(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	bastion := &ints.BastionIntegration{
+		ProjectID:  tester.initProjects[0].ID,
+		UserID:     tester.initUsers[0].ID,
+		Host:       "bastion.example.com",
+		Port:       2222,
+		User:       "porter",
+		PrivateKey: []byte("private-key"),
+		Passphrase: []byte("passphrase"),
+	}
+
+	expBastion := *bastion
+
+	bastion, err := tester.repo.BastionIntegration.CreateBastionIntegration(bastion)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the private key is encrypted in place before it is written
+	if string(bastion.PrivateKey) == "private-key" {
+		t.Errorf("bastion private key was written in plaintext")
+	}
+
+	bastion, err = tester.repo.BastionIntegration.ReadBastionIntegration(bastion.Model.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure id is 1
+	if bastion.Model.ID != 1 {
+		t.Errorf("incorrect bastion integration ID: expected %d, got %d\n", 1, bastion.Model.ID)
+	}
+
+	// reset fields for deep.Equal
+	bastion.Model = orm.Model{}
+
+	if diff := deep.Equal(expBastion, *bastion); diff != nil {
+		t.Errorf("incorrect bastion integration")
+		t.Error(diff)
+	}
+}
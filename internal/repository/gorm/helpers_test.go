@@ -45,6 +45,7 @@ func setupTestEnv(tester *tester, t *testing.T) {
 	err = db.AutoMigrate(
 		&models.Project{},
 		&models.Role{},
+		&models.RoleImpersonation{},
 		&models.User{},
 		&models.Session{},
 		&models.GitRepo{},
@@ -52,11 +53,15 @@ func setupTestEnv(tester *tester, t *testing.T) {
 		&models.Cluster{},
 		&models.ClusterCandidate{},
 		&models.ClusterResolver{},
+		&models.ManifestRelease{},
+		&models.ManifestFile{},
+		&models.Stack{},
 		&ints.KubeIntegration{},
 		&ints.OIDCIntegration{},
 		&ints.OAuthIntegration{},
 		&ints.GCPIntegration{},
 		&ints.AWSIntegration{},
+		&ints.BastionIntegration{},
 		&ints.TokenCache{},
 		&ints.RegTokenCache{},
 	)
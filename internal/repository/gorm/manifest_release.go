@@ -0,0 +1,114 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ManifestReleaseRepository uses gorm.DB for querying the database
+type ManifestReleaseRepository struct {
+	db *gorm.DB
+}
+
+// NewManifestReleaseRepository returns a ManifestReleaseRepository which uses
+// gorm.DB for querying the database
+func NewManifestReleaseRepository(db *gorm.DB) repository.ManifestReleaseRepository {
+	return &ManifestReleaseRepository{db}
+}
+
+// CreateManifestRelease creates a new manifest release with its files
+func (repo *ManifestReleaseRepository) CreateManifestRelease(
+	rel *models.ManifestRelease,
+) (*models.ManifestRelease, error) {
+	project := &models.Project{}
+
+	if err := repo.db.Where("id = ?", rel.ProjectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	assoc := repo.db.Model(&project).Association("ManifestReleases")
+
+	if assoc.Error != nil {
+		return nil, assoc.Error
+	}
+
+	if err := assoc.Append(rel); err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// ReadManifestRelease finds a manifest release with its files by the cluster,
+// namespace and name of the release
+func (repo *ManifestReleaseRepository) ReadManifestRelease(
+	clusterID uint,
+	namespace, name string,
+) (*models.ManifestRelease, error) {
+	rel := &models.ManifestRelease{}
+
+	if err := repo.db.Preload("Files").Where(
+		"cluster_id = ? AND namespace = ? AND name = ?",
+		clusterID,
+		namespace,
+		name,
+	).First(&rel).Error; err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// ListManifestReleasesByClusterID finds all manifest releases, without their
+// files, for a given cluster id
+func (repo *ManifestReleaseRepository) ListManifestReleasesByClusterID(
+	clusterID uint,
+) ([]*models.ManifestRelease, error) {
+	rels := []*models.ManifestRelease{}
+
+	if err := repo.db.Where("cluster_id = ?", clusterID).Find(&rels).Error; err != nil {
+		return nil, err
+	}
+
+	return rels, nil
+}
+
+// UpdateManifestRelease modifies an existing manifest release in the database,
+// and replaces its files with the files of the release
+func (repo *ManifestReleaseRepository) UpdateManifestRelease(
+	rel *models.ManifestRelease,
+) (*models.ManifestRelease, error) {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("manifest_release_id = ?", rel.ID).Delete(&models.ManifestFile{}).Error; err != nil {
+			return err
+		}
+
+		// the files are recreated
+		for i := range rel.Files {
+			rel.Files[i].Model = gorm.Model{}
+		}
+
+		return tx.Save(rel).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// DeleteManifestRelease deletes a manifest release and its files, so that a new
+// release can be deployed with the same name
+func (repo *ManifestReleaseRepository) DeleteManifestRelease(
+	rel *models.ManifestRelease,
+) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("manifest_release_id = ?", rel.ID).Delete(&models.ManifestFile{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("id = ?", rel.ID).Delete(&models.ManifestRelease{}).Error
+	})
+}
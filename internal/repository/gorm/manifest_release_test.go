@@ -0,0 +1,173 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestCreateManifestRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_manifest_release.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	rel := &models.ManifestRelease{
+		ProjectID: tester.initProjects[0].Model.ID,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Revision:  1,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "deployment.yaml", Data: "kind: Deployment"},
+			models.ManifestFile{Path: "service.yaml", Data: "kind: Service"},
+		},
+	}
+
+	_, err := tester.repo.ManifestRelease.CreateManifestRelease(rel)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rel, err = tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expRel := &models.ManifestReleaseExternal{
+		ID:        1,
+		ProjectID: tester.initProjects[0].Model.ID,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Revision:  1,
+		Files: map[string]string{
+			"deployment.yaml": "kind: Deployment",
+			"service.yaml":    "kind: Service",
+		},
+	}
+
+	if diff := deep.Equal(expRel, rel.Externalize()); diff != nil {
+		t.Errorf("incorrect manifest release")
+		t.Error(diff)
+	}
+
+	if _, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "other", "web"); err == nil {
+		t.Errorf("expected error reading release in other namespace")
+	}
+}
+
+func TestUpdateManifestRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_update_manifest_release.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	rel, err := tester.repo.ManifestRelease.CreateManifestRelease(&models.ManifestRelease{
+		ProjectID: tester.initProjects[0].Model.ID,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindKustomize,
+		Overlay:   "overlays/prod",
+		Revision:  1,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "base/kustomization.yaml", Data: "resources: []"},
+			models.ManifestFile{Path: "overlays/prod/kustomization.yaml", Data: "bases: [../../base]"},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the files of the release are replaced
+	rel.Revision = 2
+	rel.Files = []models.ManifestFile{
+		models.ManifestFile{Path: "base/kustomization.yaml", Data: "resources: [deployment.yaml]"},
+	}
+
+	if _, err := tester.repo.ManifestRelease.UpdateManifestRelease(rel); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rel, err = tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expFiles := map[string]string{
+		"base/kustomization.yaml": "resources: [deployment.yaml]",
+	}
+
+	if diff := deep.Equal(expFiles, rel.Externalize().Files); diff != nil || rel.Revision != 2 {
+		t.Errorf("incorrect updated manifest release: revision %d", rel.Revision)
+		t.Error(diff)
+	}
+
+	rels, err := tester.repo.ManifestRelease.ListManifestReleasesByClusterID(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rels) != 1 || rels[0].Name != "web" {
+		t.Errorf("incorrect manifest releases: %v", rels)
+	}
+}
+
+func TestDeleteManifestRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_manifest_release.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	rel, err := tester.repo.ManifestRelease.CreateManifestRelease(&models.ManifestRelease{
+		ProjectID: tester.initProjects[0].Model.ID,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Revision:  1,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "deployment.yaml", Data: "kind: Deployment"},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.ManifestRelease.DeleteManifestRelease(rel); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web"); err == nil {
+		t.Errorf("expected error reading deleted release")
+	}
+
+	rels, err := tester.repo.ManifestRelease.ListManifestReleasesByClusterID(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(rels) != 0 {
+		t.Errorf("expected no manifest releases, got %d", len(rels))
+	}
+}
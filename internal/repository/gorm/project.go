@@ -72,3 +72,65 @@ func (repo *ProjectRepository) DeleteProject(project *models.Project) (*models.P
 	}
 	return project, nil
 }
+
+// UpdateProjectRoleImpersonation sets the Kubernetes identity of a role in a
+// project, replacing the existing mapping of the role if there is one
+func (repo *ProjectRepository) UpdateProjectRoleImpersonation(
+	impersonation *models.RoleImpersonation,
+) (*models.RoleImpersonation, error) {
+	existing, err := repo.ReadProjectRoleImpersonation(impersonation.ProjectID, impersonation.RoleKind)
+
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err == nil {
+		impersonation.Model = existing.Model
+	}
+
+	if err := repo.db.Save(impersonation).Error; err != nil {
+		return nil, err
+	}
+
+	return impersonation, nil
+}
+
+// ReadProjectRoleImpersonation gets the Kubernetes identity of a role in a project
+func (repo *ProjectRepository) ReadProjectRoleImpersonation(
+	projectID uint,
+	roleKind string,
+) (*models.RoleImpersonation, error) {
+	impersonation := &models.RoleImpersonation{}
+
+	if err := repo.db.Where("project_id = ? AND role_kind = ?", projectID, roleKind).First(&impersonation).Error; err != nil {
+		return nil, err
+	}
+
+	return impersonation, nil
+}
+
+// ListProjectRoleImpersonations lists the Kubernetes identities of the roles in
+// a project
+func (repo *ProjectRepository) ListProjectRoleImpersonations(
+	projectID uint,
+) ([]*models.RoleImpersonation, error) {
+	impersonations := []*models.RoleImpersonation{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&impersonations).Error; err != nil {
+		return nil, err
+	}
+
+	return impersonations, nil
+}
+
+// DeleteProjectRoleImpersonation removes the Kubernetes identity of a role in a
+// project, so that the role acts as the cluster's default identity again
+func (repo *ProjectRepository) DeleteProjectRoleImpersonation(
+	impersonation *models.RoleImpersonation,
+) (*models.RoleImpersonation, error) {
+	if err := repo.db.Delete(&impersonation).Error; err != nil {
+		return nil, err
+	}
+
+	return impersonation, nil
+}
@@ -192,3 +192,75 @@ func TestDeleteProject(t *testing.T) {
 		t.Fatalf("read should have returned record not found: returned %v\n", err)
 	}
 }
+
+func TestProjectRoleImpersonation(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_proj_role_impersonation.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].Model.ID
+
+	imp, err := tester.repo.Project.UpdateProjectRoleImpersonation(&models.RoleImpersonation{
+		ProjectID: projID,
+		RoleKind:  models.RoleViewer,
+		User:      "porter-viewer",
+		Groups:    "viewers,auditors",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// updating the mapping of the same role replaces it
+	_, err = tester.repo.Project.UpdateProjectRoleImpersonation(&models.RoleImpersonation{
+		ProjectID: projID,
+		RoleKind:  models.RoleViewer,
+		User:      "porter-readonly",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	imps, err := tester.repo.Project.ListProjectRoleImpersonations(projID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(imps) != 1 {
+		t.Fatalf("wrong number of role impersonations: expected %d, got %d\n", 1, len(imps))
+	}
+
+	expImp := &models.RoleImpersonation{
+		ProjectID: projID,
+		RoleKind:  models.RoleViewer,
+		User:      "porter-readonly",
+	}
+
+	// reset fields for deep.Equal
+	imps[0].Model = orm.Model{}
+
+	if diff := deep.Equal(expImp, imps[0]); diff != nil {
+		t.Errorf("incorrect role impersonation")
+		t.Error(diff)
+	}
+
+	_, err = tester.repo.Project.DeleteProjectRoleImpersonation(&models.RoleImpersonation{
+		Model: orm.Model{ID: imp.ID},
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.Project.ReadProjectRoleImpersonation(projID, models.RoleViewer)
+
+	if err != gorm.ErrRecordNotFound {
+		t.Fatalf("read should have returned record not found: returned %v\n", err)
+	}
+}
@@ -0,0 +1,78 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// StackRepository uses gorm.DB for querying the database
+type StackRepository struct {
+	db *gorm.DB
+}
+
+// NewStackRepository returns a StackRepository which uses gorm.DB for querying
+// the database
+func NewStackRepository(db *gorm.DB) repository.StackRepository {
+	return &StackRepository{db}
+}
+
+// CreateStack creates a new stack
+func (repo *StackRepository) CreateStack(stack *models.Stack) (*models.Stack, error) {
+	project := &models.Project{}
+
+	if err := repo.db.Where("id = ?", stack.ProjectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	assoc := repo.db.Model(&project).Association("Stacks")
+
+	if assoc.Error != nil {
+		return nil, assoc.Error
+	}
+
+	if err := assoc.Append(stack); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+// ReadStack finds a stack by the cluster, namespace and name of the stack
+func (repo *StackRepository) ReadStack(
+	clusterID uint,
+	namespace, name string,
+) (*models.Stack, error) {
+	stack := &models.Stack{}
+
+	if err := repo.db.Where(
+		"cluster_id = ? AND namespace = ? AND name = ?",
+		clusterID,
+		namespace,
+		name,
+	).First(&stack).Error; err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+// ListStacksByClusterID finds all stacks for a given cluster id
+func (repo *StackRepository) ListStacksByClusterID(clusterID uint) ([]*models.Stack, error) {
+	stacks := []*models.Stack{}
+
+	if err := repo.db.Where("cluster_id = ?", clusterID).Find(&stacks).Error; err != nil {
+		return nil, err
+	}
+
+	return stacks, nil
+}
+
+// UpdateStack modifies an existing stack in the database
+func (repo *StackRepository) UpdateStack(stack *models.Stack) (*models.Stack, error) {
+	if err := repo.db.Save(stack).Error; err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
@@ -0,0 +1,78 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestCreateStack(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_stack.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	stack := &models.Stack{
+		ProjectID:       tester.initProjects[0].Model.ID,
+		ClusterID:       1,
+		Name:            "shop",
+		Namespace:       "default",
+		TemplateName:    "web-stack",
+		TemplateVersion: "0.1.0",
+		Revision:        1,
+		Spec:            "name: web-stack",
+	}
+
+	_, err := tester.repo.Stack.CreateStack(stack)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	stack, err = tester.repo.Stack.ReadStack(1, "default", "shop")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expStack := &models.StackExternal{
+		ID:              1,
+		ProjectID:       tester.initProjects[0].Model.ID,
+		ClusterID:       1,
+		Name:            "shop",
+		Namespace:       "default",
+		TemplateName:    "web-stack",
+		TemplateVersion: "0.1.0",
+		Revision:        1,
+	}
+
+	if diff := deep.Equal(expStack, stack.Externalize()); diff != nil {
+		t.Errorf("incorrect stack")
+		t.Error(diff)
+	}
+
+	if stack.Spec != "name: web-stack" {
+		t.Errorf("incorrect stack spec: %s", stack.Spec)
+	}
+
+	stack.Revision = 2
+	stack.TemplateVersion = "0.2.0"
+
+	if _, err := tester.repo.Stack.UpdateStack(stack); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	stacks, err := tester.repo.Stack.ListStacksByClusterID(1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(stacks) != 1 || stacks[0].Revision != 2 || stacks[0].TemplateVersion != "0.2.0" {
+		t.Errorf("incorrect updated stacks: %v", stacks)
+	}
+}
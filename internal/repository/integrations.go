@@ -43,3 +43,11 @@ type GCPIntegrationRepository interface {
 	ReadGCPIntegration(id uint) (*ints.GCPIntegration, error)
 	ListGCPIntegrationsByProjectID(projectID uint) ([]*ints.GCPIntegration, error)
 }
+
+// BastionIntegrationRepository represents the set of queries on the SSH bastion
+// integration
+type BastionIntegrationRepository interface {
+	CreateBastionIntegration(am *ints.BastionIntegration) (*ints.BastionIntegration, error)
+	ReadBastionIntegration(id uint) (*ints.BastionIntegration, error)
+	ListBastionIntegrationsByProjectID(projectID uint) ([]*ints.BastionIntegration, error)
+}
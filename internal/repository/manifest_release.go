@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/porter-dev/porter/internal/models"
+
+// ManifestReleaseRepository represents the set of queries on the
+// ManifestRelease model
+type ManifestReleaseRepository interface {
+	CreateManifestRelease(rel *models.ManifestRelease) (*models.ManifestRelease, error)
+	ReadManifestRelease(clusterID uint, namespace, name string) (*models.ManifestRelease, error)
+	ListManifestReleasesByClusterID(clusterID uint) ([]*models.ManifestRelease, error)
+	UpdateManifestRelease(rel *models.ManifestRelease) (*models.ManifestRelease, error)
+	DeleteManifestRelease(rel *models.ManifestRelease) error
+}
@@ -14,4 +14,8 @@ type ProjectRepository interface {
 	ReadProject(id uint) (*models.Project, error)
 	ListProjectsByUserID(userID uint) ([]*models.Project, error)
 	DeleteProject(project *models.Project) (*models.Project, error)
+	UpdateProjectRoleImpersonation(impersonation *models.RoleImpersonation) (*models.RoleImpersonation, error)
+	ReadProjectRoleImpersonation(projectID uint, roleKind string) (*models.RoleImpersonation, error)
+	ListProjectRoleImpersonations(projectID uint) ([]*models.RoleImpersonation, error)
+	DeleteProjectRoleImpersonation(impersonation *models.RoleImpersonation) (*models.RoleImpersonation, error)
 }
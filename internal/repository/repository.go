@@ -2,15 +2,18 @@ package repository
 
 // Repository collects the repositories for each model
 type Repository struct {
-	User             UserRepository
-	Project          ProjectRepository
-	Session          SessionRepository
-	GitRepo          GitRepoRepository
-	Cluster          ClusterRepository
-	Registry         RegistryRepository
-	KubeIntegration  KubeIntegrationRepository
-	OIDCIntegration  OIDCIntegrationRepository
-	OAuthIntegration OAuthIntegrationRepository
-	GCPIntegration   GCPIntegrationRepository
-	AWSIntegration   AWSIntegrationRepository
+	User               UserRepository
+	Project            ProjectRepository
+	Session            SessionRepository
+	GitRepo            GitRepoRepository
+	Cluster            ClusterRepository
+	Registry           RegistryRepository
+	KubeIntegration    KubeIntegrationRepository
+	OIDCIntegration    OIDCIntegrationRepository
+	OAuthIntegration   OAuthIntegrationRepository
+	GCPIntegration     GCPIntegrationRepository
+	AWSIntegration     AWSIntegrationRepository
+	BastionIntegration BastionIntegrationRepository
+	ManifestRelease    ManifestReleaseRepository
+	Stack              StackRepository
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/porter-dev/porter/internal/models"
+
+// StackRepository represents the set of queries on the Stack model
+type StackRepository interface {
+	CreateStack(stack *models.Stack) (*models.Stack, error)
+	ReadStack(clusterID uint, namespace, name string) (*models.Stack, error)
+	ListStacksByClusterID(clusterID uint) ([]*models.Stack, error)
+	UpdateStack(stack *models.Stack) (*models.Stack, error)
+}
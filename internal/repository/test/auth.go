@@ -328,3 +328,67 @@ func (repo *GCPIntegrationRepository) ListGCPIntegrationsByProjectID(
 
 	return res, nil
 }
+
+// BastionIntegrationRepository implements repository.BastionIntegrationRepository
+type BastionIntegrationRepository struct {
+	canQuery            bool
+	bastionIntegrations []*ints.BastionIntegration
+}
+
+// NewBastionIntegrationRepository will return errors if canQuery is false
+func NewBastionIntegrationRepository(canQuery bool) repository.BastionIntegrationRepository {
+	return &BastionIntegrationRepository{
+		canQuery,
+		[]*ints.BastionIntegration{},
+	}
+}
+
+// CreateBastionIntegration creates a new bastion integration
+func (repo *BastionIntegrationRepository) CreateBastionIntegration(
+	am *ints.BastionIntegration,
+) (*ints.BastionIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.bastionIntegrations = append(repo.bastionIntegrations, am)
+	am.ID = uint(len(repo.bastionIntegrations))
+
+	return am, nil
+}
+
+// ReadBastionIntegration finds a bastion integration by id
+func (repo *BastionIntegrationRepository) ReadBastionIntegration(
+	id uint,
+) (*ints.BastionIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(id-1) >= len(repo.bastionIntegrations) || repo.bastionIntegrations[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(id - 1)
+	return repo.bastionIntegrations[index], nil
+}
+
+// ListBastionIntegrationsByProjectID finds all bastion integrations
+// for a given project id
+func (repo *BastionIntegrationRepository) ListBastionIntegrationsByProjectID(
+	projectID uint,
+) ([]*ints.BastionIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*ints.BastionIntegration, 0)
+
+	for _, bastion := range repo.bastionIntegrations {
+		if bastion.ProjectID == projectID {
+			res = append(res, bastion)
+		}
+	}
+
+	return res, nil
+}
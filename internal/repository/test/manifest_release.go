@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ManifestReleaseRepository implements repository.ManifestReleaseRepository
+type ManifestReleaseRepository struct {
+	canQuery bool
+	rels     []*models.ManifestRelease
+}
+
+// NewManifestReleaseRepository will return errors if canQuery is false
+func NewManifestReleaseRepository(canQuery bool) repository.ManifestReleaseRepository {
+	return &ManifestReleaseRepository{
+		canQuery,
+		[]*models.ManifestRelease{},
+	}
+}
+
+// CreateManifestRelease creates a new manifest release and appends it to the
+// in-memory list
+func (repo *ManifestReleaseRepository) CreateManifestRelease(
+	rel *models.ManifestRelease,
+) (*models.ManifestRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.rels = append(repo.rels, rel)
+	rel.ID = uint(len(repo.rels))
+
+	return rel, nil
+}
+
+// ReadManifestRelease finds a manifest release by the cluster, namespace and
+// name of the release
+func (repo *ManifestReleaseRepository) ReadManifestRelease(
+	clusterID uint,
+	namespace, name string,
+) (*models.ManifestRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, rel := range repo.rels {
+		if rel != nil && rel.ClusterID == clusterID && rel.Namespace == namespace && rel.Name == name {
+			return rel, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListManifestReleasesByClusterID finds all manifest releases for a given
+// cluster id
+func (repo *ManifestReleaseRepository) ListManifestReleasesByClusterID(
+	clusterID uint,
+) ([]*models.ManifestRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.ManifestRelease, 0)
+
+	for _, rel := range repo.rels {
+		if rel != nil && rel.ClusterID == clusterID {
+			res = append(res, rel)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateManifestRelease modifies an existing manifest release in the in-memory
+// list
+func (repo *ManifestReleaseRepository) UpdateManifestRelease(
+	rel *models.ManifestRelease,
+) (*models.ManifestRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(rel.ID-1) >= len(repo.rels) || repo.rels[rel.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.rels[int(rel.ID-1)] = rel
+
+	return rel, nil
+}
+
+// DeleteManifestRelease removes a manifest release from the in-memory list
+func (repo *ManifestReleaseRepository) DeleteManifestRelease(
+	rel *models.ManifestRelease,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(rel.ID-1) >= len(repo.rels) || repo.rels[rel.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	repo.rels[int(rel.ID-1)] = nil
+
+	return nil
+}
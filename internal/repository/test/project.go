@@ -12,13 +12,14 @@ import (
 // and only stores a small set of projects in-memory that are indexed by their
 // array index + 1
 type ProjectRepository struct {
-	canQuery bool
-	projects []*models.Project
+	canQuery       bool
+	projects       []*models.Project
+	impersonations []*models.RoleImpersonation
 }
 
 // NewProjectRepository will return errors if canQuery is false
 func NewProjectRepository(canQuery bool) repository.ProjectRepository {
-	return &ProjectRepository{canQuery, []*models.Project{}}
+	return &ProjectRepository{canQuery, []*models.Project{}, []*models.RoleImpersonation{}}
 }
 
 // CreateProject appends a new project to the in-memory projects array
@@ -100,3 +101,83 @@ func (repo *ProjectRepository) DeleteProject(project *models.Project) (*models.P
 
 	return project, nil
 }
+
+// UpdateProjectRoleImpersonation sets the Kubernetes identity of a role in a
+// project, replacing the existing mapping of the role if there is one
+func (repo *ProjectRepository) UpdateProjectRoleImpersonation(
+	impersonation *models.RoleImpersonation,
+) (*models.RoleImpersonation, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	existing, err := repo.ReadProjectRoleImpersonation(impersonation.ProjectID, impersonation.RoleKind)
+
+	if err == nil {
+		impersonation.ID = existing.ID
+		repo.impersonations[existing.ID-1] = impersonation
+
+		return impersonation, nil
+	}
+
+	repo.impersonations = append(repo.impersonations, impersonation)
+	impersonation.ID = uint(len(repo.impersonations))
+
+	return impersonation, nil
+}
+
+// ReadProjectRoleImpersonation gets the Kubernetes identity of a role in a project
+func (repo *ProjectRepository) ReadProjectRoleImpersonation(
+	projectID uint,
+	roleKind string,
+) (*models.RoleImpersonation, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, impersonation := range repo.impersonations {
+		if impersonation != nil && impersonation.ProjectID == projectID && impersonation.RoleKind == roleKind {
+			return impersonation, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListProjectRoleImpersonations lists the Kubernetes identities of the roles in
+// a project
+func (repo *ProjectRepository) ListProjectRoleImpersonations(
+	projectID uint,
+) ([]*models.RoleImpersonation, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.RoleImpersonation, 0)
+
+	for _, impersonation := range repo.impersonations {
+		if impersonation != nil && impersonation.ProjectID == projectID {
+			res = append(res, impersonation)
+		}
+	}
+
+	return res, nil
+}
+
+// DeleteProjectRoleImpersonation removes the Kubernetes identity of a role in a
+// project
+func (repo *ProjectRepository) DeleteProjectRoleImpersonation(
+	impersonation *models.RoleImpersonation,
+) (*models.RoleImpersonation, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(impersonation.ID-1) >= len(repo.impersonations) || repo.impersonations[impersonation.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.impersonations[impersonation.ID-1] = nil
+
+	return impersonation, nil
+}
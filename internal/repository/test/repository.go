@@ -8,16 +8,19 @@ import (
 // and accepts a parameter that can trigger read/write errors
 func NewRepository(canQuery bool) *repository.Repository {
 	return &repository.Repository{
-		User:             NewUserRepository(canQuery),
-		Session:          NewSessionRepository(canQuery),
-		Project:          NewProjectRepository(canQuery),
-		Cluster:          NewClusterRepository(canQuery),
-		Registry:         NewRegistryRepository(canQuery),
-		GitRepo:          NewGitRepoRepository(canQuery),
-		KubeIntegration:  NewKubeIntegrationRepository(canQuery),
-		OIDCIntegration:  NewOIDCIntegrationRepository(canQuery),
-		OAuthIntegration: NewOAuthIntegrationRepository(canQuery),
-		GCPIntegration:   NewGCPIntegrationRepository(canQuery),
-		AWSIntegration:   NewAWSIntegrationRepository(canQuery),
+		User:               NewUserRepository(canQuery),
+		Session:            NewSessionRepository(canQuery),
+		Project:            NewProjectRepository(canQuery),
+		Cluster:            NewClusterRepository(canQuery),
+		Registry:           NewRegistryRepository(canQuery),
+		GitRepo:            NewGitRepoRepository(canQuery),
+		KubeIntegration:    NewKubeIntegrationRepository(canQuery),
+		OIDCIntegration:    NewOIDCIntegrationRepository(canQuery),
+		OAuthIntegration:   NewOAuthIntegrationRepository(canQuery),
+		GCPIntegration:     NewGCPIntegrationRepository(canQuery),
+		AWSIntegration:     NewAWSIntegrationRepository(canQuery),
+		BastionIntegration: NewBastionIntegrationRepository(canQuery),
+		ManifestRelease:    NewManifestReleaseRepository(canQuery),
+		Stack:              NewStackRepository(canQuery),
 	}
 }
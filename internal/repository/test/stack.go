@@ -0,0 +1,85 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// StackRepository implements repository.StackRepository
+type StackRepository struct {
+	canQuery bool
+	stacks   []*models.Stack
+}
+
+// NewStackRepository will return errors if canQuery is false
+func NewStackRepository(canQuery bool) repository.StackRepository {
+	return &StackRepository{
+		canQuery,
+		[]*models.Stack{},
+	}
+}
+
+// CreateStack creates a new stack and appends it to the in-memory list
+func (repo *StackRepository) CreateStack(stack *models.Stack) (*models.Stack, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.stacks = append(repo.stacks, stack)
+	stack.ID = uint(len(repo.stacks))
+
+	return stack, nil
+}
+
+// ReadStack finds a stack by the cluster, namespace and name of the stack
+func (repo *StackRepository) ReadStack(
+	clusterID uint,
+	namespace, name string,
+) (*models.Stack, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, stack := range repo.stacks {
+		if stack != nil && stack.ClusterID == clusterID && stack.Namespace == namespace && stack.Name == name {
+			return stack, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListStacksByClusterID finds all stacks for a given cluster id
+func (repo *StackRepository) ListStacksByClusterID(clusterID uint) ([]*models.Stack, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.Stack, 0)
+
+	for _, stack := range repo.stacks {
+		if stack != nil && stack.ClusterID == clusterID {
+			res = append(res, stack)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateStack modifies an existing stack in the in-memory list
+func (repo *StackRepository) UpdateStack(stack *models.Stack) (*models.Stack, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(stack.ID-1) >= len(repo.stacks) || repo.stacks[stack.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.stacks[int(stack.ID-1)] = stack
+
+	return stack, nil
+}
@@ -0,0 +1,117 @@
+package dynamic
+
+import (
+	"context"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"k8s.io/client-go/dynamic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	namespacesGVR     = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	storageClassesGVR = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	ingressClassesGVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingressclasses"}
+)
+
+// the annotations that mark the default storage class and ingress class
+const (
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+)
+
+// MetadataTemplateReader reads metadata of a cluster, which forms offer as the
+// options of select contents
+type MetadataTemplateReader struct {
+	Queries []*templater.TemplateReaderQuery
+
+	Client dynamic.Interface
+}
+
+// ValuesFromTarget returns the sorted names of the namespaces, storage classes and
+// ingress classes of the cluster under "namespaces", "storageClasses" and
+// "ingressClasses", and the names of the default classes under "defaultStorageClass"
+// and "defaultIngressClass". Storage and ingress classes that cannot be listed, for
+// example because the cluster does not serve ingress classes, are left empty.
+func (r *MetadataTemplateReader) ValuesFromTarget() (map[string]interface{}, error) {
+	namespaces, _, err := r.listNames(namespacesGVR, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	storageClasses, defaultStorageClass, _ := r.listNames(storageClassesGVR, defaultStorageClassAnnotation)
+	ingressClasses, defaultIngressClass, _ := r.listNames(ingressClassesGVR, defaultIngressClassAnnotation)
+
+	return map[string]interface{}{
+		"namespaces":          namespaces,
+		"storageClasses":      storageClasses,
+		"ingressClasses":      ingressClasses,
+		"defaultStorageClass": defaultStorageClass,
+		"defaultIngressClass": defaultIngressClass,
+	}, nil
+}
+
+// listNames lists the sorted names of the objects of a resource, and the name of the
+// object that has the default annotation set to "true"
+func (r *MetadataTemplateReader) listNames(
+	gvr schema.GroupVersionResource,
+	defaultAnnotation string,
+) ([]interface{}, string, error) {
+	names := make([]interface{}, 0)
+
+	list, err := r.Client.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return names, "", err
+	}
+
+	sorted := make([]string, 0, len(list.Items))
+	defaultName := ""
+
+	for _, item := range list.Items {
+		sorted = append(sorted, item.GetName())
+
+		if defaultAnnotation != "" && item.GetAnnotations()[defaultAnnotation] == "true" {
+			defaultName = item.GetName()
+		}
+	}
+
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		names = append(names, name)
+	}
+
+	return names, defaultName, nil
+}
+
+// RegisterQuery adds a query to the list of queries to execute
+func (r *MetadataTemplateReader) RegisterQuery(query *templater.TemplateReaderQuery) error {
+	r.Queries = append(r.Queries, query)
+
+	return nil
+}
+
+// Read executes the queries against the cluster metadata
+func (r *MetadataTemplateReader) Read() (map[string]interface{}, error) {
+	values, err := r.ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.QueryValues(values, r.Queries)
+}
+
+// ReadStream sends the queried cluster metadata once
+func (r *MetadataTemplateReader) ReadStream(
+	on templater.OnDataStream,
+	stopCh <-chan struct{},
+) error {
+	return utils.ReadOnce(r, on)
+}
@@ -2,17 +2,22 @@ package dynamic
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
 	"github.com/porter-dev/porter/internal/templater/utils"
 
 	"github.com/porter-dev/porter/internal/templater"
 	"k8s.io/client-go/dynamic"
-	di "k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -28,6 +33,11 @@ type Object struct {
 
 	// Optional, if attempting to get an object by name
 	Name string
+
+	// Optional, selectors that filter the objects that are listed when name
+	// is not set
+	LabelSelector string
+	FieldSelector string
 }
 
 // DynamicTemplateReader reads any resource registered with the k8s apiserver
@@ -40,16 +50,26 @@ type DynamicTemplateReader struct {
 
 	Client dynamic.Interface
 
+	// Informers is used by ReadStream to share informers across streams. If it
+	// is nil, each stream starts its own informer.
+	Informers informer.Subscriber
+
 	// The resource that's being queried
 	gvr      schema.GroupVersionResource
 	resource dynamic.ResourceInterface
 }
 
-// NewDynamicTemplateReader creates a new DynamicTemplateReader
-func NewDynamicTemplateReader(client dynamic.Interface, obj *Object) templater.TemplateReader {
+// NewDynamicTemplateReader creates a new DynamicTemplateReader. The informers may
+// be nil, in which case ReadStream starts its own informer.
+func NewDynamicTemplateReader(
+	client dynamic.Interface,
+	informers informer.Subscriber,
+	obj *Object,
+) templater.TemplateReader {
 	r := &DynamicTemplateReader{
-		Object: obj,
-		Client: client,
+		Object:    obj,
+		Client:    client,
+		Informers: informers,
 	}
 
 	objRes := schema.GroupVersionResource{
@@ -93,74 +113,167 @@ func (r *DynamicTemplateReader) Read() (map[string]interface{}, error) {
 	return utils.QueryValues(values, r.Queries)
 }
 
-// ReadStream listens for CRUD 	operations on resources and returns resulting
-// queried data
+// ReadStream listens for CRUD operations on resources and returns resulting
+// queried data. The reader subscribes to the shared informer for the resource,
+// and unsubscribes when stopCh is closed.
+//
+// If the object has a name, the queries are executed against that object. Otherwise
+// the queries are executed against the list of selected objects, under "items",
+// each time one of them changes.
 func (r *DynamicTemplateReader) ReadStream(
 	on templater.OnDataStream,
 	stopCh <-chan struct{},
 ) error {
-	factory := di.NewDynamicSharedInformerFactory(
-		r.Client,
-		10*time.Second,
-	)
+	labelSel, err := labels.Parse(r.Object.LabelSelector)
 
-	informer := factory.ForResource(r.gvr).Informer()
+	if err != nil {
+		return err
+	}
 
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			pkt := make(map[string]interface{})
-			pkt["kind"] = "create"
+	fieldSel, err := fields.ParseSelector(r.Object.FieldSelector)
 
-			u := obj.(*unstructured.Unstructured)
+	if err != nil {
+		return err
+	}
 
-			data, err := utils.QueryValues(u.Object, r.Queries)
+	informers := r.Informers
 
-			if err != nil {
-				return
-			}
+	// without a shared registry, the informer is stopped as soon as the stream
+	// is closed
+	if informers == nil {
+		informers = informer.NewRegistry(0).ForCluster(0, r.Client)
+	}
 
-			pkt["data"] = data
-			on(pkt)
-		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			pkt := make(map[string]interface{})
-			pkt["kind"] = "update"
+	// the selected objects, keyed by namespace and name, which are only kept
+	// when listing
+	var mu sync.Mutex
+	items := make(map[string]*unstructured.Unstructured)
+
+	sendPkt := func(kind string, obj interface{}) {
+		u, ok := obj.(*unstructured.Unstructured)
 
-			u := newObj.(*unstructured.Unstructured)
+		// the informer watches every object of the resource in the namespace,
+		// so objects other than the ones being read are skipped
+		if !ok || !r.selects(u, labelSel, fieldSel) {
+			return
+		}
 
-			data, err := utils.QueryValues(u.Object, r.Queries)
+		values := u.Object
 
-			if err != nil {
-				return
+		if r.Object.Name == "" {
+			mu.Lock()
+
+			if kind == "delete" {
+				delete(items, u.GetNamespace()+"/"+u.GetName())
+			} else {
+				items[u.GetNamespace()+"/"+u.GetName()] = u
 			}
 
-			pkt["data"] = data
-			on(pkt)
-		},
-		DeleteFunc: func(obj interface{}) {
-			pkt := make(map[string]interface{})
-			pkt["kind"] = "delete"
+			values = listValues(items)
 
-			u := obj.(*unstructured.Unstructured)
+			mu.Unlock()
+		}
 
-			data, err := utils.QueryValues(u.Object, r.Queries)
+		data, err := utils.QueryValues(values, r.Queries)
 
-			if err != nil {
-				return
-			}
+		if err != nil {
+			return
+		}
 
-			pkt["data"] = data
-			on(pkt)
+		pkt := make(map[string]interface{})
+		pkt["kind"] = kind
+		pkt["data"] = data
+		on(pkt)
+	}
+
+	unsubscribe, err := informers.Subscribe(
+		r.gvr,
+		r.Object.Namespace,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				sendPkt("create", obj)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				sendPkt("update", newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+
+				sendPkt("delete", obj)
+			},
 		},
-	})
+	)
 
-	go informer.Run(stopCh)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-stopCh
+		unsubscribe()
+	}()
 
 	return nil
 }
 
+// selects returns whether the object is read, either because it has the name of
+// the object or because it matches the selectors
+func (r *DynamicTemplateReader) selects(
+	u *unstructured.Unstructured,
+	labelSel labels.Selector,
+	fieldSel fields.Selector,
+) bool {
+	if r.Object.Name != "" {
+		return u.GetName() == r.Object.Name
+	}
+
+	if !labelSel.Matches(labels.Set(u.GetLabels())) {
+		return false
+	}
+
+	// only the fields that the selector requires are looked up
+	fieldSet := fields.Set{}
+
+	for _, req := range fieldSel.Requirements() {
+		val, found, err := unstructured.NestedFieldNoCopy(u.Object, strings.Split(req.Field, ".")...)
+
+		if err == nil && found {
+			fieldSet[req.Field] = fmt.Sprintf("%v", val)
+		}
+	}
+
+	return fieldSel.Matches(fieldSet)
+}
+
+// listValues returns the values of a list of the objects, ordered by namespace
+// and name
+func listValues(items map[string]*unstructured.Unstructured) map[string]interface{} {
+	keys := make([]string, 0, len(items))
+
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	list := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		list = append(list, items[key].Object)
+	}
+
+	return map[string]interface{}{
+		"items": list,
+	}
+}
+
 func (r *DynamicTemplateReader) valuesFromList() (map[string]interface{}, error) {
-	list, err := r.resource.List(context.TODO(), metav1.ListOptions{})
+	list, err := r.resource.List(context.TODO(), metav1.ListOptions{
+		LabelSelector: r.Object.LabelSelector,
+		FieldSelector: r.Object.FieldSelector,
+	})
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,105 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"k8s.io/client-go/dynamic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// MaskedValue replaces the values of the keys of a secret
+const MaskedValue = "********"
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// SecretTemplateReader reads the keys of a secret, with the values of the keys
+// masked, so that forms can refer to the keys without reading the secret data
+type SecretTemplateReader struct {
+	Queries []*templater.TemplateReaderQuery
+
+	Client    dynamic.Interface
+	Namespace string
+	Name      string
+}
+
+// ValuesFromTarget returns the name and namespace of the secret under "metadata",
+// the sorted keys of the secret under "keys", and the masked data under "data"
+func (r *SecretTemplateReader) ValuesFromTarget() (map[string]interface{}, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("must set name to read secret")
+	}
+
+	obj, err := r.Client.Resource(secretsGVR).Namespace(r.Namespace).Get(
+		context.TODO(),
+		r.Name,
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := unstructured.NestedMap(obj.Object, "data")
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	masked := make(map[string]interface{})
+
+	for key := range data {
+		keys = append(keys, key)
+		masked[key] = MaskedValue
+	}
+
+	sort.Strings(keys)
+
+	keysArr := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		keysArr = append(keysArr, key)
+	}
+
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      obj.GetName(),
+			"namespace": obj.GetNamespace(),
+		},
+		"keys": keysArr,
+		"data": masked,
+	}, nil
+}
+
+// RegisterQuery adds a query to the list of queries to execute
+func (r *SecretTemplateReader) RegisterQuery(query *templater.TemplateReaderQuery) error {
+	r.Queries = append(r.Queries, query)
+
+	return nil
+}
+
+// Read executes the queries against the masked secret
+func (r *SecretTemplateReader) Read() (map[string]interface{}, error) {
+	values, err := r.ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.QueryValues(values, r.Queries)
+}
+
+// ReadStream sends the queried secret values once
+func (r *SecretTemplateReader) ReadStream(
+	on templater.OnDataStream,
+	stopCh <-chan struct{},
+) error {
+	return utils.ReadOnce(r, on)
+}
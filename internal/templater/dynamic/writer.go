@@ -2,12 +2,15 @@ package dynamic
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/porter-dev/porter/internal/templater/utils"
 
 	"github.com/porter-dev/porter/internal/templater"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/client-go/dynamic"
 
@@ -81,7 +84,13 @@ func (w *DynamicTemplateWriter) Create(vals map[string]interface{}) (map[string]
 	return create.Object, nil
 }
 
+// Update patches the object identified by name with the values, merged with the
+// base values
 func (w *DynamicTemplateWriter) Update(vals map[string]interface{}) (map[string]interface{}, error) {
+	if w.Object.Name == "" {
+		return nil, fmt.Errorf("object name must be set")
+	}
+
 	w.vals = vals
 	err := w.Transform()
 
@@ -89,9 +98,19 @@ func (w *DynamicTemplateWriter) Update(vals map[string]interface{}) (map[string]
 		return nil, err
 	}
 
-	update, err := w.resource.Update(context.TODO(), &unstructured.Unstructured{
-		Object: w.vals,
-	}, metav1.UpdateOptions{})
+	data, err := json.Marshal(w.vals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := w.resource.Patch(
+		context.TODO(),
+		w.Object.Name,
+		types.MergePatchType,
+		data,
+		metav1.PatchOptions{},
+	)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,329 @@
+// Package expr implements the expressions of forms, which are used to show sections
+// conditionally, for example:
+//
+//	show_if: ingress.enabled == true
+//
+// and to compute the values of variables from other values, for example:
+//
+//	compute: image.repository + ":" + image.tag
+//
+// Identifiers are dot-separated paths into values, which are resolved when the
+// expression is evaluated. Expressions support the literals true, false, null,
+// numbers and quoted strings, the operators || && ! == != < <= > >= + - * / %, and
+// parentheses. The + operator concatenates if either operand is a string.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Resolver returns the value at a dot-separated path, or nil if there is no value
+type Resolver func(path string) interface{}
+
+// Expression is a parsed expression
+type Expression struct {
+	src  string
+	root node
+}
+
+// Parse parses an expression
+func Parse(s string) (*Expression, error) {
+	tokens, err := lex(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok.text, tok.pos)
+	}
+
+	return &Expression{s, root}, nil
+}
+
+// Eval parses and evaluates an expression
+func Eval(s string, resolve Resolver) (interface{}, error) {
+	e, err := Parse(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Eval(resolve)
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression, resolving its identifiers with resolve
+func (e *Expression) Eval(resolve Resolver) (interface{}, error) {
+	return e.root.eval(resolve)
+}
+
+// Variables returns the paths of the identifiers in the expression, in the order
+// in which they appear
+func (e *Expression) Variables() []string {
+	vars := make([]string, 0)
+	e.root.variables(&vars)
+
+	return vars
+}
+
+// Truthy returns whether a value counts as true in a condition. Null, false, zero,
+// the empty string and empty lists and maps are false.
+func Truthy(val interface{}) bool {
+	if val == nil {
+		return false
+	}
+
+	if b, ok := val.(bool); ok {
+		return b
+	}
+
+	if num, ok := toNumber(val); ok {
+		return num != 0
+	}
+
+	v := reflect.ValueOf(val)
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() > 0
+	}
+
+	return true
+}
+
+type node interface {
+	eval(resolve Resolver) (interface{}, error)
+	variables(vars *[]string)
+}
+
+type literalNode struct {
+	val interface{}
+}
+
+func (n *literalNode) eval(resolve Resolver) (interface{}, error) {
+	return n.val, nil
+}
+
+func (n *literalNode) variables(vars *[]string) {}
+
+type identNode struct {
+	path string
+}
+
+func (n *identNode) eval(resolve Resolver) (interface{}, error) {
+	return resolve(n.path), nil
+}
+
+func (n *identNode) variables(vars *[]string) {
+	*vars = append(*vars, n.path)
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(resolve Resolver) (interface{}, error) {
+	val, err := n.operand.eval(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !Truthy(val), nil
+	}
+
+	num, ok := toNumber(val)
+
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %v", val)
+	}
+
+	return -num, nil
+}
+
+func (n *unaryNode) variables(vars *[]string) {
+	n.operand.variables(vars)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) variables(vars *[]string) {
+	n.left.variables(vars)
+	n.right.variables(vars)
+}
+
+func (n *binaryNode) eval(resolve Resolver) (interface{}, error) {
+	left, err := n.left.eval(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the logical operators short-circuit
+	switch n.op {
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+	}
+
+	right, err := n.right.eval(resolve)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return Truthy(right), nil
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "+":
+		_, leftIsStr := left.(string)
+		_, rightIsStr := right.(string)
+
+		if leftIsStr || rightIsStr {
+			return toString(left) + toString(right), nil
+		}
+	}
+
+	return arithmetic(n.op, left, right)
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s requires numbers, got %v and %v", op, left, right)
+	}
+
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+
+	if r == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+
+	if op == "/" {
+		return l / r, nil
+	}
+
+	return math.Mod(l, r), nil
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var cmp int
+
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	ls, lsok := left.(string)
+	rs, rsok := right.(string)
+
+	switch {
+	case lok && rok:
+		cmp = compareOrdered(l < r, l > r)
+	case lsok && rsok:
+		cmp = compareOrdered(ls < rs, ls > rs)
+	default:
+		return nil, fmt.Errorf("cannot compare %v and %v", left, right)
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+
+	return cmp >= 0, nil
+}
+
+func compareOrdered(less, greater bool) int {
+	if less {
+		return -1
+	} else if greater {
+		return 1
+	}
+
+	return 0
+}
+
+func equal(left, right interface{}) bool {
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+
+	if lok && rok {
+		return l == r
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+// toNumber converts the numeric types that values are decoded to into a float64
+func toNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+
+	return 0, false
+}
+
+func toString(val interface{}) string {
+	if val == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", val)
+}
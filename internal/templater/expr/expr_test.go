@@ -0,0 +1,129 @@
+package expr_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/templater/expr"
+)
+
+var values = map[string]interface{}{
+	"ingress": map[string]interface{}{
+		"enabled": true,
+	},
+	"image": map[string]interface{}{
+		"repository": "nginx",
+		"tag":        "1.19",
+	},
+	"replicaCount": float64(3),
+	"name":         "web",
+}
+
+func resolve(path string) interface{} {
+	var val interface{} = values
+
+	for _, key := range strings.Split(path, ".") {
+		m, ok := val.(map[string]interface{})
+
+		if !ok {
+			return nil
+		}
+
+		val = m[key]
+	}
+
+	return val
+}
+
+type evalTest struct {
+	expr   string
+	expVal interface{}
+}
+
+var evalTests = []evalTest{
+	{"ingress.enabled == true", true},
+	{"ingress.enabled", true},
+	{"!ingress.enabled", false},
+	{"ingress.missing", nil},
+	{"ingress.missing == null", true},
+	{`image.repository + ":" + image.tag`, "nginx:1.19"},
+	{"replicaCount * 2 + 1", float64(7)},
+	{"replicaCount + 1", float64(4)},
+	{"-replicaCount", float64(-3)},
+	{"(1 + 2) * 3", float64(9)},
+	{"7 % 4", float64(3)},
+	{"replicaCount >= 3 && name == 'web'", true},
+	{"replicaCount > 3 || name != \"web\"", false},
+	{"ingress.missing || replicaCount", true},
+	{`"a" < "b"`, true},
+	{`'it\'s'`, "it's"},
+	{`name + replicaCount`, "web3"},
+}
+
+func TestEval(t *testing.T) {
+	for _, c := range evalTests {
+		val, err := expr.Eval(c.expr, resolve)
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.expr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(val, c.expVal) {
+			t.Errorf("%s: expected %v, got %v", c.expr, c.expVal, val)
+		}
+	}
+}
+
+var errorTests = []string{
+	"",
+	"replicaCount ==",
+	"(1 + 2",
+	"1 + 2)",
+	"name @ 2",
+	`"unterminated`,
+	"name - 1",
+	"1 / 0",
+	"name < 1",
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, s := range errorTests {
+		if _, err := expr.Eval(s, resolve); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := expr.Parse(`image.repository + ":" + image.tag == name && !ingress.enabled`)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expVars := []string{"image.repository", "image.tag", "name", "ingress.enabled"}
+
+	if vars := e.Variables(); !reflect.DeepEqual(vars, expVars) {
+		t.Errorf("wrong variables: expected %v, got %v", expVars, vars)
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	falsy := []interface{}{nil, false, float64(0), 0, "", []interface{}{}, map[string]interface{}{}}
+
+	for _, val := range falsy {
+		if expr.Truthy(val) {
+			t.Errorf("expected %v to be false", val)
+		}
+	}
+
+	truthy := []interface{}{true, float64(1), "false", []interface{}{1}, map[string]interface{}{"a": 1}}
+
+	for _, val := range truthy {
+		if !expr.Truthy(val) {
+			t.Errorf("expected %v to be true", val)
+		}
+	}
+}
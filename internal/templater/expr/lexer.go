@@ -0,0 +1,141 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+
+	// the value of number and string tokens
+	val interface{}
+}
+
+// operators are the operator tokens, with the longer operators first so that they
+// are matched before their prefixes
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"<", ">", "+", "-", "*", "/", "%", "!",
+}
+
+// lex splits an expression into tokens, ending with a tokenEOF
+func lex(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	i := 0
+
+	for i < len(s) {
+		c := rune(s[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			str, n, err := lexString(s[i:])
+
+			if err != nil {
+				return nil, fmt.Errorf("%v at position %d", err, i)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: s[i : i+n], pos: i, val: str})
+			i += n
+		case c >= '0' && c <= '9':
+			start := i
+
+			for i < len(s) && ((s[i] >= '0' && s[i] <= '9') || s[i] == '.') {
+				i++
+			}
+
+			num, err := strconv.ParseFloat(s[start:i], 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at position %d", s[start:i], start)
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: s[start:i], pos: start, val: num})
+		case isIdentStart(c):
+			start := i
+
+			for i < len(s) && isIdentPart(rune(s[i])) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: s[start:i], pos: start})
+		default:
+			op := ""
+
+			for _, candidate := range operators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+// lexString reads a quoted string from the start of s, and returns the unquoted
+// string and the number of bytes read
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isIdentStart(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+// isIdentPart allows the dots of the paths into values
+func isIdentPart(c rune) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '.'
+}
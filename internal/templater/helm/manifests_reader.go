@@ -12,8 +12,6 @@ import (
 
 // ManifestsTemplateReader implements the TemplateReader for reading from
 // the Helm manifests of a given release.
-//
-// Note: ReadStream does nothing at the moment.
 type ManifestsTemplateReader struct {
 	Queries []*templater.TemplateReaderQuery
 
@@ -68,10 +66,11 @@ func (r *ManifestsTemplateReader) Read() (map[string]interface{}, error) {
 	return utils.QueryValues(values, r.Queries)
 }
 
-// ReadStream is unimplemented: stub just to implement TemplateReader
+// ReadStream sends the queried manifest values once. The manifests of a release
+// revision never change, so there are no later updates to stream.
 func (r *ManifestsTemplateReader) ReadStream(
 	on templater.OnDataStream,
 	stopCh <-chan struct{},
 ) error {
-	return nil
+	return utils.ReadOnce(r, on)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/templater/utils"
 	"helm.sh/helm/v3/pkg/chart"
 )
 
@@ -51,15 +52,25 @@ func (w *ValuesTemplateWriter) Create(
 	return vals, nil
 }
 
-// Update upgrades a chart, ReleaseName must be set
+// Update upgrades a release, ReleaseName must be set. The values are merged into
+// the values of the latest revision of the release, so only the values that
+// change need to be passed.
 func (w *ValuesTemplateWriter) Update(
 	vals map[string]interface{},
 ) (map[string]interface{}, error) {
-	if w.ReleaseName != "" {
+	if w.ReleaseName == "" {
 		return nil, fmt.Errorf("release not set")
 	}
 
-	_, err := w.Agent.UpgradeReleaseByValues(w.ReleaseName, vals)
+	rel, err := w.Agent.GetRelease(w.ReleaseName, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals = utils.CoalesceValues(rel.Config, vals)
+
+	_, err = w.Agent.UpgradeReleaseByValues(w.ReleaseName, vals)
 
 	if err != nil {
 		return nil, err
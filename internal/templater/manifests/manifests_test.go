@@ -0,0 +1,369 @@
+package manifests_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository/test"
+	"github.com/porter-dev/porter/internal/templater/manifests"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const deploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.19
+---
+# the service of the deployment
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func newMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+
+	return mapper
+}
+
+func TestManifestsTemplateReader(t *testing.T) {
+	r := &manifests.ManifestsTemplateReader{
+		Release: &models.ManifestRelease{
+			Kind: models.ManifestKindRaw,
+			Files: []models.ManifestFile{
+				models.ManifestFile{Path: "web.yaml", Data: deploymentYAML},
+				models.ManifestFile{Path: "form.yaml", Data: "name: web"},
+			},
+		},
+	}
+
+	query, err := utils.NewQuery("replicas", "{ .Deployment.web.spec.replicas }")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	r.RegisterQuery(query)
+
+	data, err := r.Read()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if expVal := []interface{}{float64(1)}; !reflect.DeepEqual(data["replicas"], expVal) {
+		t.Errorf("wrong replicas: expected %v, got %v", expVal, data["replicas"])
+	}
+}
+
+func TestManifestsTemplateWriterRaw(t *testing.T) {
+	client := kubernetes.NewDynamicClientTesting()
+	repo := test.NewRepository(true)
+
+	rel := &models.ManifestRelease{
+		ProjectID: 1,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "web.yaml", Data: deploymentYAML},
+		},
+	}
+
+	w := &manifests.ManifestsTemplateWriter{
+		Release: rel,
+		Repo:    repo.ManifestRelease,
+		Client:  client,
+		Mapper:  newMapper(),
+	}
+
+	if _, err := w.Create(nil); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err := w.Update(map[string]interface{}{
+		"Deployment": map[string]interface{}{
+			"web": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 3,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	rel, err = repo.ManifestRelease.ReadManifestRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the deployment is patched in place, and the service is left as it was
+	docs := strings.Split(rel.Files[0].Data, "\n---\n")
+
+	if rel.Revision != 2 || len(docs) != 2 || !strings.Contains(docs[0], "replicas: 3") ||
+		!strings.HasPrefix(docs[1], "# the service of the deployment") {
+		t.Errorf("wrong patched release: revision %d, files %s", rel.Revision, rel.Files[0].Data)
+	}
+
+	obj, err := client.Resource(deploymentsGVR).Namespace("default").Get(
+		context.TODO(),
+		"web",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("wrong applied replicas: expected 3, got %v", replicas)
+	}
+
+	_, err = w.Update(map[string]interface{}{
+		"Deployment": map[string]interface{}{
+			"missing": map[string]interface{}{},
+		},
+	})
+
+	if err == nil {
+		t.Errorf("expected error for object that is not in the release")
+	}
+}
+
+func TestManifestsTemplateWriterKustomize(t *testing.T) {
+	client := kubernetes.NewDynamicClientTesting()
+	repo := test.NewRepository(true)
+
+	rel := &models.ManifestRelease{
+		ProjectID: 1,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindKustomize,
+		Overlay:   "overlays/prod",
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "base/kustomization.yaml", Data: "resources:\n- web.yaml\n"},
+			models.ManifestFile{Path: "base/web.yaml", Data: deploymentYAML},
+			models.ManifestFile{
+				Path: "overlays/prod/kustomization.yaml",
+				Data: "bases:\n- ../../base\ncommonLabels:\n  env: prod\n",
+			},
+		},
+	}
+
+	w := &manifests.ManifestsTemplateWriter{
+		Release: rel,
+		Repo:    repo.ManifestRelease,
+		Client:  client,
+		Mapper:  newMapper(),
+	}
+
+	_, err := w.Create(map[string]interface{}{
+		"Deployment": map[string]interface{}{
+			"web": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 2,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	objs, err := manifests.Render(rel)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(objs) != 2 {
+		t.Fatalf("wrong number of objects: expected 2, got %d", len(objs))
+	}
+
+	for _, obj := range objs {
+		if obj.GetLabels()["env"] != "prod" {
+			t.Errorf("expected overlay label on %s", obj.GetKind())
+		}
+
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+
+		if replicas, _, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); replicas != float64(2) {
+			t.Errorf("wrong replicas: expected 2, got %v", replicas)
+		}
+	}
+
+	expPatch := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`
+
+	patch := rel.Files[len(rel.Files)-1]
+
+	if patch.Path != "overlays/prod/porter-patch.yaml" || patch.Data != expPatch {
+		t.Errorf("wrong patch file %s:\n%s", patch.Path, patch.Data)
+	}
+
+	if !strings.Contains(rel.Files[2].Data, "- porter-patch.yaml") {
+		t.Errorf("expected patch in kustomization, got:\n%s", rel.Files[2].Data)
+	}
+
+	obj, err := client.Resource(deploymentsGVR).Namespace("default").Get(
+		context.TODO(),
+		"web",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if obj.GetLabels()["env"] != "prod" {
+		t.Errorf("expected applied deployment to have overlay label")
+	}
+}
+
+func TestRenderKustomizeRejectsOutsidePaths(t *testing.T) {
+	tests := []struct {
+		name          string
+		overlay       string
+		kustomization string
+	}{
+		{
+			name:          "remote base",
+			overlay:       "overlays/prod",
+			kustomization: "bases:\n- github.com/kubernetes-sigs/kustomize/examples/multibases?ref=v1.0.6\n",
+		},
+		{
+			name:          "remote base with scheme",
+			overlay:       "overlays/prod",
+			kustomization: "bases:\n- https://github.com/kubernetes-sigs/kustomize//examples/multibases\n",
+		},
+		{
+			name:          "base outside of the release",
+			overlay:       "overlays/prod",
+			kustomization: "bases:\n- ../../../base\n",
+		},
+		{
+			name:          "absolute resource",
+			overlay:       "overlays/prod",
+			kustomization: "resources:\n- /etc/passwd\n",
+		},
+		{
+			name:          "missing resource",
+			overlay:       "overlays/prod",
+			kustomization: "resources:\n- web.yaml\n",
+		},
+		{
+			name:          "remote overlay",
+			overlay:       "github.com/kubernetes-sigs/kustomize/examples/helloWorld",
+			kustomization: "bases:\n- ../../base\n",
+		},
+	}
+
+	for _, test := range tests {
+		rel := &models.ManifestRelease{
+			Kind:    models.ManifestKindKustomize,
+			Overlay: test.overlay,
+			Files: []models.ManifestFile{
+				models.ManifestFile{Path: "base/kustomization.yaml", Data: "resources:\n- web.yaml\n"},
+				models.ManifestFile{Path: "base/web.yaml", Data: deploymentYAML},
+				models.ManifestFile{Path: "overlays/prod/kustomization.yaml", Data: test.kustomization},
+			},
+		}
+
+		if _, err := manifests.Render(rel); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
+
+func TestManifestsTemplateWriterPrune(t *testing.T) {
+	client := kubernetes.NewDynamicClientTesting()
+	repo := test.NewRepository(true)
+
+	rel := &models.ManifestRelease{
+		ProjectID: 1,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "web.yaml", Data: deploymentYAML},
+		},
+	}
+
+	w := &manifests.ManifestsTemplateWriter{
+		Release: rel,
+		Repo:    repo.ManifestRelease,
+		Client:  client,
+		Mapper:  newMapper(),
+	}
+
+	if _, err := w.Create(nil); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	prev, err := manifests.Render(rel)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the service is removed from the files of the release
+	rel.Files = []models.ManifestFile{
+		models.ManifestFile{Path: "web.yaml", Data: strings.Split(deploymentYAML, "---\n")[0]},
+	}
+
+	w.Previous = prev
+
+	if _, err := w.Update(nil); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	servicesGVR := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+	if _, err := client.Resource(servicesGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected removed service to be deleted")
+	}
+
+	if _, err := client.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected deployment to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/utils"
+)
+
+// ManifestsTemplateReader implements the TemplateReader for reading from the
+// objects of a release of raw manifests or Kustomize, as stored in Porter
+type ManifestsTemplateReader struct {
+	Queries []*templater.TemplateReaderQuery
+
+	Release *models.ManifestRelease
+}
+
+// ValuesFromTarget returns the rendered objects of the release, keyed by the kind
+// and then the name of each object
+func (r *ManifestsTemplateReader) ValuesFromTarget() (map[string]interface{}, error) {
+	if r.Release == nil {
+		return nil, fmt.Errorf("must set release to read manifests")
+	}
+
+	objs, err := Render(r.Release)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return objectValues(objs), nil
+}
+
+// RegisterQuery adds a new query to be executed against the values
+func (r *ManifestsTemplateReader) RegisterQuery(query *templater.TemplateReaderQuery) error {
+	r.Queries = append(r.Queries, query)
+
+	return nil
+}
+
+// Read executes the queries against the objects of the release
+func (r *ManifestsTemplateReader) Read() (map[string]interface{}, error) {
+	values, err := r.ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.QueryValues(values, r.Queries)
+}
+
+// ReadStream sends the queried values once. The stored files of a release only
+// change when the release is upgraded, so there are no later updates to stream.
+func (r *ManifestsTemplateReader) ReadStream(
+	on templater.OnDataStream,
+	stopCh <-chan struct{},
+) error {
+	return utils.ReadOnce(r, on)
+}
@@ -0,0 +1,264 @@
+package manifests
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/kustomize"
+	"sigs.k8s.io/kustomize/pkg/fs"
+	"sigs.k8s.io/kustomize/pkg/git"
+	"sigs.k8s.io/yaml"
+)
+
+// Render returns the objects of a manifest release. The objects of a release of raw
+// manifests are read from every YAML file of the release other than the form.yaml,
+// in the order of the file paths. The objects of a Kustomize release are built from
+// the overlay of the release, which refers to its base by a relative path.
+func Render(rel *models.ManifestRelease) ([]*unstructured.Unstructured, error) {
+	switch rel.Kind {
+	case models.ManifestKindRaw:
+		res := make([]*unstructured.Unstructured, 0)
+
+		for _, file := range sortedFiles(rel) {
+			if !isManifestFile(file.Path) {
+				continue
+			}
+
+			objs, err := parseObjects([]byte(file.Data))
+
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s: %v", file.Path, err)
+			}
+
+			res = append(res, objs...)
+		}
+
+		return res, nil
+	case models.ManifestKindKustomize:
+		data, err := buildKustomize(rel)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return parseObjects(data)
+	}
+
+	return nil, fmt.Errorf("unknown manifest release kind %s", rel.Kind)
+}
+
+// buildKustomize runs a Kustomize build of the overlay of the release, with the
+// files of the release written to an in-memory file system
+func buildKustomize(rel *models.ManifestRelease) ([]byte, error) {
+	if err := checkKustomizePaths(rel); err != nil {
+		return nil, err
+	}
+
+	fSys := fs.MakeFakeFS()
+
+	for _, file := range rel.Files {
+		filePath := path.Join("/", file.Path)
+
+		for dir := path.Dir(filePath); dir != "/"; dir = path.Dir(dir) {
+			fSys.Mkdir(dir)
+		}
+
+		if err := fSys.WriteFile(filePath, []byte(file.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := kustomize.RunKustomizeBuild(&buf, fSys, path.Join("/", rel.Overlay)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// kustomizationFiles are the names of the files that Kustomize reads a
+// kustomization from
+var kustomizationFiles = map[string]bool{
+	"kustomization.yaml": true,
+	"kustomization.yml":  true,
+	"Kustomization":      true,
+}
+
+// checkKustomizePaths checks that the overlay, and the bases and resources of every
+// kustomization of a release, are relative paths to files of the release. Kustomize
+// clones the git repo of a base that is a URL, so remote bases are rejected rather
+// than fetched by the server.
+func checkKustomizePaths(rel *models.ManifestRelease) error {
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+
+	for _, file := range rel.Files {
+		filePath := path.Clean(file.Path)
+		files[filePath] = true
+
+		for dir := path.Dir(filePath); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	if overlay, ok := releasePath(".", rel.Overlay); !ok || (overlay != "." && !dirs[overlay]) {
+		return fmt.Errorf("overlay %s is not a directory of the release", rel.Overlay)
+	}
+
+	for _, file := range rel.Files {
+		filePath := path.Clean(file.Path)
+
+		if !kustomizationFiles[path.Base(filePath)] {
+			continue
+		}
+
+		kustomization := &struct {
+			Bases     []string `json:"bases"`
+			Resources []string `json:"resources"`
+		}{}
+
+		if err := yaml.Unmarshal([]byte(file.Data), kustomization); err != nil {
+			return fmt.Errorf("could not parse %s: %v", file.Path, err)
+		}
+
+		for _, base := range kustomization.Bases {
+			if basePath, ok := releasePath(path.Dir(filePath), base); !ok || !dirs[basePath] {
+				return fmt.Errorf("base %s of %s is not a directory of the release", base, file.Path)
+			}
+		}
+
+		for _, resource := range kustomization.Resources {
+			resourcePath, ok := releasePath(path.Dir(filePath), resource)
+
+			if !ok || (!files[resourcePath] && !dirs[resourcePath]) {
+				return fmt.Errorf("resource %s of %s is not a file of the release", resource, file.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// releasePath resolves a path relative to a directory of a release, and returns
+// false if the path is absolute, is a URL, or leaves the files of the release
+func releasePath(dir, p string) (string, bool) {
+	if p == "" || path.IsAbs(p) || strings.Contains(p, ":") {
+		return "", false
+	}
+
+	if _, err := git.NewRepoSpecFromUrl(p); err == nil {
+		return "", false
+	}
+
+	res := path.Clean(path.Join(dir, p))
+
+	if res == ".." || strings.HasPrefix(res, "../") {
+		return "", false
+	}
+
+	return res, true
+}
+
+// parseObjects parses the objects of a multi-document YAML file, expanding lists
+func parseObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	res := make([]*unstructured.Unstructured, 0)
+
+	for _, doc := range splitDocuments(string(data)) {
+		obj := make(map[string]interface{})
+
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, err
+		}
+
+		if len(obj) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+
+		if u.IsList() {
+			list, err := u.ToList()
+
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range list.Items {
+				res = append(res, &list.Items[i])
+			}
+
+			continue
+		}
+
+		if u.GetKind() == "" || u.GetName() == "" {
+			return nil, fmt.Errorf("every object must set a kind and a name")
+		}
+
+		res = append(res, u)
+	}
+
+	return res, nil
+}
+
+// splitDocuments splits a multi-document YAML file on its "---" separators
+func splitDocuments(data string) []string {
+	res := make([]string, 0)
+	lines := make([]string, 0)
+
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(line, "---") && strings.TrimSpace(strings.TrimLeft(line, "-")) == "" {
+			res = append(res, strings.Join(lines, "\n"))
+			lines = lines[:0]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return append(res, strings.Join(lines, "\n"))
+}
+
+// objectValues returns the values of a release's objects, keyed by the kind and then
+// the name of each object, so that the variables of a form are paths such as
+// Deployment.web.spec.replicas
+func objectValues(objs []*unstructured.Unstructured) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for _, obj := range objs {
+		byName, ok := res[obj.GetKind()].(map[string]interface{})
+
+		if !ok {
+			byName = make(map[string]interface{})
+			res[obj.GetKind()] = byName
+		}
+
+		byName[obj.GetName()] = obj.Object
+	}
+
+	return res
+}
+
+func sortedFiles(rel *models.ManifestRelease) []models.ManifestFile {
+	files := make([]models.ManifestFile, len(rel.Files))
+	copy(files, rel.Files)
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files
+}
+
+// isManifestFile returns whether a file of a release of raw manifests holds
+// manifests
+func isManifestFile(filePath string) bool {
+	ext := path.Ext(filePath)
+
+	return (ext == ".yaml" || ext == ".yml") && path.Base(filePath) != "form.yaml"
+}
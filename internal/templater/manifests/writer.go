@@ -0,0 +1,479 @@
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PatchFileName is the file in the overlay of a Kustomize release that the values
+// written to the release are stored in, as strategic merge patches
+const PatchFileName = "porter-patch.yaml"
+
+// ManifestsTemplateWriter deploys a release of raw manifests or Kustomize to a
+// cluster, and stores the files of the release in Porter
+type ManifestsTemplateWriter struct {
+	Release *models.ManifestRelease
+	Repo    repository.ManifestReleaseRepository
+
+	// The client and mapper that the objects of the release are applied with
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+
+	// Previous are the objects of the release before its files were replaced. On
+	// an update, the previous objects that are no longer objects of the release are
+	// deleted from the cluster. If nil, they are rendered from the release's files
+	// before the values are written.
+	Previous []*unstructured.Unstructured
+}
+
+// Transform does nothing, since the values are written to the files of the release
+func (w *ManifestsTemplateWriter) Transform() error {
+	return nil
+}
+
+// Create writes the values to the files of a new release, stores the release, and
+// applies the objects of the release to the cluster. The release is stored before
+// its objects are applied, so that objects applied before a failure can still be
+// upgraded or deleted with the release. The values are keyed by the kind and then
+// the name of the object that they are merged into.
+func (w *ManifestsTemplateWriter) Create(
+	vals map[string]interface{},
+) (map[string]interface{}, error) {
+	objs, err := w.render(vals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.Release.Revision = 1
+
+	if _, err := w.Repo.CreateManifestRelease(w.Release); err != nil {
+		return nil, err
+	}
+
+	if err := Apply(w.Client, w.Mapper, w.Release.Namespace, objs); err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
+
+// Update writes the values to the files of an existing release, applies the objects
+// of the release to the cluster, deletes the objects that were removed from the
+// release, and stores the release as a new revision. Only the values that change
+// need to be passed.
+func (w *ManifestsTemplateWriter) Update(
+	vals map[string]interface{},
+) (map[string]interface{}, error) {
+	if w.Release == nil || w.Release.ID == 0 {
+		return nil, fmt.Errorf("release not set")
+	}
+
+	prev := w.Previous
+
+	if prev == nil {
+		var err error
+
+		if prev, err = Render(w.Release); err != nil {
+			return nil, err
+		}
+	}
+
+	objs, err := w.render(vals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Apply(w.Client, w.Mapper, w.Release.Namespace, objs); err != nil {
+		return nil, err
+	}
+
+	if err := Delete(w.Client, w.Mapper, w.Release.Namespace, removedObjects(w.Release.Namespace, prev, objs)); err != nil {
+		return nil, err
+	}
+
+	w.Release.Revision++
+
+	if _, err := w.Repo.UpdateManifestRelease(w.Release); err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
+
+// render writes the values to the files of the release, and returns the objects
+// of the release
+func (w *ManifestsTemplateWriter) render(vals map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	if w.Release == nil {
+		return nil, fmt.Errorf("release not set")
+	}
+
+	if err := PatchFiles(w.Release, vals); err != nil {
+		return nil, err
+	}
+
+	return Render(w.Release)
+}
+
+// PatchFiles merges values into the objects of a release, keyed by the kind and
+// then the name of each object. The objects of raw manifests are patched in the
+// files they are in, which are written back without their comments. The values of a
+// Kustomize release are merged into the strategic merge patches in the
+// porter-patch.yaml of the overlay, which is added to the overlay's kustomization.
+func PatchFiles(rel *models.ManifestRelease, vals map[string]interface{}) error {
+	patches := make(map[string]map[string]interface{})
+
+	for kind, byName := range vals {
+		names, ok := byName.(map[string]interface{})
+
+		if !ok {
+			return fmt.Errorf("values of %s must be keyed by the name of an object", kind)
+		}
+
+		for name, patch := range names {
+			patchMap, ok := patch.(map[string]interface{})
+
+			if !ok {
+				return fmt.Errorf("values of %s %s must be an object", kind, name)
+			}
+
+			patches[objectKey(kind, name)] = patchMap
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil
+	}
+
+	if rel.Kind == models.ManifestKindKustomize {
+		return patchKustomizeFiles(rel, patches)
+	}
+
+	return patchRawFiles(rel, patches)
+}
+
+func patchRawFiles(rel *models.ManifestRelease, patches map[string]map[string]interface{}) error {
+	patched := make(map[string]bool)
+
+	for i, file := range rel.Files {
+		if !isManifestFile(file.Path) {
+			continue
+		}
+
+		docs := splitDocuments(file.Data)
+		changed := false
+
+		for j, doc := range docs {
+			obj := make(map[string]interface{})
+
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				return fmt.Errorf("could not parse %s: %v", file.Path, err)
+			}
+
+			u := &unstructured.Unstructured{Object: obj}
+			key := objectKey(u.GetKind(), u.GetName())
+			patch, ok := patches[key]
+
+			if !ok {
+				continue
+			}
+
+			newDoc, err := marshalDocument(utils.CoalesceValues(obj, utils.CopyValues(patch)))
+
+			if err != nil {
+				return err
+			}
+
+			if strings.HasSuffix(doc, "\n") {
+				newDoc += "\n"
+			}
+
+			docs[j] = newDoc
+			patched[key] = true
+			changed = true
+		}
+
+		if changed {
+			rel.Files[i].Data = strings.Join(docs, "\n---\n")
+		}
+	}
+
+	for key := range patches {
+		if !patched[key] {
+			return fmt.Errorf("%s is not an object of the release", key)
+		}
+	}
+
+	return nil
+}
+
+func patchKustomizeFiles(rel *models.ManifestRelease, patches map[string]map[string]interface{}) error {
+	objs, err := Render(rel)
+
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]*unstructured.Unstructured)
+
+	for _, obj := range objs {
+		targets[objectKey(obj.GetKind(), obj.GetName())] = obj
+	}
+
+	patchPath := path.Join(rel.Overlay, PatchFileName)
+
+	existing, err := parseObjects([]byte(fileData(rel, patchPath)))
+
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %v", patchPath, err)
+	}
+
+	// the patches are merged into the existing patches, in the order they were
+	// first written
+	docs := make([]map[string]interface{}, 0)
+	byKey := make(map[string]map[string]interface{})
+
+	for _, obj := range existing {
+		docs = append(docs, obj.Object)
+		byKey[objectKey(obj.GetKind(), obj.GetName())] = obj.Object
+	}
+
+	for _, key := range sortedKeys(patches) {
+		target, ok := targets[key]
+
+		if !ok {
+			return fmt.Errorf("%s is not an object of the release", key)
+		}
+
+		doc, ok := byKey[key]
+
+		if !ok {
+			doc = map[string]interface{}{
+				"apiVersion": target.GetAPIVersion(),
+				"kind":       target.GetKind(),
+				"metadata": map[string]interface{}{
+					"name": target.GetName(),
+				},
+			}
+
+			docs = append(docs, doc)
+		}
+
+		merged := utils.CoalesceValues(doc, utils.CopyValues(patches[key]))
+
+		for k := range doc {
+			delete(doc, k)
+		}
+
+		for k, v := range merged {
+			doc[k] = v
+		}
+	}
+
+	strs := make([]string, 0, len(docs))
+
+	for _, doc := range docs {
+		str, err := marshalDocument(doc)
+
+		if err != nil {
+			return err
+		}
+
+		strs = append(strs, str)
+	}
+
+	setFileData(rel, patchPath, strings.Join(strs, "\n---\n")+"\n")
+
+	return addKustomizePatch(rel)
+}
+
+// addKustomizePatch adds the porter-patch.yaml to the strategic merge patches of the
+// kustomization of the overlay, if it is not already listed
+func addKustomizePatch(rel *models.ManifestRelease) error {
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		kustPath := path.Join(rel.Overlay, name)
+
+		for i, file := range rel.Files {
+			if path.Clean(file.Path) != kustPath {
+				continue
+			}
+
+			kust := make(map[string]interface{})
+
+			if err := yaml.Unmarshal([]byte(file.Data), &kust); err != nil {
+				return fmt.Errorf("could not parse %s: %v", file.Path, err)
+			}
+
+			patchFiles, _ := kust["patchesStrategicMerge"].([]interface{})
+
+			for _, patchFile := range patchFiles {
+				if patchFile == PatchFileName {
+					return nil
+				}
+			}
+
+			kust["patchesStrategicMerge"] = append(patchFiles, PatchFileName)
+
+			data, err := yaml.Marshal(kust)
+
+			if err != nil {
+				return err
+			}
+
+			rel.Files[i].Data = string(data)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("overlay %s has no kustomization", rel.Overlay)
+}
+
+// Apply applies the objects of a release to the cluster using server-side apply,
+// with Porter as the field manager. Namespaced objects that do not set a namespace
+// are applied to the namespace of the release.
+func Apply(
+	client dynamic.Interface,
+	mapper meta.RESTMapper,
+	namespace string,
+	objs []*unstructured.Unstructured,
+) error {
+	_, err := kubernetes.ApplyObjects(client, mapper, namespace, objs)
+
+	return err
+}
+
+// Delete deletes the objects of a release from the cluster. Objects that do not
+// exist are skipped. Namespaced objects that do not set a namespace are deleted from
+// the namespace of the release.
+func Delete(
+	client dynamic.Interface,
+	mapper meta.RESTMapper,
+	namespace string,
+	objs []*unstructured.Unstructured,
+) error {
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+
+		if err != nil {
+			return err
+		}
+
+		var resource dynamic.ResourceInterface = client.Resource(mapping.Resource)
+
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			objNamespace := obj.GetNamespace()
+
+			if objNamespace == "" {
+				objNamespace = namespace
+			}
+
+			resource = client.Resource(mapping.Resource).Namespace(objNamespace)
+		}
+
+		err = resource.Delete(context.TODO(), obj.GetName(), metav1.DeleteOptions{})
+
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("could not delete %s %s: %v", gvk.Kind, obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// removedObjects returns the previous objects of a release that are not objects of
+// the release anymore
+func removedObjects(namespace string, prev, objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	keys := make(map[string]bool)
+
+	for _, obj := range objs {
+		keys[objectIdentity(namespace, obj)] = true
+	}
+
+	res := make([]*unstructured.Unstructured, 0)
+
+	for _, obj := range prev {
+		if !keys[objectIdentity(namespace, obj)] {
+			res = append(res, obj)
+		}
+	}
+
+	return res
+}
+
+// objectIdentity identifies an object by its group, kind, namespace and name, where
+// an object that does not set a namespace is in the namespace of the release
+func objectIdentity(namespace string, obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+
+	if obj.GetNamespace() != "" {
+		namespace = obj.GetNamespace()
+	}
+
+	return fmt.Sprintf("%s/%s %s/%s", gvk.Group, gvk.Kind, namespace, obj.GetName())
+}
+
+func objectKey(kind, name string) string {
+	return fmt.Sprintf("%s %s", kind, name)
+}
+
+func marshalDocument(obj map[string]interface{}) (string, error) {
+	data, err := yaml.Marshal(obj)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+func fileData(rel *models.ManifestRelease, filePath string) string {
+	for _, file := range rel.Files {
+		if path.Clean(file.Path) == filePath {
+			return file.Data
+		}
+	}
+
+	return ""
+}
+
+func setFileData(rel *models.ManifestRelease, filePath, data string) {
+	for i, file := range rel.Files {
+		if path.Clean(file.Path) == filePath {
+			rel.Files[i].Data = data
+			return
+		}
+	}
+
+	rel.Files = append(rel.Files, models.ManifestFile{Path: filePath, Data: data})
+}
+
+func sortedKeys(patches map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(patches))
+
+	for key := range patches {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/expr"
+	"github.com/porter-dev/porter/internal/templater/utils"
+)
+
+// ComputeFormYAMLValues sets the variables of the contents that compute their value
+// from the other values, in the order the contents appear in the form, so a computed
+// value can depend on the contents before it. Contents in sections that are hidden
+// by their show_if are skipped. Values that are not set in vals fall back to the
+// default values of the chart.
+func ComputeFormYAMLValues(
+	bytes []byte,
+	vals map[string]interface{},
+	defaults map[string]interface{},
+) error {
+	form, err := unqueriedFormYAMLFromBytes(bytes)
+
+	if err != nil {
+		return err
+	}
+
+	resolve := valuesResolver(vals, defaults)
+
+	for _, tab := range form.Tabs {
+		for _, section := range tab.Sections {
+			shown, err := sectionShown(section, resolve)
+
+			if err != nil {
+				return err
+			}
+
+			if !shown {
+				continue
+			}
+
+			for _, content := range section.Contents {
+				if !isComputedContent(content) {
+					continue
+				}
+
+				val, err := expr.Eval(content.Compute, resolve)
+
+				if err != nil {
+					return fmt.Errorf("could not compute %s: %v", content.Variable, err)
+				}
+
+				utils.SetValue(vals, content.Variable, val)
+			}
+		}
+	}
+
+	return nil
+}
+
+// evalFormYAMLExpressions hides the sections of a form whose show_if evaluates to
+// false, and sets the values of the computed contents, with the values of the
+// release or chart of the default config. Like ComputeFormYAMLValues, only the
+// contents of shown sections that compute a variable of the release's values are
+// computed, so the form shows the values that a deploy would write. Expressions
+// that cannot be evaluated are skipped.
+func evalFormYAMLExpressions(def *ClientConfigDefault, form *models.FormYAML) {
+	var vals, defaults map[string]interface{}
+
+	if def.HelmRelease != nil {
+		vals = def.HelmRelease.Config
+
+		if def.HelmRelease.Chart != nil {
+			defaults = def.HelmRelease.Chart.Values
+		}
+	} else if def.HelmChart != nil {
+		defaults = def.HelmChart.Values
+	}
+
+	resolve := valuesResolver(vals, defaults)
+
+	for _, tab := range form.Tabs {
+		for _, section := range tab.Sections {
+			shown, err := sectionShown(section, resolve)
+
+			if err != nil {
+				continue
+			}
+
+			section.Hidden = !shown
+
+			if !shown {
+				continue
+			}
+
+			for _, content := range section.Contents {
+				if !isComputedContent(content) {
+					continue
+				}
+
+				if val, err := expr.Eval(content.Compute, resolve); err == nil {
+					content.Value = val
+				}
+			}
+		}
+	}
+}
+
+// sectionShown evaluates the show_if of a section, which is shown if it has no
+// show_if
+func sectionShown(section *models.FormSection, resolve expr.Resolver) (bool, error) {
+	if section.ShowIf == "" {
+		return true, nil
+	}
+
+	val, err := expr.Eval(section.ShowIf, resolve)
+
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate show_if of section %s: %v", section.Name, err)
+	}
+
+	return expr.Truthy(val), nil
+}
+
+// valuesResolver resolves paths into vals, falling back to defaults
+func valuesResolver(vals, defaults map[string]interface{}) expr.Resolver {
+	return func(path string) interface{} {
+		if val, ok := lookupValue(vals, path); ok {
+			return val
+		}
+
+		val, _ := lookupValue(defaults, path)
+
+		return val
+	}
+}
+
+// isComputedContent returns whether a content computes a variable of the values of
+// the release of the form
+func isComputedContent(content *models.FormContent) bool {
+	return content.Compute != "" && content.Variable != "" && isReleaseValuesContext(content.Context)
+}
+
+// isReleaseValuesContext returns whether a context reads from the values of the
+// release of the form, rather than from another release or the cluster
+func isReleaseValuesContext(context *models.FormContext) bool {
+	return context.Type == "helm/values" && context.Config["Name"] == ""
+}
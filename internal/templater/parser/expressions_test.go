@@ -0,0 +1,190 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const expressionFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: image
+    contents:
+    - type: string-input
+      label: Image Tag
+      variable: image.tag
+    - type: heading
+      label: Image
+      variable: image.full
+      compute: image.repository + ":" + image.tag
+  - name: ingress
+    show_if: ingress.enabled == true
+    contents:
+    - type: string-input
+      label: Host
+      variable: ingress.host
+      settings:
+        required: true
+    - type: heading
+      label: URL
+      variable: ingress.url
+      compute: '"https://" + ingress.host'
+`
+
+var expressionDefaults = map[string]interface{}{
+	"image": map[string]interface{}{
+		"repository": "nginx",
+		"tag":        "1.19",
+	},
+	"ingress": map[string]interface{}{
+		"enabled": false,
+	},
+}
+
+func TestComputeFormYAMLValues(t *testing.T) {
+	vals := map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag": "1.20",
+		},
+	}
+
+	err := parser.ComputeFormYAMLValues([]byte(expressionFormYAML), vals, expressionDefaults)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the computed values of hidden sections are not set
+	expVals := map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag":  "1.20",
+			"full": "nginx:1.20",
+		},
+	}
+
+	if !reflect.DeepEqual(vals, expVals) {
+		t.Errorf("wrong values: expected %v, got %v", expVals, vals)
+	}
+
+	vals = map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"host":    "example.com",
+		},
+	}
+
+	err = parser.ComputeFormYAMLValues([]byte(expressionFormYAML), vals, expressionDefaults)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if url := vals["ingress"].(map[string]interface{})["url"]; url != "https://example.com" {
+		t.Errorf("wrong url: expected https://example.com, got %v", url)
+	}
+}
+
+func TestValidateFormYAMLValuesShowIf(t *testing.T) {
+	// the required host is only validated when ingress is enabled
+	errs, err := parser.ValidateFormYAMLValues([]byte(expressionFormYAML), map[string]interface{}{}, expressionDefaults)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	errs, err = parser.ValidateFormYAMLValues([]byte(expressionFormYAML), map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"enabled": true,
+		},
+	}, expressionDefaults)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(errs) != 1 || errs[0].Error() != "ingress.host: is required" {
+		t.Errorf("expected ingress.host to be required, got %v", errs)
+	}
+}
+
+func TestFormYAMLFromBytesExpressions(t *testing.T) {
+	def := &parser.ClientConfigDefault{
+		HelmRelease: &release.Release{
+			Name: "web",
+			Chart: &chart.Chart{
+				Values: expressionDefaults,
+			},
+			Config: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"enabled": true,
+					"host":    "example.com",
+				},
+			},
+		},
+	}
+
+	form, err := parser.FormYAMLFromBytes(def, []byte(expressionFormYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	sections := form.Tabs[0].Sections
+
+	if sections[0].Hidden || sections[1].Hidden {
+		t.Errorf("expected sections to be shown")
+	}
+
+	if val := sections[0].Contents[1].Value; val != "nginx:1.19" {
+		t.Errorf("wrong computed image: expected nginx:1.19, got %v", val)
+	}
+
+	if val := sections[1].Contents[1].Value; val != "https://example.com" {
+		t.Errorf("wrong computed url: expected https://example.com, got %v", val)
+	}
+
+	def.HelmRelease.Config = map[string]interface{}{}
+
+	form, err = parser.FormYAMLFromBytes(def, []byte(expressionFormYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !form.Tabs[0].Sections[1].Hidden {
+		t.Errorf("expected ingress section to be hidden")
+	}
+
+	// the values of hidden sections are not computed, as on deploy
+	if val, ok := form.Tabs[0].Sections[1].Contents[1].Value.(string); ok {
+		t.Errorf("expected no computed url in hidden section, got %v", val)
+	}
+}
+
+func TestComputeFormYAMLValuesInvalidExpression(t *testing.T) {
+	err := parser.ComputeFormYAMLValues([]byte(`
+tabs:
+- name: main
+  sections:
+  - name: main
+    contents:
+    - type: heading
+      variable: replicas
+      compute: replicaCount +
+`), map[string]interface{}{}, nil)
+
+	if err == nil {
+		t.Errorf("expected error for invalid expression")
+	}
+}
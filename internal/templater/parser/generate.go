@@ -0,0 +1,471 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	yamlv2 "gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
+)
+
+// FormBytesFromChart returns the form.yaml of a chart. If the chart has no form.yaml,
+// a form is generated from the chart's values.schema.json or values.yaml, so a chart
+// overrides the generated form by adding a form.yaml. Returns nil if the chart has no
+// form and no values to generate one from.
+func FormBytesFromChart(ch *chart.Chart) ([]byte, error) {
+	for _, file := range ch.Files {
+		if strings.Contains(file.Name, "form.yaml") {
+			return file.Data, nil
+		}
+	}
+
+	form, err := GenerateFormYAML(ch)
+
+	if err != nil || form == nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(form)
+}
+
+// GenerateFormYAML generates a form for the values of a chart, which is marked as
+// generated. The form is generated from the chart's JSON schema if it has one, and
+// otherwise is inferred from the types of the default values and the comments above
+// them in values.yaml. Returns nil if the chart has no values.
+//
+// Objects at the top level of the values become sections of the form, and the
+// values nested in them become contents, with a variable that is the path to the
+// value. Values that are not booleans, numbers or strings, such as lists, are left
+// out of the form.
+func GenerateFormYAML(ch *chart.Chart) (*models.FormYAML, error) {
+	var sections []*models.FormSection
+
+	if len(ch.Schema) > 0 {
+		schema := &jsonSchema{}
+
+		if err := json.Unmarshal(ch.Schema, schema); err != nil {
+			return nil, err
+		}
+
+		sections = sectionsFromSchema(schema)
+	} else if len(ch.Values) > 0 {
+		sections = sectionsFromValues(ch)
+	}
+
+	if len(sections) == 0 {
+		return nil, nil
+	}
+
+	form := &models.FormYAML{
+		Generated: true,
+		Tabs: []*models.FormTab{
+			&models.FormTab{
+				Name:     "main",
+				Label:    "Main Settings",
+				Sections: sections,
+			},
+		},
+	}
+
+	if ch.Metadata != nil {
+		form.Name = ch.Metadata.Name
+		form.Icon = ch.Metadata.Icon
+		form.Description = ch.Metadata.Description
+		form.Tags = ch.Metadata.Keywords
+	}
+
+	return form, nil
+}
+
+// jsonSchema is the subset of a JSON schema that forms are generated from
+type jsonSchema struct {
+	Type       interface{}            `json:"type"`
+	Title      string                 `json:"title"`
+	Default    interface{}            `json:"default"`
+	Enum       []interface{}          `json:"enum"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Pattern    string                 `json:"pattern"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	MinLength  *int                   `json:"minLength"`
+	MaxLength  *int                   `json:"maxLength"`
+}
+
+// typeName returns the type of the schema, which may be a list of types of which the
+// first type other than null is used
+func (s *jsonSchema) typeName() string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, name := range t {
+			if str, ok := name.(string); ok && str != "null" {
+				return str
+			}
+		}
+	}
+
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+
+	return ""
+}
+
+func sectionsFromSchema(schema *jsonSchema) []*models.FormSection {
+	general := &models.FormSection{Name: "general"}
+	sections := []*models.FormSection{general}
+
+	for _, key := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[key]
+
+		if !isVariableKey(key) {
+			continue
+		}
+
+		if prop.typeName() == "object" {
+			section := &models.FormSection{
+				Name:     key,
+				Contents: []*models.FormContent{heading(key, prop.Title)},
+			}
+
+			section.Contents = append(section.Contents, contentsFromSchema(key, prop)...)
+			sections = append(sections, section)
+		} else if content := contentFromSchema(key, key, prop, contains(schema.Required, key)); content != nil {
+			general.Contents = append(general.Contents, content)
+		}
+	}
+
+	return nonEmptySections(sections)
+}
+
+func contentsFromSchema(path string, schema *jsonSchema) []*models.FormContent {
+	contents := make([]*models.FormContent, 0)
+
+	for _, key := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[key]
+		propPath := path + "." + key
+
+		if !isVariableKey(key) {
+			continue
+		}
+
+		if prop.typeName() == "object" {
+			contents = append(contents, contentsFromSchema(propPath, prop)...)
+		} else if content := contentFromSchema(key, propPath, prop, contains(schema.Required, key)); content != nil {
+			contents = append(contents, content)
+		}
+	}
+
+	return contents
+}
+
+func contentFromSchema(key, path string, schema *jsonSchema, required bool) *models.FormContent {
+	content := &models.FormContent{
+		Label:    schema.Title,
+		Variable: path,
+	}
+
+	if content.Label == "" {
+		content.Label = humanize(key)
+	}
+
+	switch schema.typeName() {
+	case "boolean":
+		content.Type = "checkbox"
+	case "integer", "number":
+		content.Type = "number-input"
+	case "string":
+		content.Type = "string-input"
+	default:
+		return nil
+	}
+
+	if len(schema.Enum) > 0 {
+		content.Type = "select"
+
+		for _, val := range schema.Enum {
+			content.Settings.Options = append(content.Settings.Options, &models.FormOption{
+				Label: toLabel(val),
+				Value: val,
+			})
+		}
+	}
+
+	content.Settings.Default = schema.Default
+	content.Settings.Required = required
+	content.Settings.Pattern = schema.Pattern
+	content.Settings.Min = schema.Minimum
+	content.Settings.Max = schema.Maximum
+	content.Settings.MinLength = schema.MinLength
+	content.Settings.MaxLength = schema.MaxLength
+
+	return content
+}
+
+func sectionsFromValues(ch *chart.Chart) []*models.FormSection {
+	comments := map[string]string{}
+	values := yamlv2.MapSlice{}
+
+	for _, file := range ch.Raw {
+		if file.Name == "values.yaml" {
+			comments = valuesComments(file.Data)
+			yamlv2.Unmarshal(file.Data, &values)
+		}
+	}
+
+	// without the raw values.yaml, the order of the values is lost
+	if len(values) == 0 {
+		values = toMapSlice(ch.Values)
+	}
+
+	general := &models.FormSection{Name: "general"}
+	sections := []*models.FormSection{general}
+
+	for _, item := range values {
+		key, _ := item.Key.(string)
+
+		if !isVariableKey(key) {
+			continue
+		}
+
+		if nested, ok := item.Value.(yamlv2.MapSlice); ok {
+			section := &models.FormSection{
+				Name:     key,
+				Contents: []*models.FormContent{heading(key, comments[key])},
+			}
+
+			section.Contents = append(section.Contents, contentsFromValues(key, nested, comments)...)
+			sections = append(sections, section)
+		} else if content := contentFromValue(key, key, item.Value, comments); content != nil {
+			general.Contents = append(general.Contents, content)
+		}
+	}
+
+	return nonEmptySections(sections)
+}
+
+func contentsFromValues(path string, values yamlv2.MapSlice, comments map[string]string) []*models.FormContent {
+	contents := make([]*models.FormContent, 0)
+
+	for _, item := range values {
+		key, _ := item.Key.(string)
+		valPath := path + "." + key
+
+		if !isVariableKey(key) {
+			continue
+		}
+
+		if nested, ok := item.Value.(yamlv2.MapSlice); ok {
+			contents = append(contents, contentsFromValues(valPath, nested, comments)...)
+		} else if content := contentFromValue(key, valPath, item.Value, comments); content != nil {
+			contents = append(contents, content)
+		}
+	}
+
+	return contents
+}
+
+func contentFromValue(key, path string, val interface{}, comments map[string]string) *models.FormContent {
+	content := &models.FormContent{
+		Label:    comments[path],
+		Variable: path,
+	}
+
+	if content.Label == "" {
+		content.Label = humanize(key)
+	}
+
+	switch val.(type) {
+	case bool:
+		content.Type = "checkbox"
+	case int, int64, uint64, float64:
+		content.Type = "number-input"
+	case string, nil:
+		content.Type = "string-input"
+	default:
+		return nil
+	}
+
+	content.Settings.Default = val
+
+	return content
+}
+
+var (
+	keyLinePattern     = regexp.MustCompile(`^(\s*)([A-Za-z0-9_.\-]+|"[^"]+"|'[^']+'):`)
+	commentLinePattern = regexp.MustCompile(`^\s*#+\s?(.*)$`)
+
+	// paramPattern matches the "@param key" prefix of comments in the style of the
+	// Bitnami charts
+	paramPattern = regexp.MustCompile(`^@param\s+\S+\s*`)
+)
+
+// valuesComments returns the first line of the comment above each key in a
+// values.yaml, keyed by the path to the key
+func valuesComments(data []byte) map[string]string {
+	comments := make(map[string]string)
+
+	type level struct {
+		indent int
+		key    string
+	}
+
+	stack := make([]level, 0)
+	comment := ""
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if match := commentLinePattern.FindStringSubmatch(line); match != nil {
+			text := strings.TrimSpace(paramPattern.ReplaceAllString(match[1], ""))
+
+			if comment == "" {
+				comment = text
+			}
+
+			continue
+		}
+
+		match := keyLinePattern.FindStringSubmatch(line)
+
+		if match == nil {
+			// blank lines and list items separate comments from the keys below
+			comment = ""
+			continue
+		}
+
+		indent := len(match[1])
+		key := strings.Trim(match[2], `"'`)
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		path := key
+
+		if len(stack) > 0 {
+			path = stack[len(stack)-1].key + "." + key
+		}
+
+		if comment != "" {
+			comments[path] = comment
+		}
+
+		stack = append(stack, level{indent, path})
+		comment = ""
+	}
+
+	return comments
+}
+
+// toMapSlice converts values to a MapSlice, ordered by key
+func toMapSlice(values map[string]interface{}) yamlv2.MapSlice {
+	res := yamlv2.MapSlice{}
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := values[key]
+
+		if nested, ok := val.(map[string]interface{}); ok {
+			val = toMapSlice(nested)
+		}
+
+		res = append(res, yamlv2.MapItem{Key: key, Value: val})
+	}
+
+	return res
+}
+
+func heading(key, label string) *models.FormContent {
+	if label == "" {
+		label = humanize(key)
+	}
+
+	return &models.FormContent{
+		Type:  "heading",
+		Label: label,
+	}
+}
+
+// nonEmptySections removes the sections that have no contents other than their
+// heading
+func nonEmptySections(sections []*models.FormSection) []*models.FormSection {
+	res := make([]*models.FormSection, 0)
+
+	for _, section := range sections {
+		for _, content := range section.Contents {
+			if content.Variable != "" {
+				res = append(res, section)
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+var wordBoundaryPattern = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// humanize turns a key such as replicaCount or image_tag into a label such as
+// Replica Count
+func humanize(key string) string {
+	key = wordBoundaryPattern.ReplaceAllString(key, "$1 $2")
+	key = strings.NewReplacer("_", " ", "-", " ").Replace(key)
+
+	words := strings.Fields(key)
+
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+func toLabel(val interface{}) string {
+	if str, ok := val.(string); ok {
+		return str
+	}
+
+	bytes, _ := json.Marshal(val)
+
+	return string(bytes)
+}
+
+func sortedKeys(props map[string]*jsonSchema) []string {
+	keys := make([]string, 0, len(props))
+
+	for key := range props {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// isVariableKey returns whether a key of the values can be part of the dot-separated
+// path of a variable. Keys with dots, such as the app.kubernetes.io/name label in a
+// map of labels, would be split into several keys when the variable is written.
+func isVariableKey(key string) bool {
+	return key != "" && !strings.Contains(key, ".")
+}
+
+func contains(list []string, item string) bool {
+	for _, candidate := range list {
+		if candidate == item {
+			return true
+		}
+	}
+
+	return false
+}
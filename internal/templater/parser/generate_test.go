@@ -0,0 +1,234 @@
+package parser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const valuesYAML = `# Number of replicas to run
+replicaCount: 1
+
+## Container image
+image:
+  # Image repository
+  repository: nginx
+  ## @param image.tag Image tag to deploy
+  tag: "1.19"
+  pullPolicy: IfNotPresent
+
+ingress:
+  enabled: false
+  hosts:
+  - example.com
+  annotations:
+    kubernetes.io/ingress.class: nginx
+
+resources: {}
+`
+
+func TestGenerateFormYAMLFromValues(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web", Description: "A web server"},
+		Raw: []*chart.File{
+			&chart.File{Name: "values.yaml", Data: []byte(valuesYAML)},
+		},
+		Values: map[string]interface{}{
+			"replicaCount": 1,
+		},
+	}
+
+	form, err := parser.GenerateFormYAML(ch)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !form.Generated || form.Name != "web" || form.Description != "A web server" {
+		t.Errorf("wrong form metadata: got %v %s %s", form.Generated, form.Name, form.Description)
+	}
+
+	sections := form.Tabs[0].Sections
+
+	// values that are lists or empty objects, and keys with dots that cannot be
+	// part of a variable, are left out of the form
+	expSections := []string{"general", "image", "ingress"}
+
+	if names := sectionNames(sections); !reflect.DeepEqual(names, expSections) {
+		t.Fatalf("wrong sections: expected %v, got %v", expSections, names)
+	}
+
+	expContents := [][]string{
+		{"number-input:Number of replicas to run:replicaCount:1"},
+		{
+			"heading:Container image::<nil>",
+			"string-input:Image repository:image.repository:nginx",
+			"string-input:Image tag to deploy:image.tag:1.19",
+			"string-input:Pull Policy:image.pullPolicy:IfNotPresent",
+		},
+		{
+			"heading:Ingress::<nil>",
+			"checkbox:Enabled:ingress.enabled:false",
+		},
+	}
+
+	for i, section := range sections {
+		if contents := contentSummaries(section.Contents); !reflect.DeepEqual(contents, expContents[i]) {
+			t.Errorf("wrong contents of %s: expected %v, got %v", section.Name, expContents[i], contents)
+		}
+	}
+}
+
+const valuesSchemaJSON = `{
+  "$schema": "http://json-schema.org/schema#",
+  "type": "object",
+  "required": ["replicaCount"],
+  "properties": {
+    "replicaCount": {
+      "type": "integer",
+      "title": "Replicas",
+      "minimum": 1,
+      "default": 1
+    },
+    "service": {
+      "type": "object",
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "enum": ["ClusterIP", "LoadBalancer"]
+        },
+        "name": {
+          "type": ["string", "null"],
+          "pattern": "^[a-z-]+$",
+          "maxLength": 63
+        }
+      }
+    }
+  }
+}`
+
+func TestGenerateFormYAMLFromSchema(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Schema:   []byte(valuesSchemaJSON),
+		Values: map[string]interface{}{
+			"replicaCount": 1,
+		},
+	}
+
+	form, err := parser.GenerateFormYAML(ch)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	sections := form.Tabs[0].Sections
+
+	if names := sectionNames(sections); !reflect.DeepEqual(names, []string{"general", "service"}) {
+		t.Fatalf("wrong sections: got %v", names)
+	}
+
+	replicas := sections[0].Contents[0]
+
+	if replicas.Type != "number-input" || replicas.Label != "Replicas" || !replicas.Settings.Required ||
+		replicas.Settings.Min == nil || *replicas.Settings.Min != 1 {
+		t.Errorf("wrong replicas content: got %v", replicas)
+	}
+
+	name := sections[1].Contents[1]
+
+	if name.Variable != "service.name" || name.Type != "string-input" || name.Settings.Required ||
+		name.Settings.Pattern != "^[a-z-]+$" || name.Settings.MaxLength == nil || *name.Settings.MaxLength != 63 {
+		t.Errorf("wrong name content: got %v", name)
+	}
+
+	serviceType := sections[1].Contents[2]
+	expOptions := []*models.FormOption{
+		&models.FormOption{Label: "ClusterIP", Value: "ClusterIP"},
+		&models.FormOption{Label: "LoadBalancer", Value: "LoadBalancer"},
+	}
+
+	if serviceType.Type != "select" || !serviceType.Settings.Required ||
+		!reflect.DeepEqual(serviceType.Settings.Options, expOptions) {
+		t.Errorf("wrong service type content: got %v", serviceType)
+	}
+}
+
+func TestFormBytesFromChart(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Values: map[string]interface{}{
+			"replicaCount": 1,
+		},
+	}
+
+	formBytes, err := parser.FormBytesFromChart(ch)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the generated form is read like any other form, with the chart's values
+	form, err := parser.FormYAMLFromBytes(&parser.ClientConfigDefault{HelmChart: ch}, formBytes)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	content := form.Tabs[0].Sections[0].Contents[0]
+
+	if !form.Generated || content.Variable != "replicaCount" {
+		t.Errorf("wrong generated form: got %v", content)
+	}
+
+	if expVal := []interface{}{1}; !reflect.DeepEqual(content.Value, expVal) {
+		t.Errorf("wrong value: expected %v, got %v", expVal, content.Value)
+	}
+
+	// a form.yaml overrides the generated form
+	ch.Files = []*chart.File{
+		&chart.File{Name: "form.yaml", Data: []byte("name: custom\n")},
+	}
+
+	formBytes, err = parser.FormBytesFromChart(ch)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(formBytes) != "name: custom\n" {
+		t.Errorf("expected form.yaml, got %s", formBytes)
+	}
+
+	// charts without values have no form
+	formBytes, err = parser.FormBytesFromChart(&chart.Chart{})
+
+	if err != nil || formBytes != nil {
+		t.Errorf("expected no form, got %s %v", formBytes, err)
+	}
+}
+
+func sectionNames(sections []*models.FormSection) []string {
+	names := make([]string, 0)
+
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+
+	return names
+}
+
+func contentSummaries(contents []*models.FormContent) []string {
+	res := make([]string, 0)
+
+	for _, content := range contents {
+		res = append(res, content.Type+":"+content.Label+":"+content.Variable+":"+fmt.Sprint(content.Settings.Default))
+	}
+
+	return res
+}
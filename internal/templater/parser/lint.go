@@ -0,0 +1,362 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/expr"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// contextTypes are the context types that formContextToContextConfig can configure
+var contextTypes = []string{
+	"helm/values",
+	"helm/manifests",
+	"cluster",
+	"cluster/secret",
+	"cluster/metadata",
+	"manifests",
+	"registry",
+}
+
+// LintError is a mistake in a form.yaml
+type LintError struct {
+	// Line is the line of the form.yaml that the mistake is on, or 0 if the
+	// line is not known
+	Line int
+
+	// Key is the path in the form of the field with the mistake, such as
+	// tabs[0].sections[1].contents[2].variable
+	Key     string
+	Message string
+}
+
+func (e *LintError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Key, e.Message)
+}
+
+// LintErrors are the mistakes in a form.yaml, ordered by line
+type LintErrors []*LintError
+
+func (errs LintErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// LintFormYAML checks a form.yaml against the values of its chart, which are the
+// mistakes that reading the form would otherwise skip. The form is parsed the same
+// way that forms are read, and the following are checked:
+//
+//   - the type and config of every context
+//   - every value query, which must parse, and must match the chart's values if the
+//     content reads from the values of the release
+//   - every variable that reads from the values of the release, which must be in the
+//     chart's values
+//   - every show_if and compute expression, which must parse, and the variables of
+//     which must be in the chart's values or be a variable of the form
+//   - the pattern of every content
+//
+// An error is returned if the form cannot be parsed at all.
+func LintFormYAML(ch *chart.Chart, bytes []byte) (LintErrors, error) {
+	form, err := unqueriedFormYAMLFromBytes(bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{
+		lines:   formLines(bytes),
+		values:  ch.Values,
+		formVar: make(map[string]bool),
+		linted:  make(map[*models.FormContext]bool),
+		errs:    make(LintErrors, 0),
+	}
+
+	if l.values == nil {
+		l.values = map[string]interface{}{}
+	}
+
+	for _, tab := range form.Tabs {
+		for _, section := range tab.Sections {
+			for _, content := range section.Contents {
+				if content.Variable != "" && isReleaseValuesContext(content.Context) {
+					l.formVar[content.Variable] = true
+				}
+			}
+		}
+	}
+
+	for i, tab := range form.Tabs {
+		tabKey := fmt.Sprintf("tabs[%d]", i)
+
+		l.lintContext(tabKey, tab.Context)
+
+		for j, section := range tab.Sections {
+			sectionKey := fmt.Sprintf("%s.sections[%d]", tabKey, j)
+
+			l.lintContext(sectionKey, section.Context)
+
+			if section.ShowIf != "" {
+				l.lintExpression(sectionKey+".show_if", section.ShowIf)
+			}
+
+			for k, content := range section.Contents {
+				l.lintContent(fmt.Sprintf("%s.contents[%d]", sectionKey, k), content)
+			}
+		}
+	}
+
+	sort.SliceStable(l.errs, func(i, j int) bool {
+		return l.errs[i].Line < l.errs[j].Line
+	})
+
+	return l.errs, nil
+}
+
+type linter struct {
+	lines  map[string]int
+	values map[string]interface{}
+
+	// formVar are the variables of the form that are written to the values of
+	// the release, which expressions may refer to
+	formVar map[string]bool
+
+	// linted are the contexts that have been linted, since the contexts of
+	// tabs and sections are shared with their contents
+	linted map[*models.FormContext]bool
+
+	errs LintErrors
+}
+
+func (l *linter) errorf(key, format string, args ...interface{}) {
+	l.errs = append(l.errs, &LintError{
+		Line:    l.line(key),
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// line returns the line of the key, or of the closest parent of the key that has a
+// line
+func (l *linter) line(key string) int {
+	for key != "" {
+		if line, ok := l.lines[key]; ok {
+			return line
+		}
+
+		if i := strings.LastIndexAny(key, ".["); i >= 0 {
+			key = key[:i]
+		} else {
+			key = ""
+		}
+	}
+
+	return 0
+}
+
+func (l *linter) lintContext(key string, context *models.FormContext) {
+	if context == nil {
+		return
+	}
+
+	if l.linted[context] {
+		return
+	}
+
+	l.linted[context] = true
+	key = key + ".context"
+
+	if !contains(contextTypes, context.Type) {
+		l.errorf(key+".type", "unknown context type %q, must be one of %s", context.Type, strings.Join(contextTypes, ", "))
+		return
+	}
+
+	for name, val := range context.Config {
+		if _, err := template.New(name).Parse(val); err != nil {
+			l.errorf(key+".config."+name, "invalid template: %v", err)
+		}
+	}
+
+	config := context.Config
+
+	switch context.Type {
+	case "helm/values", "helm/manifests":
+		if rev := config["Revision"]; rev != "" && !strings.Contains(rev, "{{") {
+			if _, err := strconv.Atoi(rev); err != nil {
+				l.errorf(key+".config.Revision", "revision %q is not a number", rev)
+			}
+		}
+	case "cluster":
+		for _, name := range []string{"Version", "Resource"} {
+			if config[name] == "" {
+				l.errorf(key+".config", "cluster context must set %s", name)
+			}
+		}
+	case "cluster/secret":
+		if config["Name"] == "" {
+			l.errorf(key+".config", "cluster/secret context must set Name")
+		}
+	}
+}
+
+func (l *linter) lintContent(key string, content *models.FormContent) {
+	l.lintContext(key, content.Context)
+
+	readsValues := isReleaseValuesContext(content.Context)
+
+	if content.Value != nil && content.Value != "" {
+		query, err := utils.NewQuery(key, fmt.Sprintf("%v", content.Value))
+
+		if err != nil {
+			l.errorf(key+".value", "invalid query: %v", err)
+		} else if readsValues && content.Compute == "" {
+			res, err := utils.QueryValues(l.values, []*templater.TemplateReaderQuery{query})
+			matches, _ := res[key].([]interface{})
+
+			if err != nil || len(matches) == 0 {
+				l.errorf(key+".value", "query %v matches nothing in the chart's values", content.Value)
+			}
+		}
+	}
+
+	if content.Variable != "" {
+		if _, err := utils.NewQuery(key, fmt.Sprintf("{ .%v }", content.Variable)); err != nil ||
+			strings.Contains(content.Variable, "..") {
+			l.errorf(key+".variable", "invalid variable %q", content.Variable)
+		} else if readsValues && content.Compute == "" {
+			if _, ok := lookupValue(l.values, content.Variable); !ok {
+				l.errorf(key+".variable", "variable %s is not in the chart's values", content.Variable)
+			}
+		}
+	}
+
+	if content.Compute != "" {
+		if content.Variable == "" {
+			l.errorf(key+".compute", "compute must set a variable")
+		}
+
+		l.lintExpression(key+".compute", content.Compute)
+	}
+
+	if from := content.Settings.OptionsFrom; from != nil {
+		l.lintContext(key+".settings.options_from", from.Context)
+
+		if _, err := utils.NewQuery(key, from.Query); err != nil || from.Query == "" {
+			l.errorf(key+".settings.options_from.query", "invalid query %q", from.Query)
+		}
+	}
+
+	if content.Settings.Pattern != "" {
+		if _, err := regexp.Compile(content.Settings.Pattern); err != nil {
+			l.errorf(key+".settings.pattern", "invalid pattern: %v", err)
+		}
+	}
+}
+
+func (l *linter) lintExpression(key, s string) {
+	e, err := expr.Parse(s)
+
+	if err != nil {
+		l.errorf(key, "invalid expression: %v", err)
+		return
+	}
+
+	for _, variable := range e.Variables() {
+		if _, ok := lookupValue(l.values, variable); !ok && !l.formVar[variable] {
+			l.errorf(key, "%s is not in the chart's values or the form", variable)
+		}
+	}
+}
+
+var (
+	lineKeyPattern  = regexp.MustCompile(`^([A-Za-z0-9_.\-]+|"[^"]+"|'[^']+')\s*:(\s|$)`)
+	lineItemPattern = regexp.MustCompile(`^-(\s+|$)`)
+)
+
+// formLines maps the keys of a form.yaml, such as tabs[0].sections[1].name, to the
+// lines they are on. Only the block style of YAML is mapped, so keys in flow style
+// mappings and lists fall back to the line of their parent.
+func formLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+
+	type frame struct {
+		indent int
+		path   string
+		item   bool
+	}
+
+	stack := make([]frame, 0)
+	items := make(map[string]int)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// a list item starts a frame for the item, and may hold the first key of
+		// the item on the same line
+		for lineItemPattern.MatchString(trimmed) {
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent ||
+				stack[len(stack)-1].indent == indent && stack[len(stack)-1].item) {
+				stack = stack[:len(stack)-1]
+			}
+
+			parent := ""
+
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1].path
+			}
+
+			path := fmt.Sprintf("%s[%d]", parent, items[parent])
+			items[parent]++
+			lines[path] = i + 1
+
+			stack = append(stack, frame{indent, path, true})
+
+			match := lineItemPattern.FindString(trimmed)
+			indent += len(match)
+			trimmed = trimmed[len(match):]
+		}
+
+		match := lineKeyPattern.FindStringSubmatch(trimmed)
+
+		if match == nil {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		path := strings.Trim(match[1], `"'`)
+
+		if len(stack) > 0 {
+			path = stack[len(stack)-1].path + "." + path
+		}
+
+		lines[path] = i + 1
+
+		stack = append(stack, frame{indent, path, false})
+	}
+
+	return lines
+}
@@ -0,0 +1,112 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const lintFormYAML = `name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: image
+    contents:
+    - type: string-input
+      label: Image Tag
+      variable: image.tag
+      settings:
+        pattern: "[0-9"
+    - type: string-input
+      label: Image Digest
+      variable: image.digest
+    - type: heading
+      label: Image
+      variable: image.full
+      compute: image.repository + ":" + image.tag
+  - name: ingress
+    show_if: ingress.enabled == true && ingress.missing
+    contents:
+    - type: string-input
+      label: Host
+      value: "{ .ingress.hosts[0] }"
+    - type: string-input
+      label: Path
+      value: "{ .ingress.paths"
+- name: status
+  label: Status
+  context:
+    type: cluster
+    config:
+      Resource: deployments
+  sections:
+  - name: replicas
+    contents:
+    - type: heading
+      label: Ready
+      value: "{ .status.readyReplicas }"
+    - type: heading
+      label: Pods
+      context:
+        type: helm/pods
+`
+
+func TestLintFormYAML(t *testing.T) {
+	ch := &chart.Chart{
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "1.19",
+			},
+			"ingress": map[string]interface{}{
+				"enabled": false,
+				"hosts":   []interface{}{},
+			},
+		},
+	}
+
+	errs, err := parser.LintFormYAML(ch, []byte(lintFormYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expErrs := []string{
+		"line 12: tabs[0].sections[0].contents[0].settings.pattern: invalid pattern: error parsing regexp: missing closing ]: `[0-9`",
+		"line 15: tabs[0].sections[0].contents[1].variable: variable image.digest is not in the chart's values",
+		"line 21: tabs[0].sections[1].show_if: ingress.missing is not in the chart's values or the form",
+		"line 25: tabs[0].sections[1].contents[0].value: query { .ingress.hosts[0] } matches nothing in the chart's values",
+		"line 28: tabs[0].sections[1].contents[1].value: invalid query: unclosed action",
+		"line 33: tabs[1].context.config: cluster context must set Version",
+		"line 44: tabs[1].sections[0].contents[1].context.type: unknown context type \"helm/pods\"",
+	}
+
+	gotErrs := make([]string, 0)
+
+	for _, err := range errs {
+		msg := err.Error()
+
+		// only the unknown context type is checked, not the list of known types
+		if i := strings.Index(msg, ", must be one of"); i != -1 {
+			msg = msg[:i]
+		}
+
+		gotErrs = append(gotErrs, msg)
+	}
+
+	if !reflect.DeepEqual(gotErrs, expErrs) {
+		t.Errorf("wrong errors:\nexpected %q\ngot      %q", expErrs, gotErrs)
+	}
+}
+
+func TestLintFormYAMLInvalidYAML(t *testing.T) {
+	_, err := parser.LintFormYAML(&chart.Chart{}, []byte("tabs:\n- name: main\n  sections: {\n"))
+
+	if err == nil {
+		t.Errorf("expected error for invalid form")
+	}
+}
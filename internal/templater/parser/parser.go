@@ -2,18 +2,27 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+	"github.com/porter-dev/porter/internal/repository"
 	"github.com/porter-dev/porter/internal/templater"
 	"github.com/porter-dev/porter/internal/templater/utils"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/dynamic"
 	"sigs.k8s.io/yaml"
 
 	td "github.com/porter-dev/porter/internal/templater/dynamic"
 	th "github.com/porter-dev/porter/internal/templater/helm"
+	tm "github.com/porter-dev/porter/internal/templater/manifests"
+	tr "github.com/porter-dev/porter/internal/templater/registry"
 )
 
 // TODO -- handle all continue statements, errors should at least be logged if not
@@ -22,9 +31,23 @@ import (
 type ClientConfigDefault struct {
 	DynamicClient dynamic.Interface
 
+	// Informers are the shared informers used to stream live cluster values,
+	// which may be nil
+	Informers informer.Subscriber
+
 	HelmAgent   *helm.Agent
 	HelmRelease *release.Release
 	HelmChart   *chart.Chart
+
+	// Repo and ProjectID are used to read the registries connected to the
+	// project, and may be unset if the form is not read for a project
+	Repo      *repository.Repository
+	ProjectID uint
+
+	// ManifestRelease is the release of raw manifests or Kustomize that the form
+	// is read for, which is written to with the RESTMapper and DynamicClient
+	ManifestRelease *models.ManifestRelease
+	RESTMapper      meta.RESTMapper
 }
 
 func FormYAMLFromBytes(def *ClientConfigDefault, bytes []byte) (*models.FormYAML, error) {
@@ -59,13 +82,239 @@ func FormYAMLFromBytes(def *ClientConfigDefault, bytes []byte) (*models.FormYAML
 				if val, ok := data[key]; ok {
 					content.Value = val
 				}
+
+				if val, ok := data[key+".options"]; ok {
+					content.Settings.Options = toOptions(val)
+				}
 			}
 		}
 	}
 
+	evalFormYAMLExpressions(def, form)
+
 	return form, nil
 }
 
+// StreamFormYAMLValues subscribes to every live context of the form, and calls on
+// whenever the values of the contents in a context change. The data passed to on
+// has a "kind" of create, update or delete, and the "data" of the updated values,
+// keyed by the tabs[i].sections[j].contents[k] path of each content. The streams
+// are closed when stopCh is closed.
+func StreamFormYAMLValues(
+	def *ClientConfigDefault,
+	bytes []byte,
+	on templater.OnDataStream,
+	stopCh <-chan struct{},
+) error {
+	form, err := unqueriedFormYAMLFromBytes(bytes)
+
+	if err != nil {
+		return err
+	}
+
+	lookup := formToLookupTable(def, form)
+
+	for _, lookupVal := range lookup {
+		if lookupVal.FromType != "live" {
+			continue
+		}
+
+		if err := lookupVal.TemplateReader.ReadStream(on, stopCh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FormWriteResult is the result of writing the submitted values of a form to one
+// of its contexts
+type FormWriteResult struct {
+	Context   *models.FormContext `json:"context"`
+	Variables []string            `json:"variables"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// WriteFormYAMLValues writes the submitted values, keyed by the variable of each form
+// content, to the contexts of the contents. The variables are dot-separated paths,
+// such as image.tag, into the values of the context. The values written to the
+// release of the form are merged with its values and validated like a deploy: the
+// computed contents are recomputed and written along with them, and the contents of
+// hidden sections are not validated. A result is returned for each context that is
+// written to, with the error of the write if it failed. An error is returned without
+// writing anything if a variable is not in the form or is computed, or if the values
+// fail the validation rules of their contents, in which case the error is the
+// FieldErrors of the values.
+func WriteFormYAMLValues(
+	def *ClientConfigDefault,
+	bytes []byte,
+	vals map[string]interface{},
+) ([]*FormWriteResult, error) {
+	form, err := unqueriedFormYAMLFromBytes(bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var relVals, defaults map[string]interface{}
+
+	if def.HelmRelease != nil {
+		relVals = utils.CopyValues(def.HelmRelease.Config)
+
+		if def.HelmRelease.Chart != nil {
+			defaults = def.HelmRelease.Chart.Values
+		}
+	} else {
+		relVals = make(map[string]interface{})
+
+		if def.HelmChart != nil {
+			defaults = def.HelmChart.Values
+		}
+	}
+
+	// merge the values submitted for the release of the form into its values
+	known := make(map[string]bool)
+	relChanged := false
+
+	for _, tab := range form.Tabs {
+		for _, section := range tab.Sections {
+			for _, content := range section.Contents {
+				if content.Variable == "" {
+					continue
+				}
+
+				known[content.Variable] = true
+
+				val, ok := vals[content.Variable]
+
+				if !ok {
+					continue
+				}
+
+				if isComputedContent(content) {
+					return nil, fmt.Errorf("variable %s is computed and cannot be set", content.Variable)
+				}
+
+				if isReleaseValuesContext(content.Context) {
+					utils.SetValue(relVals, content.Variable, val)
+					relChanged = true
+				}
+			}
+		}
+	}
+
+	for variable := range vals {
+		if !known[variable] {
+			return nil, fmt.Errorf("variable %s is not in the form", variable)
+		}
+	}
+
+	fieldErrs := make(FieldErrors, 0)
+
+	if relChanged {
+		if err := ComputeFormYAMLValues(bytes, relVals, defaults); err != nil {
+			return nil, err
+		}
+
+		fieldErrs, err = ValidateFormYAMLValues(bytes, relVals, defaults)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lookup := formToLookupTable(def, form)
+	resolve := valuesResolver(relVals, defaults)
+
+	// the values to write to each context, with the results in the order that
+	// the contexts appear in the form
+	results := make([]*FormWriteResult, 0)
+	byContext := make(map[*models.FormContext]*FormWriteResult)
+	ctxVals := make(map[*models.FormContext]map[string]interface{})
+	written := make(map[string]bool)
+
+	for i, tab := range form.Tabs {
+		for j, section := range tab.Sections {
+			shown, err := sectionShown(section, resolve)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for k, content := range section.Contents {
+				if content.Variable == "" || written[content.Variable] {
+					continue
+				}
+
+				val, ok := vals[content.Variable]
+
+				if isReleaseValuesContext(content.Context) {
+					// the release's values were validated above, and its computed
+					// values are written whenever its values change
+					if ok || (relChanged && shown && isComputedContent(content)) {
+						val, ok = lookupValue(relVals, content.Variable)
+					}
+				} else if ok && shown {
+					msg, err := validateContent(content, val, true)
+
+					if err != nil {
+						return nil, err
+					}
+
+					if msg != "" {
+						fieldErrs = append(fieldErrs, &FieldError{
+							Key:      fmt.Sprintf("tabs[%d].sections[%d].contents[%d]", i, j, k),
+							Variable: content.Variable,
+							Message:  msg,
+						})
+					}
+				}
+
+				if !ok {
+					continue
+				}
+
+				written[content.Variable] = true
+
+				res, ok := byContext[content.Context]
+
+				if !ok {
+					res = &FormWriteResult{
+						Context:   content.Context,
+						Variables: []string{},
+					}
+
+					byContext[content.Context] = res
+					ctxVals[content.Context] = make(map[string]interface{})
+					results = append(results, res)
+				}
+
+				res.Variables = append(res.Variables, content.Variable)
+				utils.SetValue(ctxVals[content.Context], content.Variable, val)
+			}
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, fieldErrs
+	}
+
+	for _, res := range results {
+		config, ok := lookup[res.Context]
+
+		if !ok || config.TemplateWriter == nil {
+			res.Error = fmt.Sprintf("context %s cannot be written to", res.Context.Type)
+			continue
+		}
+
+		if _, err := config.TemplateWriter.Update(ctxVals[res.Context]); err != nil {
+			res.Error = err.Error()
+		}
+	}
+
+	return results, nil
+}
+
 // unqueriedFormYAMLFromBytes returns a FormYAML without values queries populated
 func unqueriedFormYAMLFromBytes(bytes []byte) (*models.FormYAML, error) {
 	// parse bytes into object
@@ -96,6 +345,10 @@ func unqueriedFormYAMLFromBytes(bytes []byte) (*models.FormYAML, error) {
 				if content.Context == nil {
 					content.Context = section.Context
 				}
+
+				if from := content.Settings.OptionsFrom; from != nil && from.Context == nil {
+					from.Context = content.Context
+				}
 			}
 		}
 	}
@@ -118,21 +371,26 @@ func formToLookupTable(def *ClientConfigDefault, form *models.FormYAML) map[*mod
 	for i, tab := range form.Tabs {
 		for j, section := range tab.Sections {
 			for k, content := range section.Contents {
-				if content.Context == nil {
-					continue
+				key := fmt.Sprintf("tabs[%d].sections[%d].contents[%d]", i, j, k)
+
+				if from := content.Settings.OptionsFrom; from != nil && from.Query != "" {
+					if config := lookupContextConfig(def, lookup, from.Context); config != nil {
+						if query, err := utils.NewQuery(key+".options", from.Query); err == nil {
+							config.TemplateReader.RegisterQuery(query)
+						}
+					}
 				}
 
-				if _, ok := lookup[content.Context]; !ok {
-					lookup[content.Context] = formContextToContextConfig(def, content.Context)
+				// the context type is unknown, or cannot be read with the default
+				// config
+				if lookupContextConfig(def, lookup, content.Context) == nil {
+					continue
 				}
 
-				if content.Value != "" {
+				if content.Value != nil && content.Value != "" {
 					// TODO -- case on whether value is proper query string, if not resolve it to a
 					// proper query string
-					query, err := utils.NewQuery(
-						fmt.Sprintf("tabs[%d].sections[%d].contents[%d]", i, j, k),
-						fmt.Sprintf("%v", content.Value),
-					)
+					query, err := utils.NewQuery(key, fmt.Sprintf("%v", content.Value))
 
 					if err != nil {
 						continue
@@ -142,10 +400,7 @@ func formToLookupTable(def *ClientConfigDefault, form *models.FormYAML) map[*mod
 				} else if content.Variable != "" {
 					// if variable field set without value field set, make variable field into jsonpath
 					// query
-					query, err := utils.NewQuery(
-						fmt.Sprintf("tabs[%d].sections[%d].contents[%d]", i, j, k),
-						fmt.Sprintf("{ .%v }", content.Variable),
-					)
+					query, err := utils.NewQuery(key, fmt.Sprintf("{ .%v }", content.Variable))
 
 					if err != nil {
 						continue
@@ -160,27 +415,141 @@ func formToLookupTable(def *ClientConfigDefault, form *models.FormYAML) map[*mod
 	return lookup
 }
 
-// TODO -- this needs to be able to construct new context configs based on
-// configuration for each context, but right now just uses the default config
-// for everything
+// lookupContextConfig returns the config of a context from the lookup table, which
+// is constructed and added to the table the first time the context is looked up.
+// Returns nil if the context cannot be configured.
+func lookupContextConfig(
+	def *ClientConfigDefault,
+	lookup map[*models.FormContext]*ContextConfig,
+	context *models.FormContext,
+) *ContextConfig {
+	if context == nil {
+		return nil
+	}
+
+	if config, ok := lookup[context]; ok {
+		return config
+	}
+
+	config := formContextToContextConfig(def, context)
+
+	if config != nil {
+		lookup[context] = config
+	}
+
+	return config
+}
+
+// toOptions converts the values matched by an options query to the options of a
+// content. Lists are flattened, and maps with a label and value, or a name, are
+// converted to an option with that label and value.
+func toOptions(val interface{}) []*models.FormOption {
+	res := make([]*models.FormOption, 0)
+
+	vals, ok := val.([]interface{})
+
+	if !ok {
+		vals = []interface{}{val}
+	}
+
+	for _, item := range vals {
+		switch v := item.(type) {
+		case []interface{}:
+			res = append(res, toOptions(v)...)
+		case map[string]interface{}:
+			if name, ok := v["name"]; ok {
+				res = append(res, &models.FormOption{Label: fmt.Sprintf("%v", name), Value: name})
+			} else if value, ok := v["value"]; ok {
+				label, ok := v["label"]
+
+				if !ok {
+					label = value
+				}
+
+				res = append(res, &models.FormOption{Label: fmt.Sprintf("%v", label), Value: value})
+			}
+		case nil:
+		default:
+			res = append(res, &models.FormOption{Label: fmt.Sprintf("%v", v), Value: v})
+		}
+	}
+
+	return res
+}
+
+// formContextToContextConfig constructs the context config from the configuration of
+// the context, which falls back to the default config. The configuration values are
+// templates, which are executed with the release of the default config, for example
+// {{ .Release.Namespace }}. Returns nil if the context type is unknown, or if the
+// context cannot be configured.
+//
+// The helm/values and helm/manifests contexts read from the release of the default
+// config, unless the Name config names another release or the Revision config sets
+// another revision. The latest revision of another release is read by default.
+//
+// The cluster context reads the resource identified by the Group, Version and Resource
+// config. If Name is set, the context reads that object. Otherwise the context reads
+// the list of objects that match the LabelSelector and FieldSelector config. The
+// objects are read from the Namespace config, which defaults to the namespace of the
+// release, unless Scope is set to "cluster" for cluster-scoped resources or to read
+// from all namespaces.
+//
+// The cluster/secret context reads the keys of the secret named by the Name config,
+// with their values masked, from the Namespace config, which defaults to the
+// namespace of the release. The cluster/metadata context reads the namespaces,
+// storage classes and ingress classes of the cluster.
+//
+// The manifests context reads the objects of the release of raw manifests or
+// Kustomize of the default config, keyed by the kind and then the name of each
+// object, such as Deployment.web.spec.replicas. Values written to the context are
+// merged into the objects, which are applied to the cluster.
+//
+// The registry context reads the image repositories of the project's registry named
+// by the Registry config, which may be left unset if the project has one registry,
+// and the images and tags of the image repository named by the Repository config.
 func formContextToContextConfig(def *ClientConfigDefault, context *models.FormContext) *ContextConfig {
 	res := &ContextConfig{}
 
+	config, err := executeContextConfig(def, context.Config)
+
+	if err != nil {
+		return nil
+	}
+
 	switch context.Type {
 	case "helm/values":
+		rel, err := contextRelease(def, config)
+
+		if err != nil {
+			return nil
+		}
+
 		res.FromType = "declared"
 
-		res.Capabilities = []string{"read", "write"}
+		// a release other than the release of the form is read-only, so that
+		// submitting a form never upgrades another release
+		if rel != def.HelmRelease {
+			res.Capabilities = []string{"read"}
 
-		res.TemplateReader = &th.ValuesTemplateReader{
-			Release: def.HelmRelease,
-			Chart:   def.HelmChart,
+			res.TemplateReader = &th.ValuesTemplateReader{
+				Release: rel,
+				Chart:   rel.Chart,
+			}
+
+			break
 		}
 
+		res.Capabilities = []string{"read", "write"}
+
 		relName := ""
 
-		if def.HelmRelease != nil {
-			relName = def.HelmRelease.Name
+		if rel != nil {
+			relName = rel.Name
+		}
+
+		res.TemplateReader = &th.ValuesTemplateReader{
+			Release: rel,
+			Chart:   def.HelmChart,
 		}
 
 		res.TemplateWriter = &th.ValuesTemplateWriter{
@@ -189,31 +558,235 @@ func formContextToContextConfig(def *ClientConfigDefault, context *models.FormCo
 			ReleaseName: relName,
 		}
 	case "helm/manifests":
+		rel, err := contextRelease(def, config)
+
+		if err != nil {
+			return nil
+		}
+
 		res.FromType = "live"
 
 		res.Capabilities = []string{"read"}
 
 		res.TemplateReader = &th.ManifestsTemplateReader{
-			Release: def.HelmRelease,
+			Release: rel,
 		}
 	case "cluster":
+		if def.DynamicClient == nil {
+			return nil
+		}
+
 		res.FromType = "live"
 
 		res.Capabilities = []string{"read"}
 
+		namespace := contextNamespace(def, config)
+
+		if config["Scope"] == "cluster" {
+			namespace = ""
+		}
+
 		// identify object based on passed config
 		obj := &td.Object{
-			Group:     context.Config["Group"],
-			Version:   context.Config["Version"],
-			Resource:  context.Config["Resource"],
-			Namespace: context.Config["Namespace"],
-			Name:      context.Config["Name"],
+			Group:         config["Group"],
+			Version:       config["Version"],
+			Resource:      config["Resource"],
+			Namespace:     namespace,
+			Name:          config["Name"],
+			LabelSelector: config["LabelSelector"],
+			FieldSelector: config["FieldSelector"],
 		}
 
-		res.TemplateReader = td.NewDynamicTemplateReader(def.DynamicClient, obj)
+		res.TemplateReader = td.NewDynamicTemplateReader(def.DynamicClient, def.Informers, obj)
+
+		// a single object can be written back by patching it
+		if obj.Name != "" {
+			res.Capabilities = append(res.Capabilities, "write")
+			res.TemplateWriter = td.NewDynamicTemplateWriter(def.DynamicClient, obj, nil)
+		}
+	case "cluster/secret":
+		if def.DynamicClient == nil || config["Name"] == "" {
+			return nil
+		}
+
+		res.FromType = "declared"
+
+		res.Capabilities = []string{"read"}
+
+		res.TemplateReader = &td.SecretTemplateReader{
+			Client:    def.DynamicClient,
+			Namespace: contextNamespace(def, config),
+			Name:      config["Name"],
+		}
+	case "cluster/metadata":
+		if def.DynamicClient == nil {
+			return nil
+		}
+
+		res.FromType = "declared"
+
+		res.Capabilities = []string{"read"}
+
+		res.TemplateReader = &td.MetadataTemplateReader{
+			Client: def.DynamicClient,
+		}
+	case "manifests":
+		if def.ManifestRelease == nil {
+			return nil
+		}
+
+		res.FromType = "declared"
+
+		res.Capabilities = []string{"read"}
+
+		res.TemplateReader = &tm.ManifestsTemplateReader{
+			Release: def.ManifestRelease,
+		}
+
+		if def.Repo != nil && def.DynamicClient != nil && def.RESTMapper != nil {
+			res.Capabilities = append(res.Capabilities, "write")
+
+			res.TemplateWriter = &tm.ManifestsTemplateWriter{
+				Release: def.ManifestRelease,
+				Repo:    def.Repo.ManifestRelease,
+				Client:  def.DynamicClient,
+				Mapper:  def.RESTMapper,
+			}
+		}
+	case "registry":
+		reg, err := contextRegistry(def, config)
+
+		if err != nil {
+			return nil
+		}
+
+		res.FromType = "declared"
+
+		res.Capabilities = []string{"read"}
+
+		regAPI := registry.Registry(*reg)
+
+		res.TemplateReader = &tr.RegistryTemplateReader{
+			Registry:   &regAPI,
+			Repo:       *def.Repo,
+			Repository: config["Repository"],
+		}
 	default:
 		return nil
 	}
 
 	return res
 }
+
+// contextNamespace returns the Namespace config, which defaults to the namespace of
+// the release of the default config
+func contextNamespace(def *ClientConfigDefault, config map[string]string) string {
+	if namespace := config["Namespace"]; namespace != "" || def.HelmRelease == nil {
+		return namespace
+	}
+
+	return def.HelmRelease.Namespace
+}
+
+// contextRegistry returns the registry of the project that a registry context reads
+// from, which is the registry named by the Registry config, or the only registry of
+// the project if the config is not set
+func contextRegistry(def *ClientConfigDefault, config map[string]string) (*models.Registry, error) {
+	if def.Repo == nil || def.ProjectID == 0 {
+		return nil, fmt.Errorf("project must be set to read registries")
+	}
+
+	regs, err := def.Repo.Registry.ListRegistriesByProjectID(def.ProjectID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	name := config["Registry"]
+
+	if name == "" && len(regs) == 1 {
+		return regs[0], nil
+	}
+
+	for _, reg := range regs {
+		if name != "" && reg.Name == name {
+			return reg, nil
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("registry must be set, since the project has %d registries", len(regs))
+	}
+
+	return nil, fmt.Errorf("registry %s not found", name)
+}
+
+// executeContextConfig executes the values of a context's config as templates, with
+// the release of the default config
+func executeContextConfig(def *ClientConfigDefault, config map[string]string) (map[string]string, error) {
+	data := map[string]interface{}{}
+
+	if def.HelmRelease != nil {
+		data["Release"] = map[string]interface{}{
+			"Name":      def.HelmRelease.Name,
+			"Namespace": def.HelmRelease.Namespace,
+			"Revision":  def.HelmRelease.Version,
+		}
+	}
+
+	res := make(map[string]string)
+
+	for key, val := range config {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var buf strings.Builder
+
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+
+		res[key] = buf.String()
+	}
+
+	return res, nil
+}
+
+// contextRelease returns the release that a helm context reads from, which is the
+// default release unless the Name or Revision config is set
+func contextRelease(def *ClientConfigDefault, config map[string]string) (*release.Release, error) {
+	name := config["Name"]
+	revision := 0
+
+	if config["Revision"] != "" {
+		var err error
+
+		revision, err = strconv.Atoi(config["Revision"])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if def.HelmRelease != nil && (name == "" || name == def.HelmRelease.Name) &&
+		(revision == 0 || revision == def.HelmRelease.Version) {
+		return def.HelmRelease, nil
+	}
+
+	if name == "" && def.HelmRelease == nil {
+		return nil, nil
+	}
+
+	if name == "" {
+		name = def.HelmRelease.Name
+	}
+
+	if def.HelmAgent == nil {
+		return nil, fmt.Errorf("helm agent must be set to read release %s", name)
+	}
+
+	return def.HelmAgent.GetRelease(name, revision)
+}
@@ -0,0 +1,700 @@
+package parser_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+const liveFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: status
+    contents:
+    - type: string-input
+      label: Replicas
+      variable: replicaCount
+    - type: heading
+      label: Status
+      context:
+        type: cluster
+        config:
+          Version: v1
+          Resource: configmaps
+          Namespace: default
+          Name: web-status
+      value: "{ .data.phase }"
+`
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newConfigMap(name, phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"phase": phase,
+			},
+		},
+	}
+}
+
+func TestStreamFormYAMLValues(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newConfigMap("other", "failed"),
+		newConfigMap("web-status", "running"),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+	}
+
+	pkts := make(chan map[string]interface{}, 10)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	err := parser.StreamFormYAMLValues(def, []byte(liveFormYAML), func(val map[string]interface{}) error {
+		pkts <- val
+		return nil
+	}, stopCh)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	select {
+	case pkt := <-pkts:
+		if pkt["kind"] != "create" {
+			t.Errorf("wrong kind: expected create, got %v", pkt["kind"])
+		}
+
+		data, _ := pkt["data"].(map[string]interface{})
+
+		// only the live content is streamed, and only from the named object
+		if len(data) != 1 {
+			t.Fatalf("wrong number of values: expected 1, got %v", data)
+		}
+
+		expVal := []interface{}{"running"}
+
+		if val := data["tabs[0].sections[0].contents[1]"]; !reflect.DeepEqual(val, expVal) {
+			t.Errorf("wrong value: expected %v, got %v", expVal, val)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for form values")
+	}
+
+	select {
+	case pkt := <-pkts:
+		t.Errorf("unexpected form values: %v", pkt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+const writableFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  context:
+    type: cluster
+    config:
+      Version: v1
+      Resource: configmaps
+      Namespace: default
+      Name: web-status
+  sections:
+  - name: status
+    contents:
+    - type: string-input
+      label: Phase
+      variable: data.phase
+    - type: string-input
+      label: Reason
+      variable: data.reason
+  - name: manifests
+    context:
+      type: helm/manifests
+    contents:
+    - type: string-input
+      label: Kind
+      variable: kind
+`
+
+func TestWriteFormYAMLValues(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newConfigMap("web-status", "running"),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+	}
+
+	results, err := parser.WriteFormYAMLValues(def, []byte(writableFormYAML), map[string]interface{}{
+		"data.reason": "scaled down",
+		"kind":        "Deployment",
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("wrong number of results: expected 2, got %d", len(results))
+	}
+
+	if results[0].Context.Type != "cluster" || results[0].Error != "" {
+		t.Errorf("wrong cluster result: got %s %q", results[0].Context.Type, results[0].Error)
+	}
+
+	if !reflect.DeepEqual(results[0].Variables, []string{"data.reason"}) {
+		t.Errorf("wrong cluster variables: got %v", results[0].Variables)
+	}
+
+	// the manifests of a release are read-only
+	if results[1].Context.Type != "helm/manifests" || results[1].Error == "" {
+		t.Errorf("wrong manifests result: got %s %q", results[1].Context.Type, results[1].Error)
+	}
+
+	cm, err := client.Resource(configMapsGVR).Namespace("default").Get(
+		context.TODO(),
+		"web-status",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expData := map[string]interface{}{
+		"phase":  "running",
+		"reason": "scaled down",
+	}
+
+	if data := cm.Object["data"]; !reflect.DeepEqual(data, expData) {
+		t.Errorf("wrong data after write: expected %v, got %v", expData, data)
+	}
+}
+
+func TestWriteFormYAMLValuesUnknownVariable(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newConfigMap("web-status", "running"),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+	}
+
+	_, err := parser.WriteFormYAMLValues(def, []byte(writableFormYAML), map[string]interface{}{
+		"data.phase":   "failed",
+		"replicaCount": 2,
+	})
+
+	if err == nil {
+		t.Fatalf("expected error for variable not in the form")
+	}
+
+	cm, err := client.Resource(configMapsGVR).Namespace("default").Get(
+		context.TODO(),
+		"web-status",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// nothing is written if a variable is not in the form
+	if phase := cm.Object["data"].(map[string]interface{})["phase"]; phase != "running" {
+		t.Errorf("wrong phase: expected running, got %v", phase)
+	}
+}
+
+func newLabeledConfigMap(namespace, name, app string) *unstructured.Unstructured {
+	cm := newConfigMap(name, "running")
+	cm.SetNamespace(namespace)
+	cm.SetLabels(map[string]string{"app": app})
+
+	return cm
+}
+
+const selectorFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: status
+    contents:
+    - type: heading
+      label: Config Maps
+      context:
+        type: cluster
+        config:
+          Version: v1
+          Resource: configmaps
+          LabelSelector: "app={{ .Release.Name }}"
+      value: "{ .items[*].metadata.name }"
+    - type: heading
+      label: All Config Maps
+      context:
+        type: cluster
+        config:
+          Version: v1
+          Resource: configmaps
+          Scope: cluster
+          LabelSelector: "app={{ .Release.Name }}"
+      value: "{ .items[*].metadata.name }"
+`
+
+func TestFormYAMLFromBytesContextConfig(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newLabeledConfigMap("default", "web-a", "web"),
+		newLabeledConfigMap("other", "web-b", "web"),
+		newLabeledConfigMap("default", "db", "db"),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+		HelmRelease: &release.Release{
+			Name:      "web",
+			Namespace: "default",
+			Version:   1,
+		},
+	}
+
+	form, err := parser.FormYAMLFromBytes(def, []byte(selectorFormYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	contents := form.Tabs[0].Sections[0].Contents
+
+	// the namespace defaults to the namespace of the release
+	if expVal := []interface{}{"web-a"}; !reflect.DeepEqual(contents[0].Value, expVal) {
+		t.Errorf("wrong namespaced value: expected %v, got %v", expVal, contents[0].Value)
+	}
+
+	if expVal := []interface{}{"web-a", "web-b"}; !reflect.DeepEqual(contents[1].Value, expVal) {
+		t.Errorf("wrong cluster value: expected %v, got %v", expVal, contents[1].Value)
+	}
+}
+
+func TestStreamFormYAMLValuesList(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newLabeledConfigMap("default", "web-a", "web"),
+		newLabeledConfigMap("default", "db", "db"),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+		HelmRelease: &release.Release{
+			Name:      "web",
+			Namespace: "default",
+			Version:   1,
+		},
+	}
+
+	pkts := make(chan map[string]interface{}, 10)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	err := parser.StreamFormYAMLValues(def, []byte(selectorFormYAML), func(val map[string]interface{}) error {
+		pkts <- val
+		return nil
+	}, stopCh)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the packets of the cluster-scoped content are skipped
+	expValue := func(expVal []interface{}) {
+		t.Helper()
+
+		for {
+			select {
+			case pkt := <-pkts:
+				data, _ := pkt["data"].(map[string]interface{})
+				val, ok := data["tabs[0].sections[0].contents[0]"]
+
+				if !ok {
+					continue
+				}
+
+				if !reflect.DeepEqual(val, expVal) {
+					t.Errorf("wrong value: expected %v, got %v", expVal, val)
+				}
+
+				return
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for form values")
+			}
+		}
+	}
+
+	expValue([]interface{}{"web-a"})
+
+	_, err = client.Resource(configMapsGVR).Namespace("default").Create(
+		context.TODO(),
+		newLabeledConfigMap("default", "web-c", "web"),
+		metav1.CreateOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expValue([]interface{}{"web-a", "web-c"})
+}
+
+func TestFormYAMLFromBytesOtherRelease(t *testing.T) {
+	agent := helm.GetAgentTesting(&helm.Form{}, nil, logger.NewConsole(false))
+
+	agent.ActionConfig.Releases.Create(&release.Release{
+		Name:      "db",
+		Namespace: "default",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+		Config: map[string]interface{}{
+			"port": float64(5432),
+		},
+	})
+
+	def := &parser.ClientConfigDefault{
+		HelmAgent: agent,
+		HelmRelease: &release.Release{
+			Name:      "web",
+			Namespace: "default",
+			Version:   1,
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+		},
+	}
+
+	form, err := parser.FormYAMLFromBytes(def, []byte(`
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: db
+    context:
+      type: helm/values
+      config:
+        Name: db
+    contents:
+    - type: number-input
+      label: Port
+      variable: port
+`))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expVal := []interface{}{float64(5432)}
+
+	if val := form.Tabs[0].Sections[0].Contents[0].Value; !reflect.DeepEqual(val, expVal) {
+		t.Errorf("wrong value: expected %v, got %v", expVal, val)
+	}
+}
+
+func TestWriteFormYAMLValuesOtherRelease(t *testing.T) {
+	agent := helm.GetAgentTesting(&helm.Form{}, nil, logger.NewConsole(false))
+
+	agent.ActionConfig.Releases.Create(&release.Release{
+		Name:      "db",
+		Namespace: "default",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+		Config: map[string]interface{}{
+			"port": float64(5432),
+		},
+	})
+
+	def := &parser.ClientConfigDefault{
+		HelmAgent: agent,
+		HelmRelease: &release.Release{
+			Name:      "web",
+			Namespace: "default",
+			Version:   1,
+			Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+		},
+	}
+
+	results, err := parser.WriteFormYAMLValues(def, []byte(`
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: db
+    context:
+      type: helm/values
+      config:
+        Name: db
+    contents:
+    - type: number-input
+      label: Port
+      variable: port
+`), map[string]interface{}{
+		"port": 5433,
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the values of a release other than the form's release are read-only
+	if len(results) != 1 || results[0].Error == "" {
+		t.Fatalf("expected the write to the other release to fail, got %v", results)
+	}
+
+	rel, err := agent.GetRelease("db", 0)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if rel.Version != 1 || rel.Config["port"] != float64(5432) {
+		t.Errorf("expected db not to be upgraded, got version %d with %v", rel.Version, rel.Config)
+	}
+}
+
+func TestWriteFormYAMLValuesRelease(t *testing.T) {
+	agent := helm.GetAgentTesting(&helm.Form{}, nil, logger.NewConsole(false))
+
+	rel := &release.Release{
+		Name:      "web",
+		Namespace: "default",
+		Version:   1,
+		Info:      &release.Info{Status: release.StatusDeployed},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "web", Version: "1.0.0"},
+			Values:   expressionDefaults,
+		},
+		Config: map[string]interface{}{
+			"replicaCount": float64(2),
+		},
+	}
+
+	agent.ActionConfig.Releases.Create(rel)
+
+	def := &parser.ClientConfigDefault{
+		HelmAgent:   agent,
+		HelmRelease: rel,
+		HelmChart:   rel.Chart,
+	}
+
+	// computed values cannot be set by the client
+	_, err := parser.WriteFormYAMLValues(def, []byte(expressionFormYAML), map[string]interface{}{
+		"image.full": "nginx:latest",
+	})
+
+	if err == nil {
+		t.Fatalf("expected error for computed variable")
+	}
+
+	// the required host of the hidden ingress section is not enforced
+	results, err := parser.WriteFormYAMLValues(def, []byte(expressionFormYAML), map[string]interface{}{
+		"image.tag": "1.20",
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("expected a successful write to the release, got %v", results)
+	}
+
+	if !reflect.DeepEqual(results[0].Variables, []string{"image.tag", "image.full"}) {
+		t.Errorf("wrong release variables: got %v", results[0].Variables)
+	}
+
+	upgraded, err := agent.GetRelease("web", 0)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the submitted values are merged with the values of the release, and the
+	// computed image is recomputed from them
+	expConfig := map[string]interface{}{
+		"replicaCount": float64(2),
+		"image": map[string]interface{}{
+			"tag":  "1.20",
+			"full": "nginx:1.20",
+		},
+	}
+
+	if !reflect.DeepEqual(upgraded.Config, expConfig) {
+		t.Errorf("wrong release values: expected %v, got %v", expConfig, upgraded.Config)
+	}
+
+}
+
+const optionsFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: storage
+    contents:
+    - type: select
+      label: Namespace
+      variable: namespace
+      settings:
+        options_from:
+          context:
+            type: cluster/metadata
+          query: "{ .namespaces }"
+    - type: select
+      label: Storage Class
+      variable: persistence.storageClass
+      settings:
+        options_from:
+          context:
+            type: cluster/metadata
+          query: "{ .storageClasses }"
+    - type: heading
+      label: Default Storage Class
+      context:
+        type: cluster/metadata
+      value: "{ .defaultStorageClass }"
+    - type: select
+      label: Password Key
+      variable: auth.existingSecretKey
+      settings:
+        options_from:
+          context:
+            type: cluster/secret
+            config:
+              Name: "{{ .Release.Name }}-auth"
+          query: "{ .keys }"
+    - type: heading
+      label: Password
+      context:
+        type: cluster/secret
+        config:
+          Name: "{{ .Release.Name }}-auth"
+      value: "{ .data.password }"
+`
+
+func newObject(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+
+	for key, val := range fields {
+		obj.Object[key] = val
+	}
+
+	return obj
+}
+
+func TestFormYAMLFromBytesOptionContexts(t *testing.T) {
+	standard := newObject("storage.k8s.io/v1", "StorageClass", "", "standard", nil)
+	standard.SetAnnotations(map[string]string{
+		"storageclass.kubernetes.io/is-default-class": "true",
+	})
+
+	client := dynamicfake.NewSimpleDynamicClient(
+		runtime.NewScheme(),
+		newObject("v1", "Namespace", "", "kube-system", nil),
+		newObject("v1", "Namespace", "", "default", nil),
+		newObject("storage.k8s.io/v1", "StorageClass", "", "ssd", nil),
+		standard,
+		newObject("v1", "Secret", "default", "web-auth", map[string]interface{}{
+			"data": map[string]interface{}{
+				"password":      "aHVudGVyMg==",
+				"root-password": "aHVudGVyMw==",
+			},
+		}),
+	)
+
+	def := &parser.ClientConfigDefault{
+		DynamicClient: client,
+		HelmRelease: &release.Release{
+			Name:      "web",
+			Namespace: "default",
+			Chart:     &chart.Chart{},
+			Config:    map[string]interface{}{},
+		},
+	}
+
+	form, err := parser.FormYAMLFromBytes(def, []byte(optionsFormYAML))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	contents := form.Tabs[0].Sections[0].Contents
+
+	expOptions := [][]string{
+		{"default", "kube-system"},
+		{"ssd", "standard"},
+		nil,
+		{"password", "root-password"},
+	}
+
+	for i, exp := range expOptions {
+		var options []string
+
+		for _, option := range contents[i].Settings.Options {
+			options = append(options, option.Label)
+		}
+
+		if !reflect.DeepEqual(options, exp) {
+			t.Errorf("wrong options of %s: expected %v, got %v", contents[i].Label, exp, options)
+		}
+	}
+
+	if expVal := []interface{}{"standard"}; !reflect.DeepEqual(contents[2].Value, expVal) {
+		t.Errorf("wrong default storage class: expected %v, got %v", expVal, contents[2].Value)
+	}
+
+	// the values of secrets are never read
+	if expVal := []interface{}{"********"}; !reflect.DeepEqual(contents[4].Value, expVal) {
+		t.Errorf("wrong secret value: expected %v, got %v", expVal, contents[4].Value)
+	}
+}
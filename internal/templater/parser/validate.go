@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// FieldError is a value of a form content that fails the validation rules in the
+// settings of the content
+type FieldError struct {
+	// Key is the tabs[i].sections[j].contents[k] path of the content
+	Key      string `json:"key"`
+	Variable string `json:"variable"`
+	Message  string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Variable, e.Message)
+}
+
+// FieldErrors are the validation errors of the values of a form
+type FieldErrors []*FieldError
+
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateFormYAMLValues validates the values that a release is deployed with
+// against the validation rules of the contents in the form that read from the
+// release's values. Each content's variable is a dot-separated path into the values,
+// which falls back to the default values of the chart. Contents in sections that are
+// hidden by their show_if are not validated. An error is returned if the form, its
+// rules or its show_if expressions cannot be parsed.
+func ValidateFormYAMLValues(
+	bytes []byte,
+	vals map[string]interface{},
+	defaults map[string]interface{},
+) (FieldErrors, error) {
+	return validateFormYAMLValues(bytes, vals, defaults, isReleaseValuesContext)
+}
+
+// ValidateManifestFormYAMLValues validates the values written to the objects of a
+// release of raw manifests or Kustomize, keyed by the kind and then the name of each
+// object, against the validation rules of the contents in the form that read from
+// the manifests context. The values fall back to the current objects of the release,
+// and the contents of hidden sections are not validated.
+func ValidateManifestFormYAMLValues(
+	bytes []byte,
+	vals map[string]interface{},
+	objects map[string]interface{},
+) (FieldErrors, error) {
+	return validateFormYAMLValues(bytes, vals, objects, func(context *models.FormContext) bool {
+		return context.Type == "manifests"
+	})
+}
+
+// validateFormYAMLValues validates the values of the contents of a form that read
+// from the contexts that validated returns true for
+func validateFormYAMLValues(
+	bytes []byte,
+	vals map[string]interface{},
+	defaults map[string]interface{},
+	validated func(context *models.FormContext) bool,
+) (FieldErrors, error) {
+	form, err := unqueriedFormYAMLFromBytes(bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make(FieldErrors, 0)
+	resolve := valuesResolver(vals, defaults)
+
+	for i, tab := range form.Tabs {
+		for j, section := range tab.Sections {
+			shown, err := sectionShown(section, resolve)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !shown {
+				continue
+			}
+
+			for k, content := range section.Contents {
+				// only contents that read from the values of the release being
+				// deployed are validated
+				if content.Variable == "" || !validated(content.Context) {
+					continue
+				}
+
+				val, ok := lookupValue(vals, content.Variable)
+
+				if !ok {
+					val, ok = lookupValue(defaults, content.Variable)
+				}
+
+				msg, err := validateContent(content, val, ok)
+
+				if err != nil {
+					return nil, err
+				}
+
+				if msg != "" {
+					errs = append(errs, &FieldError{
+						Key:      fmt.Sprintf("tabs[%d].sections[%d].contents[%d]", i, j, k),
+						Variable: content.Variable,
+						Message:  msg,
+					})
+				}
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// validateContent checks the value of a content against the rules in its settings,
+// and returns a message describing the first rule that fails
+func validateContent(content *models.FormContent, val interface{}, ok bool) (string, error) {
+	settings := content.Settings
+
+	if !ok || val == nil || val == "" {
+		if settings.Required {
+			return "is required", nil
+		}
+
+		return "", nil
+	}
+
+	str := fmt.Sprintf("%v", val)
+
+	if settings.Pattern != "" {
+		re, err := regexp.Compile(settings.Pattern)
+
+		if err != nil {
+			return "", fmt.Errorf("invalid pattern for %s: %v", content.Variable, err)
+		}
+
+		if !re.MatchString(str) {
+			return fmt.Sprintf("must match the pattern %s", settings.Pattern), nil
+		}
+	}
+
+	if settings.Min != nil || settings.Max != nil {
+		num, err := strconv.ParseFloat(str, 64)
+
+		if err != nil {
+			return "must be a number", nil
+		}
+
+		if settings.Min != nil && num < *settings.Min {
+			return fmt.Sprintf("must be at least %v", *settings.Min), nil
+		}
+
+		if settings.Max != nil && num > *settings.Max {
+			return fmt.Sprintf("must be at most %v", *settings.Max), nil
+		}
+	}
+
+	if settings.MinLength != nil && utf8.RuneCountInString(str) < *settings.MinLength {
+		return fmt.Sprintf("must be at least %d characters", *settings.MinLength), nil
+	}
+
+	if settings.MaxLength != nil && utf8.RuneCountInString(str) > *settings.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", *settings.MaxLength), nil
+	}
+
+	if len(settings.Options) > 0 {
+		for _, option := range settings.Options {
+			if fmt.Sprintf("%v", option.Value) == str {
+				return "", nil
+			}
+		}
+
+		return "must be one of the options", nil
+	}
+
+	return "", nil
+}
+
+// lookupValue returns the value at the dot-separated path in vals
+func lookupValue(vals map[string]interface{}, path string) (interface{}, bool) {
+	var val interface{} = vals
+
+	for _, key := range strings.Split(path, ".") {
+		m, ok := val.(map[string]interface{})
+
+		if !ok {
+			return nil, false
+		}
+
+		val, ok = m[key]
+
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return val, true
+}
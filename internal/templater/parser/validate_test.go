@@ -0,0 +1,172 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/templater/parser"
+)
+
+const validatedFormYAML = `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: main
+    contents:
+    - type: string-input
+      label: Name
+      variable: name
+      settings:
+        required: true
+        min_length: 3
+        max_length: 8
+    - type: string-input
+      label: Image Tag
+      variable: image.tag
+      settings:
+        pattern: "^v[0-9]+$"
+    - type: number-input
+      label: Replicas
+      variable: replicaCount
+      settings:
+        min: 1
+        max: 10
+    - type: select
+      label: Tier
+      variable: tier
+      settings:
+        options:
+        - label: Free
+          value: free
+        - label: Paid
+          value: paid
+    - type: heading
+      label: Live Replicas
+      context:
+        type: cluster
+        config:
+          Version: v1
+          Resource: pods
+      variable: status.replicas
+      settings:
+        required: true
+`
+
+type validateTest struct {
+	msg     string
+	vals    map[string]interface{}
+	expErrs []string
+}
+
+var validateTests = []validateTest{
+	validateTest{
+		msg: "valid values",
+		vals: map[string]interface{}{
+			"name":         "web",
+			"image":        map[string]interface{}{"tag": "v2"},
+			"replicaCount": float64(3),
+			"tier":         "paid",
+		},
+		expErrs: []string{},
+	},
+	validateTest{
+		msg: "chart defaults",
+		vals: map[string]interface{}{
+			"name": "web",
+		},
+		expErrs: []string{},
+	},
+	validateTest{
+		msg: "invalid values",
+		vals: map[string]interface{}{
+			"name":         "",
+			"image":        map[string]interface{}{"tag": "latest"},
+			"replicaCount": "11",
+			"tier":         "enterprise",
+		},
+		expErrs: []string{
+			"name: is required",
+			"image.tag: must match the pattern ^v[0-9]+$",
+			"replicaCount: must be at most 10",
+			"tier: must be one of the options",
+		},
+	},
+	validateTest{
+		msg: "string length and numbers",
+		vals: map[string]interface{}{
+			"name":         "a-very-long-name",
+			"replicaCount": "three",
+		},
+		expErrs: []string{
+			"name: must be at most 8 characters",
+			"replicaCount: must be a number",
+		},
+	},
+}
+
+func TestValidateFormYAMLValues(t *testing.T) {
+	defaults := map[string]interface{}{
+		"name":         "",
+		"image":        map[string]interface{}{"tag": "v1"},
+		"replicaCount": float64(1),
+		"tier":         "free",
+	}
+
+	for _, c := range validateTests {
+		errs, err := parser.ValidateFormYAMLValues([]byte(validatedFormYAML), c.vals, defaults)
+
+		if err != nil {
+			t.Fatalf("%s: %v", c.msg, err)
+		}
+
+		if len(errs) != len(c.expErrs) {
+			t.Errorf("%s: wrong errors: expected %v, got %v", c.msg, c.expErrs, errs)
+			continue
+		}
+
+		for i, expErr := range c.expErrs {
+			if errs[i].Error() != expErr {
+				t.Errorf("%s: wrong error: expected %s, got %s", c.msg, expErr, errs[i].Error())
+			}
+		}
+	}
+}
+
+func TestValidateFormYAMLValuesKey(t *testing.T) {
+	errs, err := parser.ValidateFormYAMLValues([]byte(validatedFormYAML), map[string]interface{}{
+		"name":         "web",
+		"replicaCount": float64(0),
+	}, nil)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("wrong number of errors: expected 1, got %v", errs)
+	}
+
+	// errors map back to the path of the content in the form
+	if expKey := "tabs[0].sections[0].contents[2]"; errs[0].Key != expKey {
+		t.Errorf("wrong key: expected %s, got %s", expKey, errs[0].Key)
+	}
+}
+
+func TestValidateFormYAMLValuesInvalidPattern(t *testing.T) {
+	_, err := parser.ValidateFormYAMLValues([]byte(`
+tabs:
+- name: main
+  sections:
+  - name: main
+    contents:
+    - type: string-input
+      variable: name
+      settings:
+        pattern: "(["
+`), map[string]interface{}{"name": "web"}, nil)
+
+	if err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
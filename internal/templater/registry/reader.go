@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/registry"
+	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/internal/templater"
+	"github.com/porter-dev/porter/internal/templater/utils"
+)
+
+// Lister lists the image repositories and images of a registry, which is
+// implemented by *registry.Registry
+type Lister interface {
+	ListRepositories(repo repository.Repository) ([]*registry.Repository, error)
+	ListImages(repoName string, repo repository.Repository) ([]*registry.Image, error)
+}
+
+// RegistryTemplateReader reads the image repositories of a registry connected to a
+// project, and the images of one of the repositories
+type RegistryTemplateReader struct {
+	Queries []*templater.TemplateReaderQuery
+
+	Registry Lister
+	Repo     repository.Repository
+
+	// Optional, the image repository to list the images and tags of
+	Repository string
+}
+
+// ValuesFromTarget returns the sorted names of the image repositories under
+// "repositories". If the image repository is set, its images are returned under
+// "images", and their sorted tags under "tags".
+func (r *RegistryTemplateReader) ValuesFromTarget() (map[string]interface{}, error) {
+	if r.Registry == nil {
+		return nil, fmt.Errorf("must set registry to read image repositories")
+	}
+
+	repos, err := r.Registry.ListRepositories(r.Repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repoNames := make([]string, 0, len(repos))
+
+	for _, repo := range repos {
+		repoNames = append(repoNames, repo.Name)
+	}
+
+	res := map[string]interface{}{
+		"repositories": toList(repoNames),
+		"images":       []interface{}{},
+		"tags":         []interface{}{},
+	}
+
+	if r.Repository == "" {
+		return res, nil
+	}
+
+	imgs, err := r.Registry.ListImages(r.Repository, r.Repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]interface{}, 0, len(imgs))
+	tags := make([]string, 0, len(imgs))
+
+	for _, img := range imgs {
+		images = append(images, map[string]interface{}{
+			"digest":          img.Digest,
+			"tag":             img.Tag,
+			"repository_name": img.RepositoryName,
+		})
+
+		// untagged images are only referred to by digest
+		if img.Tag != "" {
+			tags = append(tags, img.Tag)
+		}
+	}
+
+	res["images"] = images
+	res["tags"] = toList(tags)
+
+	return res, nil
+}
+
+// RegisterQuery adds a query to the list of queries to execute
+func (r *RegistryTemplateReader) RegisterQuery(query *templater.TemplateReaderQuery) error {
+	r.Queries = append(r.Queries, query)
+
+	return nil
+}
+
+// Read executes the queries against the repositories and images of the registry
+func (r *RegistryTemplateReader) Read() (map[string]interface{}, error) {
+	values, err := r.ValuesFromTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.QueryValues(values, r.Queries)
+}
+
+// ReadStream sends the queried registry values once
+func (r *RegistryTemplateReader) ReadStream(
+	on templater.OnDataStream,
+	stopCh <-chan struct{},
+) error {
+	return utils.ReadOnce(r, on)
+}
+
+// toList sorts strs into a list that can be queried
+func toList(strs []string) []interface{} {
+	sort.Strings(strs)
+
+	res := make([]interface{}, 0, len(strs))
+
+	for _, str := range strs {
+		res = append(res, str)
+	}
+
+	return res
+}
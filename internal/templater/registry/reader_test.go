@@ -0,0 +1,91 @@
+package registry_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/registry"
+	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/internal/templater/utils"
+
+	tr "github.com/porter-dev/porter/internal/templater/registry"
+)
+
+type fakeLister struct {
+	images map[string][]*registry.Image
+}
+
+func (l *fakeLister) ListRepositories(repo repository.Repository) ([]*registry.Repository, error) {
+	res := make([]*registry.Repository, 0)
+
+	for name := range l.images {
+		res = append(res, &registry.Repository{Name: name})
+	}
+
+	return res, nil
+}
+
+func (l *fakeLister) ListImages(repoName string, repo repository.Repository) ([]*registry.Image, error) {
+	imgs, ok := l.images[repoName]
+
+	if !ok {
+		return nil, fmt.Errorf("repository %s not found", repoName)
+	}
+
+	return imgs, nil
+}
+
+var lister = &fakeLister{
+	images: map[string][]*registry.Image{
+		"web": []*registry.Image{
+			&registry.Image{Digest: "sha256:b", Tag: "1.1.0", RepositoryName: "web"},
+			&registry.Image{Digest: "sha256:a", Tag: "1.0.0", RepositoryName: "web"},
+			&registry.Image{Digest: "sha256:c", RepositoryName: "web"},
+		},
+		"api": []*registry.Image{},
+	},
+}
+
+func TestRegistryTemplateReader(t *testing.T) {
+	r := &tr.RegistryTemplateReader{
+		Registry:   lister,
+		Repository: "web",
+	}
+
+	for key, query := range map[string]string{
+		"repositories": "{ .repositories[*] }",
+		"tags":         "{ .tags[*] }",
+		"digests":      "{ .images[*].digest }",
+	} {
+		q, err := utils.NewQuery(key, query)
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		r.RegisterQuery(q)
+	}
+
+	data, err := r.Read()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expData := map[string]interface{}{
+		"repositories": []interface{}{"api", "web"},
+		"tags":         []interface{}{"1.0.0", "1.1.0"},
+		"digests":      []interface{}{"sha256:b", "sha256:a", "sha256:c"},
+	}
+
+	if !reflect.DeepEqual(data, expData) {
+		t.Errorf("wrong data: expected %v, got %v", expData, data)
+	}
+
+	r.Repository = "missing"
+
+	if _, err := r.Read(); err == nil {
+		t.Errorf("expected error for missing repository")
+	}
+}
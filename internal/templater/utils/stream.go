@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"github.com/porter-dev/porter/internal/templater"
+)
+
+// ReadOnce sends the queried values of a reader once, as a create, for readers of
+// values that are not watched for updates
+func ReadOnce(r templater.TemplateReader, on templater.OnDataStream) error {
+	data, err := r.Read()
+
+	if err != nil {
+		return err
+	}
+
+	pkt := make(map[string]interface{})
+	pkt["kind"] = "create"
+	pkt["data"] = data
+
+	return on(pkt)
+}
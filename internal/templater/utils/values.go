@@ -1,6 +1,10 @@
 package utils
 
-import "sigs.k8s.io/yaml"
+import (
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
 
 // MergeYAML merges raw yaml, with preference given to override
 func MergeYAML(base, override []byte) (map[string]interface{}, error) {
@@ -43,6 +47,40 @@ func CoalesceValues(base, override map[string]interface{}) map[string]interface{
 	return override
 }
 
+// CopyValues copies the maps of values, since merging values modifies them
+func CopyValues(vals map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(vals))
+
+	for key, val := range vals {
+		if m, ok := val.(map[string]interface{}); ok {
+			val = CopyValues(m)
+		}
+
+		res[key] = val
+	}
+
+	return res
+}
+
+// SetValue sets the value at the dot-separated path in vals, creating the maps
+// along the path
+func SetValue(vals map[string]interface{}, path string, val interface{}) {
+	keys := strings.Split(path, ".")
+
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := vals[key].(map[string]interface{})
+
+		if !ok {
+			next = make(map[string]interface{})
+			vals[key] = next
+		}
+
+		vals = next
+	}
+
+	vals[keys[len(keys)-1]] = val
+}
+
 func isYAMLTable(v interface{}) bool {
 	_, ok := v.(map[string]interface{})
 	return ok
@@ -11,6 +11,8 @@ import (
 	"github.com/gorilla/sessions"
 	"github.com/porter-dev/porter/internal/helm"
 	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
 	lr "github.com/porter-dev/porter/internal/logger"
 	"github.com/porter-dev/porter/internal/repository"
 	"helm.sh/helm/v3/pkg/storage"
@@ -37,6 +39,15 @@ type App struct {
 	isLocal      bool
 	TestAgents   *TestAgents
 	GithubConfig *oauth2.Config
+
+	// informers are the informers shared between streaming connections
+	informers *informer.Registry
+
+	// k8sAgents caches the Kubernetes agents of each cluster
+	k8sAgents *kubernetes.AgentCache
+
+	// tunnels are the tunnels opened by in-cluster agents
+	tunnels *tunnel.Registry
 }
 
 // New returns a new App instance
@@ -51,6 +62,7 @@ func New(
 	testing bool,
 	isLocal bool,
 	githubConfig *oauth.Config,
+	discoveryCacheDir string,
 ) *App {
 	// for now, will just support the english translator from the
 	// validator/translations package
@@ -76,6 +88,11 @@ func New(
 		oauthGithubConf = oauth.NewGithubClient(githubConfig)
 	}
 
+	tunnels := tunnel.NewRegistry()
+
+	k8sAgents := kubernetes.NewAgentCache(kubernetes.DefaultAgentCacheTTL, discoveryCacheDir)
+	k8sAgents.Tunnels = tunnels
+
 	return &App{
 		db:           db,
 		logger:       logger,
@@ -88,6 +105,9 @@ func New(
 		isLocal:      isLocal,
 		TestAgents:   testAgents,
 		GithubConfig: oauthGithubConf,
+		informers:    informer.NewRegistry(informer.DefaultIdleTimeout),
+		k8sAgents:    k8sAgents,
+		tunnels:      tunnels,
 	}
 }
 
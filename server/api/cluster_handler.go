@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
 	"github.com/porter-dev/porter/internal/models"
 )
 
@@ -63,6 +66,130 @@ func (app *App) HandleCreateProjectCluster(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// CreateAgentClusterResponse is the response to creating an agent cluster: the
+// agent token is only returned once, and is not stored
+type CreateAgentClusterResponse struct {
+	*models.ClusterExternal
+
+	AgentToken string `json:"agent_token"`
+}
+
+// HandleCreateProjectAgentCluster creates a new cluster that is reached through an
+// in-cluster agent, and returns the token that the agent authenticates with
+func (app *App) HandleCreateProjectAgentCluster(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.CreateAgentClusterForm{
+		ProjectID: uint(projID),
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	token, hash, err := tunnel.NewAgentToken()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	cluster, err := form.ToCluster(hash)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// handle write to the database
+	cluster, err = app.repo.Cluster.CreateCluster(cluster)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.logger.Info().Msgf("New agent cluster created: %d", cluster.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	res := &CreateAgentClusterResponse{
+		ClusterExternal: cluster.Externalize(),
+		AgentToken:      token,
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleConnectClusterAgent opens the tunnel of an in-cluster agent. The agent
+// authenticates with its token as a bearer token, rather than with a session, and
+// the connection is held open until the agent disconnects.
+func (app *App) HandleConnectClusterAgent(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(chi.URLParam(r, "cluster_id"), 0, 64)
+
+	if err != nil || clusterID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	cluster, err := app.repo.Cluster.ReadCluster(uint(clusterID))
+
+	// the same error is returned for clusters that do not exist, so that agent
+	// tokens cannot be used to find clusters
+	if err != nil || cluster.ProjectID != uint(projID) ||
+		cluster.AuthMechanism != models.Agent || !tunnel.CheckAgentToken(token, cluster.AgentTokenHash) {
+		app.sendExternalError(err, http.StatusUnauthorized, HTTPError{
+			Code:   http.StatusUnauthorized,
+			Errors: []string{"invalid agent token"},
+		}, w)
+
+		return
+	}
+
+	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+		app.handleErrorUpgradeWebsocket(err, w)
+		return
+	}
+
+	app.logger.Info().Msgf("Agent connected for cluster: %d", cluster.ID)
+
+	if err := app.tunnels.Serve(cluster.ID, conn); err != nil {
+		app.logger.Warn().Err(err).Msgf("Agent tunnel failed for cluster: %d", cluster.ID)
+		return
+	}
+
+	app.logger.Info().Msgf("Agent disconnected for cluster: %d", cluster.ID)
+}
+
 // HandleReadProjectCluster reads a cluster by id
 func (app *App) HandleReadProjectCluster(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(chi.URLParam(r, "cluster_id"), 0, 64)
@@ -89,6 +216,53 @@ func (app *App) HandleReadProjectCluster(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// HandleCheckProjectCluster tries to connect to a cluster with its stored credentials,
+// and checks that Porter has the permissions it needs. Failed checks are reported in
+// the response body rather than as an error status.
+func (app *App) HandleCheckProjectCluster(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "cluster_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	cluster, err := app.repo.Cluster.ReadCluster(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	// permissions are checked as the identity that the user's role is mapped to
+	imp, err := app.getRoleImpersonation(r, cluster)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	var res *kubernetes.ClusterCheckResult
+
+	if app.testing {
+		res = app.TestAgents.K8sAgent.CheckCluster()
+	} else {
+		res = kubernetes.CheckClusterConfig(&kubernetes.OutOfClusterConfig{
+			Cluster:     cluster,
+			Repo:        app.repo,
+			Tunnels:     app.tunnels,
+			Impersonate: imp,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
 // HandleListProjectClusters returns a list of clusters that have linked Integrations.
 func (app *App) HandleListProjectClusters(w http.ResponseWriter, r *http.Request) {
 	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
@@ -152,7 +326,7 @@ func (app *App) HandleUpdateProjectCluster(w http.ResponseWriter, r *http.Reques
 	}
 
 	// convert the form to a registry
-	cluster, err := form.ToCluster(app.repo.Cluster)
+	cluster, err := form.ToCluster(*app.repo)
 
 	if err != nil {
 		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
@@ -167,6 +341,8 @@ func (app *App) HandleUpdateProjectCluster(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.k8sAgents.Invalidate(cluster.ID)
+
 	w.WriteHeader(http.StatusOK)
 
 	clusterExt := cluster.Externalize()
@@ -200,6 +376,34 @@ func (app *App) HandleDeleteProjectCluster(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.k8sAgents.Invalidate(cluster.ID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleInvalidateClusterDiscoveryCache removes the cached discovery information of a
+// cluster, so that resources added to the API server, for example after a cluster
+// upgrade, are discovered on the next request
+func (app *App) HandleInvalidateClusterDiscoveryCache(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "cluster_id"), 0, 64)
+
+	if err != nil || id == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	cluster, err := app.repo.Cluster.ReadCluster(uint(id))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	if err := app.k8sAgents.InvalidateDiscovery(cluster.ID); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 
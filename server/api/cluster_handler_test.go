@@ -7,8 +7,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/kubernetes/fixtures"
+	"github.com/porter-dev/porter/internal/kubernetes/tunnel"
 	"github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/server/api"
 	"gorm.io/gorm"
 
 	"github.com/go-test/deep"
@@ -97,6 +100,75 @@ func TestHandleCreateCluster(t *testing.T) {
 	testRegistryRequests(t, createRegistryTests, true)
 }
 
+var createAgentClusterTests = []*clusterTest{
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create agent cluster",
+		method:    "POST",
+		endpoint:  "/api/projects/1/clusters/agent",
+		body:      `{"name":"cluster-test"}`,
+		expStatus: http.StatusCreated,
+		expBody:   `{"id":1,"project_id":1,"name":"cluster-test","server":"http://porter-agent","service":"kube"}`,
+		useCookie: true,
+		validators: []func(c *clusterTest, tester *tester, t *testing.T){
+			projectClusterBodyValidator,
+			func(c *clusterTest, tester *tester, t *testing.T) {
+				res := &api.CreateAgentClusterResponse{}
+				json.Unmarshal(tester.rr.Body.Bytes(), res)
+
+				cluster, err := tester.repo.Cluster.ReadCluster(1)
+
+				if err != nil {
+					t.Fatalf("%v\n", err)
+				}
+
+				if cluster.AuthMechanism != models.Agent {
+					t.Errorf("wrong auth mechanism: expected %s, got %s", models.Agent, cluster.AuthMechanism)
+				}
+
+				if !tunnel.CheckAgentToken(res.AgentToken, cluster.AgentTokenHash) {
+					t.Errorf("returned agent token does not match stored hash")
+				}
+			},
+		},
+	},
+}
+
+func TestHandleCreateAgentCluster(t *testing.T) {
+	testClusterRequests(t, createAgentClusterTests, true)
+}
+
+var connectClusterAgentTests = []*clusterTest{
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initProjectClusterDefault,
+		},
+		msg:       "Connect agent of cluster without agent",
+		method:    "GET",
+		endpoint:  "/api/projects/1/clusters/1/agent/connect",
+		body:      ``,
+		expStatus: http.StatusUnauthorized,
+		expBody:   `{"code":401,"errors":["invalid agent token"]}`,
+		useCookie: false,
+		validators: []func(c *clusterTest, tester *tester, t *testing.T){
+			func(c *clusterTest, tester *tester, t *testing.T) {
+				if body := strings.TrimSpace(tester.rr.Body.String()); body != c.expBody {
+					t.Errorf("wrong body: expected %s, got %s", c.expBody, body)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleConnectClusterAgent(t *testing.T) {
+	testClusterRequests(t, connectClusterAgentTests, true)
+}
+
 var readProjectClusterTest = []*clusterTest{
 	&clusterTest{
 		initializers: []func(t *tester){
@@ -163,6 +235,40 @@ var updateClusterTests = []*clusterTest{
 			projectClusterBodyValidator,
 		},
 	},
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initProjectClusterDefault,
+		},
+		msg:       "Update cluster proxy url",
+		method:    "POST",
+		endpoint:  "/api/projects/1/clusters/1",
+		body:      `{"name":"cluster-new-name","proxy_url":"socks5://proxy.example.com:1080"}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"id":1,"project_id":1,"name":"cluster-new-name","server":"https://10.10.10.10","service":"kube","proxy_url":"socks5://proxy.example.com:1080"}`,
+		useCookie: true,
+		validators: []func(c *clusterTest, tester *tester, t *testing.T){
+			projectClusterBodyValidator,
+		},
+	},
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initProjectClusterDefault,
+		},
+		msg:       "Update cluster unsupported proxy scheme",
+		method:    "POST",
+		endpoint:  "/api/projects/1/clusters/1",
+		body:      `{"name":"cluster-new-name","proxy_url":"ftp://proxy.example.com"}`,
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":600,"errors":["could not process request"]}`,
+		useCookie: true,
+		validators: []func(c *clusterTest, tester *tester, t *testing.T){
+			projectClusterBodyValidator,
+		},
+	},
 }
 
 func TestHandleUpdateCluster(t *testing.T) {
@@ -214,6 +320,68 @@ func TestHandleDeleteCluster(t *testing.T) {
 	testClusterRequests(t, deleteClusterTests, true)
 }
 
+var checkProjectClusterTests = []*clusterTest{
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initProjectClusterDefault,
+		},
+		msg:       "Check cluster",
+		method:    "GET",
+		endpoint:  "/api/projects/1/clusters/1/check",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   ``,
+		useCookie: true,
+		validators: []func(c *clusterTest, tester *tester, t *testing.T){
+			func(c *clusterTest, tester *tester, t *testing.T) {
+				gotBody := &kubernetes.ClusterCheckResult{}
+
+				if err := json.Unmarshal(tester.rr.Body.Bytes(), gotBody); err != nil {
+					t.Fatal(err)
+				}
+
+				if len(gotBody.Checks) != len(kubernetes.RequiredPermissions)+1 {
+					t.Fatalf("%s, wrong number of checks: got %d want %d",
+						c.msg, len(gotBody.Checks), len(kubernetes.RequiredPermissions)+1)
+				}
+
+				// the fake clientset denies every access review
+				if !gotBody.Checks[0].Passed || gotBody.Passed {
+					t.Errorf("%s, expected connection to pass and permissions to fail, got %v",
+						c.msg, gotBody)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleCheckProjectCluster(t *testing.T) {
+	testClusterRequests(t, checkProjectClusterTests, true)
+}
+
+var invalidateClusterDiscoveryCacheTests = []*clusterTest{
+	&clusterTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initProjectClusterDefault,
+		},
+		msg:       "Invalidate cluster discovery cache",
+		method:    "DELETE",
+		endpoint:  "/api/projects/1/clusters/1/discovery_cache",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   ``,
+		useCookie: true,
+	},
+}
+
+func TestHandleInvalidateClusterDiscoveryCache(t *testing.T) {
+	testClusterRequests(t, invalidateClusterDiscoveryCacheTests, true)
+}
+
 var createProjectClusterCandidatesTests = []*clusterTest{
 	&clusterTest{
 		initializers: []func(t *tester){
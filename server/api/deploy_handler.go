@@ -2,16 +2,28 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/helm"
 	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/helm/stack"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"gorm.io/gorm"
+	"helm.sh/helm/v3/pkg/chart"
+	"sigs.k8s.io/yaml"
 )
 
-// HandleDeployTemplate triggers a chart deployment from a template
+// HandleDeployTemplate triggers a chart deployment from a template. Stack templates,
+// which have a stack.yaml, deploy each of their charts as a release, and upgrade
+// the releases if the stack is already deployed, uninstalling the releases of the
+// charts that the stack no longer lists.
 func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	version := chi.URLParam(r, "version")
@@ -74,6 +86,26 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	stackYAML, err := stack.FromChart(chart)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"invalid stack template: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if stackYAML != nil {
+		app.deployStack(w, r, agent, form, getChartForm, chart, stackYAML)
+		return
+	}
+
+	if !app.applyFormValues(w, chart, form.ChartTemplateForm.FormValues) {
+		return
+	}
+
 	conf := &helm.InstallChartConfig{
 		Chart:     chart,
 		Name:      form.ChartTemplateForm.Name,
@@ -94,3 +126,223 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// deployStack deploys the charts of a stack template as a unit, and stores the stack
+// so that its releases can be read and upgraded together. The form values of a
+// stack are keyed by the name of each chart in the stack.
+func (app *App) deployStack(
+	w http.ResponseWriter,
+	r *http.Request,
+	agent *helm.Agent,
+	form *forms.InstallChartTemplateForm,
+	chartForm *forms.ChartForm,
+	template *chart.Chart,
+	stackYAML *models.StackYAML,
+) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	name := form.ChartTemplateForm.Name
+	cluster := form.ReleaseForm.Form.Cluster
+	namespace := form.ReleaseForm.Form.Namespace
+
+	if name == "" || cluster == nil {
+		app.sendExternalError(fmt.Errorf("stack name or cluster not set"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"a stack must be deployed with a name and a cluster_id"},
+		}, w)
+
+		return
+	}
+
+	existing, err := app.repo.Stack.ReadStack(cluster.ID, namespace, name)
+
+	if err == gorm.ErrRecordNotFound {
+		existing = nil
+	} else if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	} else if existing.ProjectID != uint(projID) {
+		app.sendExternalError(fmt.Errorf("stack in other project"), http.StatusConflict, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"stack already exists"},
+		}, w)
+
+		return
+	}
+
+	deployer := &stack.Deployer{
+		Agent:     agent,
+		Namespace: namespace,
+		Template:  template,
+		RepoURL:   chartForm.RepoURL,
+		LoadChart: loader.LoadChart,
+		Install:   existing == nil,
+	}
+
+	if existing != nil {
+		deployer.Previous, err = stack.FromBytes([]byte(existing.Spec))
+
+		if err != nil {
+			app.handleErrorInternal(err, w)
+			return
+		}
+	}
+
+	if form.ChartTemplateForm.FormValues == nil {
+		form.ChartTemplateForm.FormValues = make(map[string]interface{})
+	}
+
+	if !app.applyStackFormValues(w, deployer, stackYAML, form.ChartTemplateForm.FormValues) {
+		return
+	}
+
+	if _, err := deployer.Deploy(name, stackYAML, form.ChartTemplateForm.FormValues); err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deploying stack: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	spec, err := yaml.Marshal(stackYAML)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if existing == nil {
+		existing = &models.Stack{
+			ProjectID: uint(projID),
+			ClusterID: cluster.ID,
+			Name:      name,
+			Namespace: namespace,
+		}
+	}
+
+	existing.TemplateName = chartForm.Name
+	existing.TemplateVersion = template.Metadata.Version
+	existing.RepoURL = chartForm.RepoURL
+	existing.Spec = string(spec)
+	existing.Revision++
+
+	if existing.ID == 0 {
+		_, err = app.repo.Stack.CreateStack(existing)
+	} else {
+		_, err = app.repo.Stack.UpdateStack(existing)
+	}
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.writeStackStatus(w, agent, existing, stackYAML)
+}
+
+// applyStackFormValues sets the computed values of the form of each chart of a
+// stack, in the values of the chart keyed by its name, and validates them against
+// the rules of the form, before any chart is deployed. The values of a chart fall
+// back to its values in the stack.yaml and then to the chart's defaults, and the
+// variables of the validation errors are prefixed with the name of the chart. The
+// variables that are set from the release of another chart are not validated unless
+// they are set in vals, since they are only known once that chart is deployed. If
+// the values are invalid, the errors are sent and false is returned.
+func (app *App) applyStackFormValues(
+	w http.ResponseWriter,
+	deployer *stack.Deployer,
+	stackYAML *models.StackYAML,
+	vals map[string]interface{},
+) bool {
+	charts, err := stack.Order(stackYAML)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return false
+	}
+
+	fieldErrs := make(parser.FieldErrors, 0)
+
+	for _, c := range charts {
+		ch, err := deployer.Chart(c)
+
+		if err != nil {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrReleaseReadData,
+				Errors: []string{"could not load chart " + c.Name + ": " + err.Error()},
+			}, w)
+
+			return false
+		}
+
+		formBytes := chartFormBytes(ch)
+
+		if formBytes == nil {
+			continue
+		}
+
+		chartVals, ok := vals[c.Name].(map[string]interface{})
+
+		if !ok {
+			chartVals = make(map[string]interface{})
+			vals[c.Name] = chartVals
+		}
+
+		chartErrs, ok := app.computeFormValues(w, formBytes, chartVals, stack.DefaultValues(c, ch))
+
+		if !ok {
+			return false
+		}
+
+		for _, fieldErr := range chartErrs {
+			if isStackReference(c, fieldErr.Variable) && !hasValue(vals[c.Name], fieldErr.Variable) {
+				continue
+			}
+
+			fieldErr.Variable = c.Name + "." + fieldErr.Variable
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		app.handleErrorFormFieldValidation(fieldErrs, w)
+		return false
+	}
+
+	return true
+}
+
+// isStackReference returns whether a variable of a chart of a stack is set, or is
+// inside a value that is set, from the release of another chart of the stack
+func isStackReference(c *models.StackChart, variable string) bool {
+	for _, ref := range c.ValuesFrom {
+		if variable == ref.Variable || strings.HasPrefix(variable, ref.Variable+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasValue returns whether the value at the dot-separated path in vals is set
+func hasValue(vals interface{}, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := vals.(map[string]interface{})
+
+		if !ok {
+			return false
+		}
+
+		if vals, ok = m[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
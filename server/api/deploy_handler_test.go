@@ -3,10 +3,17 @@ package api_test
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/porter-dev/porter/internal/helm/stack"
 	"github.com/porter-dev/porter/internal/kubernetes"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -33,6 +40,8 @@ func testDeployRequests(t *testing.T, tests []*deployTest, canQuery bool) {
 			init(tester)
 		}
 
+		tester.app.TestAgents.HelmAgent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("default")
+
 		req, err := http.NewRequest(
 			c.method,
 			c.endpoint,
@@ -67,27 +76,151 @@ func testDeployRequests(t *testing.T, tests []*deployTest, canQuery bool) {
 
 // ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
 
-// var newDeployTests = []*deployTest{
-// 	&deployTest{
-// 		initializers: []func(tester *tester){
-// 			initDefaultDeploy,
-// 		},
-// 		msg:       "Deploy template",
-// 		method:    "POST",
-// 		endpoint:  "/api/projects/1/deploy",
-// 		body:      "",
-// 		expStatus: http.StatusOK,
-// 		expBody:   "unimplemented",
-// 		useCookie: true,
-// 		validators: []func(c *deployTest, tester *tester, t *testing.T){
-// 			deployValidator,
-// 		},
-// 	},
-// }
-
-// func TestHandleDeployTemplate(t *testing.T) {
-// 	testDeployRequests(t, newDeployTests, true)
-// }
+const deployStackYAML = `name: shop
+charts:
+- name: app
+  chart: web
+  values:
+    image:
+      repository: nginx
+  values_from:
+  - variable: dbHost
+    chart: db
+    query: '{ .name }'
+- name: db
+  chart: postgres
+`
+
+const deployWebFormYAML = `name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: main
+    contents:
+    - type: number-input
+      label: Replicas
+      variable: replicaCount
+      settings:
+        min: 1
+        max: 10
+    - type: heading
+      label: Image
+      variable: image.full
+      compute: image.repository + ":" + image.tag
+    - type: string-input
+      label: Database Host
+      variable: dbHost
+      settings:
+        required: true
+`
+
+// newStackRepo serves a chart repository with a shop-stack template, which vendors
+// a web chart with a form and a postgres chart that the web chart references
+func newStackRepo(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	web := newTestChart("web", map[string]interface{}{
+		"replicaCount": 1,
+		"image":        map[string]interface{}{"tag": "latest"},
+	})
+	web.Files = []*chart.File{
+		&chart.File{Name: "form.yaml", Data: []byte(deployWebFormYAML)},
+	}
+	web.Raw = []*chart.File{
+		&chart.File{Name: chartutil.ValuesfileName, Data: []byte("replicaCount: 1\nimage:\n  tag: latest\n")},
+	}
+
+	template := newTestChart("shop-stack", nil)
+	template.Files = []*chart.File{
+		&chart.File{Name: stack.FileName, Data: []byte(deployStackYAML)},
+	}
+	template.AddDependency(web, newTestChart("postgres", nil))
+
+	return newChartRepo(t, template)
+}
+
+func TestHandleDeployStackTemplate(t *testing.T) {
+	server := newStackRepo(t)
+
+	endpoint := "/api/projects/1/deploy/shop-stack/0.1.0?cluster_id=1&namespace=default&storage=memory&repo_url=" +
+		url.QueryEscape(server.URL)
+
+	deployTests := []*deployTest{
+		&deployTest{
+			initializers: []func(tester *tester){
+				initUserDefault,
+				initProject,
+				initProjectClusterDefault,
+			},
+			msg:       "Deploy stack template",
+			method:    "POST",
+			endpoint:  endpoint,
+			body:      `{"templateName":"shop-stack","name":"shop","formValues":{"app":{"replicaCount":3}}}`,
+			expStatus: http.StatusOK,
+			useCookie: true,
+			validators: []func(c *deployTest, tester *tester, t *testing.T){
+				func(c *deployTest, tester *tester, t *testing.T) {
+					if _, err := tester.repo.Stack.ReadStack(1, "default", "shop"); err != nil {
+						t.Errorf("%s, stack not stored: %v", c.msg, err)
+					}
+
+					rel, err := tester.app.TestAgents.HelmAgent.GetRelease("shop-app", 0)
+
+					if err != nil {
+						t.Fatalf("%s, app not deployed: %v", c.msg, err)
+					}
+
+					// the required host is set from the release of the db
+					expConfig := map[string]interface{}{
+						"replicaCount": float64(3),
+						"dbHost":       "shop-db",
+						"image": map[string]interface{}{
+							"repository": "nginx",
+							"full":       "nginx:latest",
+						},
+					}
+
+					if !reflect.DeepEqual(rel.Config, expConfig) {
+						t.Errorf("%s, wrong app values: expected %v, got %v", c.msg, expConfig, rel.Config)
+					}
+
+					if _, err := tester.app.TestAgents.HelmAgent.GetRelease("shop-db", 0); err != nil {
+						t.Errorf("%s, db not deployed: %v", c.msg, err)
+					}
+				},
+			},
+		},
+		&deployTest{
+			initializers: []func(tester *tester){
+				initUserDefault,
+				initProject,
+				initProjectClusterDefault,
+			},
+			msg:       "Deploy stack template with invalid chart values",
+			method:    "POST",
+			endpoint:  endpoint,
+			body:      `{"templateName":"shop-stack","name":"shop","formValues":{"app":{"replicaCount":20}}}`,
+			expStatus: http.StatusUnprocessableEntity,
+			expBody:   `{"code":601,"errors":["app.replicaCount: must be at most 10"],"fields":[{"key":"tabs[0].sections[0].contents[0]","variable":"app.replicaCount","message":"must be at most 10"}]}`,
+			useCookie: true,
+			validators: []func(c *deployTest, tester *tester, t *testing.T){
+				deployValidator,
+				func(c *deployTest, tester *tester, t *testing.T) {
+					if _, err := tester.app.TestAgents.HelmAgent.GetRelease("shop-db", 0); err != driver.ErrReleaseNotFound {
+						t.Errorf("%s, expected no chart to be deployed, got %v", c.msg, err)
+					}
+
+					if _, err := tester.repo.Stack.ReadStack(1, "default", "shop"); err == nil {
+						t.Errorf("%s, expected no stack to be stored", c.msg)
+					}
+				},
+			},
+		},
+	}
+
+	testDeployRequests(t, deployTests, true)
+}
 
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
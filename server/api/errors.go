@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/porter-dev/porter/internal/templater/parser"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +14,10 @@ import (
 type HTTPError struct {
 	Code   ErrorCode `json:"code"`
 	Errors []string  `json:"errors"`
+
+	// Fields are the invalid contents of a form, if the values of a form failed
+	// validation
+	Fields parser.FieldErrors `json:"fields,omitempty"`
 }
 
 // ErrorCode is a custom Porter error code, useful for frontend messages
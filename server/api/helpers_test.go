@@ -1,9 +1,13 @@
 package api_test
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"testing"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -16,6 +20,10 @@ import (
 
 	sessionstore "github.com/porter-dev/porter/internal/auth"
 	vr "github.com/porter-dev/porter/internal/validator"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
 )
 
 type tester struct {
@@ -79,7 +87,7 @@ func newTester(canQuery bool) *tester {
 	repo := test.NewRepository(canQuery)
 
 	store, _ := sessionstore.NewStore(repo, appConf.Server)
-	app := api.New(logger, nil, repo, validator, store, appConf.Server.CookieName, true, false, nil)
+	app := api.New(logger, nil, repo, validator, store, appConf.Server.CookieName, true, false, nil, appConf.Server.DiscoveryCacheDir)
 	r := router.New(app, store, appConf.Server.CookieName, appConf.Server.StaticFilePath, repo)
 
 	return &tester{
@@ -92,3 +100,60 @@ func newTester(canQuery bool) *tester {
 		cookie: nil,
 	}
 }
+
+func newTestChart(name string, values map[string]interface{}) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       name,
+			Version:    "0.1.0",
+			Type:       "application",
+		},
+		Values: values,
+	}
+}
+
+// newChartRepo serves a chart repository with the charts, which is closed when the
+// test finishes
+func newChartRepo(t *testing.T, charts ...*chart.Chart) *httptest.Server {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "chart-repo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	index := repo.NewIndexFile()
+
+	for _, ch := range charts {
+		archive, err := chartutil.Save(ch, dir)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index.Add(ch.Metadata, filepath.Base(archive), "", "")
+	}
+
+	indexBytes, err := yaml.Marshal(index)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.yaml" {
+			w.Write(indexBytes)
+			return
+		}
+
+		http.ServeFile(w, r, filepath.Join(dir, filepath.Base(r.URL.Path)))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
@@ -2,12 +2,20 @@ package api
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/informer"
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 
 	"github.com/gorilla/websocket"
 	"github.com/porter-dev/porter/internal/forms"
@@ -17,6 +25,8 @@ import (
 const (
 	ErrK8sDecode ErrorCode = iota + 600
 	ErrK8sValidate
+	ErrK8sWrite
+	ErrK8sReadData
 )
 
 var upgrader = websocket.Upgrader{
@@ -54,7 +64,7 @@ func (app *App) HandleListNamespaces(w http.ResponseWriter, r *http.Request) {
 	if app.testing {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.getK8sAgent(r, form.OutOfClusterConfig)
 	}
 
 	namespaces, err := agent.ListNamespaces()
@@ -70,6 +80,131 @@ func (app *App) HandleListNamespaces(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleCreateNamespace creates a namespace, optionally applying a default
+// ResourceQuota and LimitRange to it. The namespace is deleted if either of them
+// cannot be created.
+func (app *App) HandleCreateNamespace(w http.ResponseWriter, r *http.Request) {
+	form := &forms.CreateNamespaceForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	quota, err := form.ToResourceQuota()
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	limitRange, err := form.ToLimitRange()
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	namespace, err := agent.CreateNamespace(form.ToNamespace())
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error creating namespace " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if quota != nil {
+		if _, err := agent.CreateResourceQuota(namespace.Name, quota); err != nil {
+			app.handleErrorNamespaceLimits(err, "error creating resource quota ", agent, namespace.Name, w)
+			return
+		}
+	}
+
+	if limitRange != nil {
+		if _, err := agent.CreateLimitRange(namespace.Name, limitRange); err != nil {
+			app.handleErrorNamespaceLimits(err, "error creating limit range ", agent, namespace.Name, w)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(namespace); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// handleErrorNamespaceLimits deletes a namespace whose resource quota or limit range
+// could not be created, so that a failed request does not leave the namespace behind
+// without its limits, and sends the error
+func (app *App) handleErrorNamespaceLimits(
+	err error,
+	msg string,
+	agent *kubernetes.Agent,
+	namespace string,
+	w http.ResponseWriter,
+) {
+	errs := []string{msg + err.Error()}
+
+	if deleteErr := agent.DeleteNamespace(namespace); deleteErr != nil {
+		errs = append(errs, "error deleting namespace "+deleteErr.Error())
+	}
+
+	app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+		Code:   ErrK8sWrite,
+		Errors: errs,
+	}, w)
+}
+
+// HandleDeleteNamespace deletes a namespace and all objects inside of it
+func (app *App) HandleDeleteNamespace(w http.ResponseWriter, r *http.Request) {
+	form := &forms.DeleteNamespaceForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Name: chi.URLParam(r, "namespace"),
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	err = agent.DeleteNamespace(form.Name)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error deleting namespace " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // HandleGetPodLogs returns real-time logs of the pod via websockets
 // TODO: Refactor repeated calls.
 func (app *App) HandleGetPodLogs(w http.ResponseWriter, r *http.Request) {
@@ -114,7 +249,7 @@ func (app *App) HandleGetPodLogs(w http.ResponseWriter, r *http.Request) {
 	if app.testing {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.getK8sAgent(r, form.OutOfClusterConfig)
 	}
 
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
@@ -174,7 +309,7 @@ func (app *App) HandleListPods(w http.ResponseWriter, r *http.Request) {
 	if app.testing {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.getK8sAgent(r, form.OutOfClusterConfig)
 	}
 
 	pods := []v1.Pod{}
@@ -237,7 +372,7 @@ func (app *App) HandleStreamControllerStatus(w http.ResponseWriter, r *http.Requ
 	if app.testing {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.getK8sAgent(r, form.OutOfClusterConfig)
 	}
 
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
@@ -247,14 +382,574 @@ func (app *App) HandleStreamControllerStatus(w http.ResponseWriter, r *http.Requ
 
 	if err != nil {
 		app.handleErrorUpgradeWebsocket(err, w)
+		return
 	}
 
 	// get path parameters
 	kind := chi.URLParam(r, "kind")
-	err = agent.StreamControllerStatus(conn, kind)
+	err = agent.StreamControllerStatus(conn, kind, app.getInformers(form, agent))
 
 	if err != nil {
 		app.handleErrorWebsocketWrite(err, w)
 		return
 	}
 }
+
+// HandleRestartController triggers a rolling restart of a Deployment, StatefulSet or
+// DaemonSet
+func (app *App) HandleRestartController(w http.ResponseWriter, r *http.Request) {
+	form := &forms.RestartControllerForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Namespace: chi.URLParam(r, "namespace"),
+		Kind:      strings.ToLower(chi.URLParam(r, "kind")),
+		Name:      chi.URLParam(r, "name"),
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	err = agent.RestartController(form.Kind, form.Namespace, form.Name)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error restarting controller " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleScaleController sets the number of replicas of a Deployment, StatefulSet or
+// ReplicaSet
+func (app *App) HandleScaleController(w http.ResponseWriter, r *http.Request) {
+	form := &forms.ScaleControllerForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	form.Namespace = chi.URLParam(r, "namespace")
+	form.Kind = strings.ToLower(chi.URLParam(r, "kind"))
+	form.Name = chi.URLParam(r, "name")
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	err = agent.ScaleController(form.Kind, form.Namespace, form.Name, *form.Replicas)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error scaling controller " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDeletePod deletes a single pod by namespace and name
+func (app *App) HandleDeletePod(w http.ResponseWriter, r *http.Request) {
+	form := &forms.DeletePodForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Namespace: chi.URLParam(r, "namespace"),
+		Name:      chi.URLParam(r, "name"),
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	err = agent.DeletePod(form.Namespace, form.Name)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error deleting pod " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleListAPIResources lists the resources served by the cluster's api server,
+// including custom resources
+func (app *App) HandleListAPIResources(w http.ResponseWriter, r *http.Request) {
+	form := &forms.K8sForm{
+		OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+			Repo: app.repo,
+		},
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	resources, err := agent.ListAPIResources()
+
+	if err != nil {
+		app.handleErrorK8sRead(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(resources); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleListResources lists the objects of an arbitrary resource
+func (app *App) HandleListResources(w http.ResponseWriter, r *http.Request) {
+	form, agent, err := app.getResourceFormAndAgent(w, r)
+
+	// errors are handled in app.getResourceFormAndAgent
+	if err != nil {
+		return
+	}
+
+	objs, err := agent.ListResources(
+		form.ToGroupVersionResource(),
+		form.Namespace,
+		kubernetes.ListOptions{
+			LabelSelector: form.LabelSelector,
+		},
+	)
+
+	if err != nil {
+		app.handleErrorK8sRead(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(objs); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleGetResource gets a single object of an arbitrary resource
+func (app *App) HandleGetResource(w http.ResponseWriter, r *http.Request) {
+	form, agent, err := app.getResourceFormAndAgent(w, r)
+
+	// errors are handled in app.getResourceFormAndAgent
+	if err != nil {
+		return
+	}
+
+	obj, err := agent.GetResource(form.ToGroupVersionResource(), form.Namespace, form.Name)
+
+	if err != nil {
+		app.handleErrorK8sRead(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleDeleteResource deletes a single object of an arbitrary resource
+func (app *App) HandleDeleteResource(w http.ResponseWriter, r *http.Request) {
+	form, agent, err := app.getResourceFormAndAgent(w, r)
+
+	// errors are handled in app.getResourceFormAndAgent
+	if err != nil {
+		return
+	}
+
+	err = agent.DeleteResource(form.ToGroupVersionResource(), form.Namespace, form.Name)
+
+	if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	} else if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error deleting resource " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleApplyResources applies a YAML or JSON manifest using server-side apply,
+// and returns the applied objects
+func (app *App) HandleApplyResources(w http.ResponseWriter, r *http.Request) {
+	form := &forms.ApplyResourceForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	namespace := form.Namespace
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	objs, err := agent.ApplyResources([]byte(form.Manifest), namespace)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error applying manifest " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(objs); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleListNodes lists the nodes in a cluster with their status, capacity and the
+// resources requested by the pods scheduled on them
+func (app *App) HandleListNodes(w http.ResponseWriter, r *http.Request) {
+	form := &forms.K8sForm{
+		OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+			Repo: app.repo,
+		},
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	capacity, err := agent.GetClusterCapacity()
+
+	if err != nil {
+		app.handleErrorK8sRead(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(capacity); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// HandleCordonNode marks a node as unschedulable
+func (app *App) HandleCordonNode(w http.ResponseWriter, r *http.Request) {
+	app.cordonNode(w, r, true)
+}
+
+// HandleUncordonNode marks a node as schedulable
+func (app *App) HandleUncordonNode(w http.ResponseWriter, r *http.Request) {
+	app.cordonNode(w, r, false)
+}
+
+func (app *App) cordonNode(w http.ResponseWriter, r *http.Request, unschedulable bool) {
+	form := &forms.NodeForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Name: chi.URLParam(r, "name"),
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	err = agent.CordonNode(form.Name, unschedulable)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error updating node " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleDrainNode cordons a node and evicts its pods, respecting PodDisruptionBudgets.
+// It returns the pods that were evicted, skipped or blocked.
+func (app *App) HandleDrainNode(w http.ResponseWriter, r *http.Request) {
+	form := &forms.DrainNodeForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil && err != io.EOF {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+
+	form.Name = chi.URLParam(r, "name")
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	res, err := agent.DrainNode(form.Name, form.ToDrainOptions())
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrK8sWrite,
+			Errors: []string{"error draining node " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return
+	}
+}
+
+// ------------------------ K8s handler helper functions ------------------------ //
+
+// getInformers returns the shared informers for the cluster of the K8sForm, which are
+// started with the agent's dynamic client
+func (app *App) getInformers(k8sForm *forms.K8sForm, agent *kubernetes.Agent) informer.Subscriber {
+	var clusterID uint
+
+	if k8sForm.Cluster != nil {
+		clusterID = k8sForm.Cluster.ID
+	}
+
+	var identity string
+
+	if k8sForm.Impersonate != nil {
+		identity = k8sForm.Impersonate.Identity()
+	}
+
+	return app.informers.ForIdentity(clusterID, identity, agent.DynamicClient)
+}
+
+// getK8sAgent returns the cached agent for the cluster in conf, which acts as the
+// Kubernetes identity that the project maps the requesting user's role to. If the
+// role is not mapped, the agent acts as the identity configured on the cluster.
+func (app *App) getK8sAgent(r *http.Request, conf *kubernetes.OutOfClusterConfig) (*kubernetes.Agent, error) {
+	imp, err := app.getRoleImpersonation(r, conf.Cluster)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Impersonate = imp
+
+	return app.k8sAgents.GetAgent(conf)
+}
+
+// getRoleImpersonation returns the Kubernetes identity that the role of the
+// requesting user in the cluster's project is mapped to, or nil if the role is
+// not mapped
+func (app *App) getRoleImpersonation(r *http.Request, cluster *models.Cluster) (*models.RoleImpersonation, error) {
+	projID := uint64(cluster.ProjectID)
+
+	if param := chi.URLParam(r, "project_id"); param != "" {
+		var err error
+
+		if projID, err = strconv.ParseUint(param, 0, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := app.store.Get(r, app.cookieName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userID, ok := session.Values["user_id"].(uint)
+
+	if !ok {
+		return nil, nil
+	}
+
+	proj, err := app.repo.Project.ReadProject(uint(projID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var kind string
+
+	for _, role := range proj.Roles {
+		if role.UserID == userID {
+			kind = role.Kind
+			break
+		}
+	}
+
+	if kind == "" {
+		return nil, nil
+	}
+
+	imp, err := app.repo.Project.ReadProjectRoleImpersonation(uint(projID), kind)
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return imp, nil
+}
+
+// getK8sAgentFromQueryParams uses the query params to populate the cluster of a
+// K8sForm, validates the form that embeds it, and then creates a new Kubernetes
+// agent from the resulting config.
+func (app *App) getK8sAgentFromQueryParams(
+	w http.ResponseWriter,
+	r *http.Request,
+	k8sForm *forms.K8sForm,
+	form interface{},
+) (*kubernetes.Agent, error) {
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return nil, err
+	}
+
+	if err := k8sForm.PopulateK8sOptionsFromQueryParams(vals, app.repo.Cluster); err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return nil, err
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return nil, err
+	}
+
+	if app.testing {
+		return app.TestAgents.K8sAgent, nil
+	}
+
+	agent, err := app.getK8sAgent(r, k8sForm.OutOfClusterConfig)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// getResourceFormAndAgent populates a ResourceForm from the query params and the
+// name URL param, and creates a Kubernetes agent from it
+func (app *App) getResourceFormAndAgent(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*forms.ResourceForm, *kubernetes.Agent, error) {
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrK8sDecode, w)
+		return nil, nil, err
+	}
+
+	form := &forms.ResourceForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Name: chi.URLParam(r, "name"),
+	}
+
+	form.PopulateResourceFromQueryParams(vals)
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return form, agent, nil
+}
+
+// handleErrorK8sRead sends a not found error if the api server does not serve the
+// requested resource or object, and a generic read error otherwise
+func (app *App) handleErrorK8sRead(err error, w http.ResponseWriter) {
+	if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrK8sReadData,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+		Code:   ErrK8sReadData,
+		Errors: []string{"could not read from cluster"},
+	}, w)
+}
@@ -1,7 +1,9 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -9,9 +11,12 @@ import (
 	"testing"
 
 	"github.com/porter-dev/porter/internal/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -96,6 +101,280 @@ func TestHandleListNamespaces(t *testing.T) {
 	testK8sRequests(t, listNamespacesTests, true)
 }
 
+var createNamespaceTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Create namespace with quota",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/namespaces?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{"name":"team-a","labels":{"team":"a"},"resource_quota":{"requests.cpu":"4"}}`,
+		expStatus: http.StatusCreated,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sNamespaceCreatedValidator,
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Create namespace with invalid quota",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/namespaces?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{"name":"team-a","resource_quota":{"requests.cpu":"lots"}}`,
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":600,"errors":["could not process request"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			func(tester *tester) {
+				clientset := tester.app.TestAgents.K8sAgent.Clientset.(*fake.Clientset)
+
+				clientset.PrependReactor("create", "limitranges", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, fmt.Errorf("limit range rejected")
+				})
+			},
+		},
+		msg:    "Create namespace with rejected limit range",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/namespaces?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{"name":"team-a","resource_quota":{"requests.cpu":"4"},"limit_range":{"default":{"cpu":"1"}}}`,
+		expStatus: http.StatusInternalServerError,
+		expBody:   `{"code":602,"errors":["error creating limit range limit range rejected"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				clientset := tester.app.TestAgents.K8sAgent.Clientset
+
+				if _, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "team-a", metav1.GetOptions{}); err == nil {
+					t.Errorf("%s, expected namespace to be deleted", c.msg)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleCreateNamespace(t *testing.T) {
+	testK8sRequests(t, createNamespaceTests, true)
+}
+
+var deleteNamespaceTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Delete namespace",
+		method: "DELETE",
+		endpoint: "/api/projects/1/k8s/namespaces/test-namespace-0?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				namespaces, _ := tester.app.TestAgents.K8sAgent.ListNamespaces()
+
+				if len(namespaces.Items) != 1 || namespaces.Items[0].Name != "test-namespace-1" {
+					t.Errorf("%s, namespace was not deleted", c.msg)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleDeleteNamespace(t *testing.T) {
+	testK8sRequests(t, deleteNamespaceTests, true)
+}
+
+var listNodesTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "List nodes",
+		method: "GET",
+		endpoint: "/api/projects/1/k8s/nodes?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				gotBody := &kubernetes.ClusterCapacity{}
+
+				json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+
+				if len(gotBody.Nodes) != 1 || gotBody.Nodes[0].Name != "test-node" {
+					t.Errorf("%s, handler returned wrong body: got %v", c.msg, gotBody.Nodes)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleListNodes(t *testing.T) {
+	testK8sRequests(t, listNodesTests, true)
+}
+
+var cordonNodeTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Cordon node",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/nodes/test-node/cordon?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				node, _ := tester.app.TestAgents.K8sAgent.Clientset.CoreV1().Nodes().Get(
+					context.TODO(),
+					"test-node",
+					metav1.GetOptions{},
+				)
+
+				if node == nil || !node.Spec.Unschedulable {
+					t.Errorf("%s, node was not cordoned", c.msg)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleCordonNode(t *testing.T) {
+	testK8sRequests(t, cordonNodeTests, true)
+}
+
+var scaleControllerTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Scale deployment",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/default/deployment/test-deployment/scale?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{"replicas":3}`,
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sDeploymentReplicasValidator(3),
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Scale unsupported kind",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/default/daemonset/test-deployment/scale?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{"replicas":3}`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["oneof validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Scale deployment without replicas",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/default/deployment/test-deployment/scale?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      `{}`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["required validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+		},
+	},
+}
+
+func TestHandleScaleController(t *testing.T) {
+	testK8sRequests(t, scaleControllerTests, true)
+}
+
+var restartControllerTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Restart deployment",
+		method: "POST",
+		endpoint: "/api/projects/1/k8s/default/deployment/test-deployment/restart?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sDeploymentRestartedValidator,
+		},
+	},
+}
+
+func TestHandleRestartController(t *testing.T) {
+	testK8sRequests(t, restartControllerTests, true)
+}
+
+var deletePodTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:    "Delete pod",
+		method: "DELETE",
+		endpoint: "/api/projects/1/k8s/default/pod/test-pod?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "",
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sPodDeletedValidator,
+		},
+	},
+}
+
+func TestHandleDeletePod(t *testing.T) {
+	testK8sRequests(t, deletePodTests, true)
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
 var defaultObjects = []runtime.Object{
@@ -111,12 +390,32 @@ var defaultObjects = []runtime.Object{
 	},
 }
 
+var defaultWorkloadObjects = []runtime.Object{
+	&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+	},
+	&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+		},
+	},
+	&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	},
+}
+
 func initDefaultK8s(tester *tester) {
 	initUserDefault(tester)
 	initProject(tester)
 	initProjectClusterDefault(tester)
 
-	agent := kubernetes.GetAgentTesting(defaultObjects...)
+	agent := kubernetes.GetAgentTesting(append(defaultObjects, defaultWorkloadObjects...)...)
 
 	// overwrite the test agent with new resources
 	tester.app.TestAgents.K8sAgent = agent
@@ -140,3 +439,81 @@ func k8sNamespaceListValidator(c *k8sTest, tester *tester, t *testing.T) {
 			c.msg, gotBody.Items, expBody)
 	}
 }
+
+func k8sBodyValidator(c *k8sTest, tester *tester, t *testing.T) {
+	if body := tester.rr.Body.String(); strings.TrimSpace(body) != c.expBody {
+		t.Errorf("%s, handler returned wrong body: got %v want %v",
+			c.msg, body, c.expBody)
+	}
+}
+
+func k8sDeploymentReplicasValidator(replicas int32) func(c *k8sTest, tester *tester, t *testing.T) {
+	return func(c *k8sTest, tester *tester, t *testing.T) {
+		depl, err := tester.app.TestAgents.K8sAgent.Clientset.AppsV1().Deployments("default").Get(
+			context.TODO(),
+			"test-deployment",
+			metav1.GetOptions{},
+		)
+
+		if err != nil {
+			t.Fatalf("%s, %v", c.msg, err)
+		}
+
+		if depl.Spec.Replicas == nil || *depl.Spec.Replicas != replicas {
+			t.Errorf("%s, replicas not updated: got %v want %d",
+				c.msg, depl.Spec.Replicas, replicas)
+		}
+	}
+}
+
+func k8sDeploymentRestartedValidator(c *k8sTest, tester *tester, t *testing.T) {
+	depl, err := tester.app.TestAgents.K8sAgent.Clientset.AppsV1().Deployments("default").Get(
+		context.TODO(),
+		"test-deployment",
+		metav1.GetOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf("%s, %v", c.msg, err)
+	}
+
+	if _, ok := depl.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"]; !ok {
+		t.Errorf("%s, restartedAt annotation was not set", c.msg)
+	}
+}
+
+func k8sPodDeletedValidator(c *k8sTest, tester *tester, t *testing.T) {
+	_, err := tester.app.TestAgents.K8sAgent.Clientset.CoreV1().Pods("default").Get(
+		context.TODO(),
+		"test-pod",
+		metav1.GetOptions{},
+	)
+
+	if err == nil {
+		t.Errorf("%s, pod was not deleted", c.msg)
+	}
+}
+
+func k8sNamespaceCreatedValidator(c *k8sTest, tester *tester, t *testing.T) {
+	clientset := tester.app.TestAgents.K8sAgent.Clientset
+
+	ns, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "team-a", metav1.GetOptions{})
+
+	if err != nil {
+		t.Fatalf("%s, %v", c.msg, err)
+	}
+
+	if ns.Labels["team"] != "a" {
+		t.Errorf("%s, wrong namespace labels: got %v", c.msg, ns.Labels)
+	}
+
+	quota, err := clientset.CoreV1().ResourceQuotas("team-a").Get(context.TODO(), "porter-default", metav1.GetOptions{})
+
+	if err != nil {
+		t.Fatalf("%s, %v", c.msg, err)
+	}
+
+	if cpu := quota.Spec.Hard[v1.ResourceName("requests.cpu")]; cpu.String() != "4" {
+		t.Errorf("%s, wrong resource quota: got %s want %s", c.msg, cpu.String(), "4")
+	}
+}
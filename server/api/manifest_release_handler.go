@@ -0,0 +1,440 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/manifests"
+	"github.com/porter-dev/porter/internal/templater/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PorterManifestRelease is a release of raw manifests or Kustomize, with the
+// objects rendered from its files and its form
+type PorterManifestRelease struct {
+	*models.ManifestReleaseExternal
+	Objects []*unstructured.Unstructured `json:"objects"`
+	Form    *models.FormYAML             `json:"form"`
+}
+
+// HandleDeployManifests deploys a release of raw manifests, or of a Kustomize base
+// and overlay, to a cluster. The files of the release are stored in Porter, and the
+// values are written to the objects of the release before they are applied.
+func (app *App) HandleDeployManifests(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.CreateManifestReleaseForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form.K8sForm)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	if form.Cluster == nil {
+		app.sendExternalError(fmt.Errorf("no cluster"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"cluster_id is required"},
+		}, w)
+
+		return
+	}
+
+	if _, err := app.repo.ManifestRelease.ReadManifestRelease(form.Cluster.ID, form.Namespace, form.Name); err == nil {
+		app.sendExternalError(err, http.StatusConflict, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"release already exists"},
+		}, w)
+
+		return
+	}
+
+	mapper, err := agent.RESTClientGetter.ToRESTMapper()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	rel := form.ToManifestRelease(uint(projID), form.Cluster.ID)
+
+	if !app.validateManifestFormValues(w, rel, form.Values) {
+		return
+	}
+
+	// the values are written and the objects rendered before the release is stored,
+	// so that invalid files or values do not leave a release behind
+	err = manifests.PatchFiles(rel, form.Values)
+
+	if err == nil {
+		_, err = manifests.Render(rel)
+	}
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusUnprocessableEntity, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"invalid manifests: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	writer := &manifests.ManifestsTemplateWriter{
+		Release: rel,
+		Repo:    app.repo.ManifestRelease,
+		Client:  agent.DynamicClient,
+		Mapper:  mapper,
+	}
+
+	if _, err := writer.Create(nil); err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deploying manifests: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(rel.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleGetManifestRelease returns a release of raw manifests or Kustomize, with the
+// objects rendered from its files and the form parsed from its form.yaml. The
+// contents of the form read from the release's objects with the manifests context.
+func (app *App) HandleGetManifestRelease(w http.ResponseWriter, r *http.Request) {
+	rel, parserDef, err := app.getManifestReleaseFromParams(w, r)
+
+	// errors are handled in app.getManifestReleaseFromParams
+	if err != nil {
+		return
+	}
+
+	objs, err := manifests.Render(rel)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"could not render release: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	res := &PorterManifestRelease{rel.Externalize(), objs, nil}
+
+	if formBytes := manifestFormBytes(rel); formBytes != nil {
+		if formYAML, err := parser.FormYAMLFromBytes(parserDef, formBytes); err == nil {
+			res.Form = formYAML
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleUpgradeManifestRelease upgrades a release of raw manifests or Kustomize. If
+// files are passed, they replace the files of the release, and the values are then
+// written to the objects of the release before they are applied.
+func (app *App) HandleUpgradeManifestRelease(w http.ResponseWriter, r *http.Request) {
+	form := &forms.UpgradeManifestReleaseForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rel, parserDef, err := app.getManifestReleaseFromParams(w, r)
+
+	// errors are handled in app.getManifestReleaseFromParams
+	if err != nil {
+		return
+	}
+
+	// the objects of the release before its files are replaced, so that the objects
+	// removed from the files are deleted
+	prev, err := manifests.Render(rel)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if len(form.Files) > 0 {
+		rel.Files = form.ToManifestFiles()
+	}
+
+	if !app.validateManifestFormValues(w, rel, form.Values) {
+		return
+	}
+
+	writer := &manifests.ManifestsTemplateWriter{
+		Release:  rel,
+		Repo:     app.repo.ManifestRelease,
+		Client:   parserDef.DynamicClient,
+		Mapper:   parserDef.RESTMapper,
+		Previous: prev,
+	}
+
+	if _, err := writer.Update(form.Values); err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error upgrading manifests: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(rel.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleDeleteManifestRelease deletes the objects of a release of raw manifests or
+// Kustomize from the cluster, and then deletes the release from Porter
+func (app *App) HandleDeleteManifestRelease(w http.ResponseWriter, r *http.Request) {
+	rel, parserDef, err := app.getManifestReleaseFromParams(w, r)
+
+	// errors are handled in app.getManifestReleaseFromParams
+	if err != nil {
+		return
+	}
+
+	objs, err := manifests.Render(rel)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if err := manifests.Delete(parserDef.DynamicClient, parserDef.RESTMapper, rel.Namespace, objs); err != nil {
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{"error deleting manifests: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := app.repo.ManifestRelease.DeleteManifestRelease(rel); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleSubmitManifestReleaseForm writes the values submitted from the form of a
+// release of raw manifests or Kustomize to the contexts of the form
+func (app *App) HandleSubmitManifestReleaseForm(w http.ResponseWriter, r *http.Request) {
+	form := &forms.SubmitReleaseFormForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rel, parserDef, err := app.getManifestReleaseFromParams(w, r)
+
+	// errors are handled in app.getManifestReleaseFromParams
+	if err != nil {
+		return
+	}
+
+	formBytes := manifestFormBytes(rel)
+
+	if formBytes == nil {
+		app.sendExternalError(fmt.Errorf("no form.yaml"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"release has no form.yaml"},
+		}, w)
+
+		return
+	}
+
+	results, err := parser.WriteFormYAMLValues(parserDef, formBytes, form.Values)
+
+	if fieldErrs, ok := err.(parser.FieldErrors); ok {
+		app.handleErrorFormFieldValidation(fieldErrs, w)
+		return
+	} else if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// getManifestReleaseFromParams reads the manifest release identified by the
+// namespace and name URL params and the cluster_id query param, along with the
+// parser config to read and write its form with
+func (app *App) getManifestReleaseFromParams(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*models.ManifestRelease, *parser.ClientConfigDefault, error) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, nil, err
+	}
+
+	form := &forms.UpgradeManifestReleaseForm{
+		K8sForm: &forms.K8sForm{
+			OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+				Repo: app.repo,
+			},
+		},
+		Namespace: chi.URLParam(r, "namespace"),
+		Name:      chi.URLParam(r, "name"),
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, form.K8sForm, form)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if form.Cluster == nil {
+		err := fmt.Errorf("no cluster")
+
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"cluster_id is required"},
+		}, w)
+
+		return nil, nil, err
+	}
+
+	rel, err := app.repo.ManifestRelease.ReadManifestRelease(form.Cluster.ID, form.Namespace, form.Name)
+
+	if err != nil || rel.ProjectID != uint(projID) {
+		if err == nil {
+			err = fmt.Errorf("release not in project")
+		}
+
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return nil, nil, err
+	}
+
+	mapper, err := agent.RESTClientGetter.ToRESTMapper()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return nil, nil, err
+	}
+
+	parserDef := &parser.ClientConfigDefault{
+		DynamicClient:   agent.DynamicClient,
+		Repo:            app.repo,
+		ProjectID:       uint(projID),
+		ManifestRelease: rel,
+		RESTMapper:      mapper,
+	}
+
+	return rel, parserDef, nil
+}
+
+// validateManifestFormValues validates the values written to the objects of a
+// release of raw manifests or Kustomize against the rules of the release's form, if
+// it has one. If the values are invalid, the errors are sent and false is returned.
+func (app *App) validateManifestFormValues(
+	w http.ResponseWriter,
+	rel *models.ManifestRelease,
+	vals map[string]interface{},
+) bool {
+	formBytes := manifestFormBytes(rel)
+
+	if formBytes == nil {
+		return true
+	}
+
+	objects, err := (&manifests.ManifestsTemplateReader{Release: rel}).ValuesFromTarget()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusUnprocessableEntity, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"invalid manifests: " + err.Error()},
+		}, w)
+
+		return false
+	}
+
+	fieldErrs, err := parser.ValidateManifestFormYAMLValues(formBytes, vals, objects)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusUnprocessableEntity, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"error validating values: " + err.Error()},
+		}, w)
+
+		return false
+	}
+
+	if len(fieldErrs) > 0 {
+		app.handleErrorFormFieldValidation(fieldErrs, w)
+		return false
+	}
+
+	return true
+}
+
+// manifestFormBytes returns the form.yaml at the root of a manifest release's
+// files, or nil if the release has no form
+func manifestFormBytes(rel *models.ManifestRelease) []byte {
+	for _, file := range rel.Files {
+		if path.Clean(file.Path) == "form.yaml" {
+			return []byte(file.Data)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,417 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/templater/manifests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+const manifestDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`
+
+const manifestForm = `name: web
+tabs:
+- name: main
+  label: Main
+  context:
+    type: manifests
+  sections:
+  - name: scaling
+    contents:
+    - type: number-input
+      label: Replicas
+      variable: Deployment.web.spec.replicas
+      settings:
+        max: 10
+`
+
+var manifestsEndpoint = "/api/projects/1/manifests?" + url.Values{
+	"cluster_id": []string{"1"},
+}.Encode()
+
+var manifestReleaseEndpoint = "/api/projects/1/manifests/default/web%s?" + url.Values{
+	"cluster_id": []string{"1"},
+}.Encode()
+
+func manifestsBody(vals map[string]interface{}) string {
+	body, _ := json.Marshal(vals)
+
+	return string(body)
+}
+
+var deployManifestsBody = manifestsBody(map[string]interface{}{
+	"name":      "web",
+	"namespace": "default",
+	"kind":      "manifests",
+	"files": map[string]string{
+		"web.yaml":  manifestDeployment,
+		"form.yaml": manifestForm,
+	},
+	"values": map[string]interface{}{
+		"Deployment": map[string]interface{}{
+			"web": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 2,
+				},
+			},
+		},
+	},
+})
+
+var deployManifestsTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Deploy manifests",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      deployManifestsBody,
+		expStatus: http.StatusCreated,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			manifestReleaseValidator(1),
+			manifestDeploymentReplicasValidator(2),
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+		},
+		msg:       "Deploy manifests that already exist",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      deployManifestsBody,
+		expStatus: http.StatusConflict,
+		useCookie: true,
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Deploy manifests with an unknown kind",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      strings.Replace(deployManifestsBody, `"kind":"manifests"`, `"kind":"jsonnet"`, 1),
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["oneof validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Deploy manifests without files",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      `{"name":"web","namespace":"default","kind":"manifests","files":{}}`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["min validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Deploy manifests with values for a missing object",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      strings.Replace(deployManifestsBody, `"Deployment":{"web"`, `"Deployment":{"api"`, 1),
+		expStatus: http.StatusUnprocessableEntity,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				if _, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web"); err == nil {
+					t.Errorf("%s, expected no release to be stored", c.msg)
+				}
+			},
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Deploy manifests with invalid form values",
+		method:    "POST",
+		endpoint:  manifestsEndpoint,
+		body:      strings.Replace(deployManifestsBody, `"replicas":2`, `"replicas":20`, 1),
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["Deployment.web.spec.replicas: must be at most 10"],"fields":[{"key":"tabs[0].sections[0].contents[0]","variable":"Deployment.web.spec.replicas","message":"must be at most 10"}]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				if _, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web"); err == nil {
+					t.Errorf("%s, expected no release to be stored", c.msg)
+				}
+			},
+		},
+	},
+}
+
+func TestHandleDeployManifests(t *testing.T) {
+	testK8sRequests(t, deployManifestsTests, true)
+}
+
+var getManifestReleaseTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+		},
+		msg:       "Get manifest release",
+		method:    "GET",
+		endpoint:  strings.Replace(manifestReleaseEndpoint, "%s", "", 1),
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				res := &struct {
+					Revision uint                         `json:"revision"`
+					Objects  []*unstructured.Unstructured `json:"objects"`
+					Form     *models.FormYAML             `json:"form"`
+				}{}
+
+				json.Unmarshal(tester.rr.Body.Bytes(), res)
+
+				if len(res.Objects) != 1 || res.Objects[0].GetName() != "web" {
+					t.Errorf("%s, wrong objects: got %v", c.msg, res.Objects)
+				}
+
+				if res.Form == nil {
+					t.Fatalf("%s, expected form", c.msg)
+				}
+
+				content := res.Form.Tabs[0].Sections[0].Contents[0]
+
+				if content.Value == nil {
+					t.Errorf("%s, expected replicas to be read into the form", c.msg)
+				}
+			},
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Get manifest release that does not exist",
+		method:    "GET",
+		endpoint:  strings.Replace(manifestReleaseEndpoint, "%s", "", 1),
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+}
+
+func TestHandleGetManifestRelease(t *testing.T) {
+	testK8sRequests(t, getManifestReleaseTests, true)
+}
+
+var upgradeManifestReleaseTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+		},
+		msg:      "Upgrade manifest release",
+		method:   "POST",
+		endpoint: strings.Replace(manifestReleaseEndpoint, "%s", "/upgrade", 1),
+		body: manifestsBody(map[string]interface{}{
+			"values": map[string]interface{}{
+				"Deployment": map[string]interface{}{
+					"web": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"replicas": 4,
+						},
+					},
+				},
+			},
+		}),
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			manifestReleaseValidator(2),
+			manifestDeploymentReplicasValidator(4),
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+		},
+		msg:      "Upgrade manifest release with invalid form values",
+		method:   "POST",
+		endpoint: strings.Replace(manifestReleaseEndpoint, "%s", "/upgrade", 1),
+		body: manifestsBody(map[string]interface{}{
+			"values": map[string]interface{}{
+				"Deployment": map[string]interface{}{
+					"web": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"replicas": 20,
+						},
+					},
+				},
+			},
+		}),
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["Deployment.web.spec.replicas: must be at most 10"],"fields":[{"key":"tabs[0].sections[0].contents[0]","variable":"Deployment.web.spec.replicas","message":"must be at most 10"}]}`,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			k8sBodyValidator,
+			manifestReleaseValidator(1),
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+		},
+		msg:       "Submit manifest release form",
+		method:    "POST",
+		endpoint:  strings.Replace(manifestReleaseEndpoint, "%s", "/form", 1),
+		body:      `{"values":{"Deployment.web.spec.replicas":3}}`,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			manifestReleaseValidator(2),
+			manifestDeploymentReplicasValidator(3),
+		},
+	},
+}
+
+func TestHandleUpgradeManifestRelease(t *testing.T) {
+	testK8sRequests(t, upgradeManifestReleaseTests, true)
+}
+
+var deleteManifestReleaseTests = []*k8sTest{
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+			initManifestReleaseDefault,
+			initManifestReleaseObjects,
+		},
+		msg:       "Delete manifest release",
+		method:    "DELETE",
+		endpoint:  strings.Replace(manifestReleaseEndpoint, "%s", "", 1),
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *k8sTest, tester *tester, t *testing.T){
+			func(c *k8sTest, tester *tester, t *testing.T) {
+				if _, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web"); err == nil {
+					t.Errorf("%s, expected release to be deleted", c.msg)
+				}
+
+				gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+				_, err := tester.app.TestAgents.K8sAgent.DynamicClient.Resource(gvr).Namespace("default").Get(
+					context.TODO(),
+					"web",
+					metav1.GetOptions{},
+				)
+
+				if err == nil {
+					t.Errorf("%s, expected deployment to be deleted", c.msg)
+				}
+			},
+		},
+	},
+	&k8sTest{
+		initializers: []func(tester *tester){
+			initDefaultK8s,
+		},
+		msg:       "Delete manifest release that does not exist",
+		method:    "DELETE",
+		endpoint:  strings.Replace(manifestReleaseEndpoint, "%s", "", 1),
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+}
+
+func TestHandleDeleteManifestRelease(t *testing.T) {
+	testK8sRequests(t, deleteManifestReleaseTests, true)
+}
+
+func initManifestReleaseDefault(tester *tester) {
+	tester.repo.ManifestRelease.CreateManifestRelease(&models.ManifestRelease{
+		ProjectID: 1,
+		ClusterID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Kind:      models.ManifestKindRaw,
+		Revision:  1,
+		Files: []models.ManifestFile{
+			models.ManifestFile{Path: "form.yaml", Data: manifestForm},
+			models.ManifestFile{Path: "web.yaml", Data: manifestDeployment},
+		},
+	})
+}
+
+// initManifestReleaseObjects applies the objects of the default manifest release to
+// the cluster
+func initManifestReleaseObjects(tester *tester) {
+	rel, _ := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web")
+	objs, _ := manifests.Render(rel)
+	agent := tester.app.TestAgents.K8sAgent
+	mapper, _ := agent.RESTClientGetter.ToRESTMapper()
+
+	manifests.Apply(agent.DynamicClient, mapper, "default", objs)
+}
+
+func manifestReleaseValidator(revision uint) func(c *k8sTest, tester *tester, t *testing.T) {
+	return func(c *k8sTest, tester *tester, t *testing.T) {
+		rel, err := tester.repo.ManifestRelease.ReadManifestRelease(1, "default", "web")
+
+		if err != nil {
+			t.Fatalf("%s, %v", c.msg, err)
+		}
+
+		if rel.Revision != revision {
+			t.Errorf("%s, wrong revision: got %d want %d", c.msg, rel.Revision, revision)
+		}
+	}
+}
+
+func manifestDeploymentReplicasValidator(replicas int64) func(c *k8sTest, tester *tester, t *testing.T) {
+	return func(c *k8sTest, tester *tester, t *testing.T) {
+		gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+		obj, err := tester.app.TestAgents.K8sAgent.DynamicClient.Resource(gvr).Namespace("default").Get(
+			context.TODO(),
+			"web",
+			metav1.GetOptions{},
+		)
+
+		if err != nil {
+			t.Fatalf("%s, %v", c.msg, err)
+		}
+
+		got, _, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas")
+
+		if got != float64(replicas) && got != replicas {
+			t.Errorf("%s, wrong replicas: got %v want %d", c.msg, got, replicas)
+		}
+	}
+}
@@ -138,6 +138,165 @@ func TestHandleDeleteProject(t *testing.T) {
 	testProjRequests(t, deleteProjectTests, true)
 }
 
+var updateRoleImpersonationTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:      "Map role to Kubernetes identity",
+		method:   "POST",
+		endpoint: "/api/projects/1/roles/viewer/impersonation",
+		body: `{
+			"user": "porter-viewer",
+			"groups": ["viewers", " auditors "]
+		}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"id":1,"project_id":1,"role_kind":"viewer","user":"porter-viewer","groups":["viewers","auditors"]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRoleImpersonation,
+		},
+		msg:      "Replace role mapping",
+		method:   "POST",
+		endpoint: "/api/projects/1/roles/viewer/impersonation",
+		body: `{
+			"user": "porter-readonly"
+		}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"id":1,"project_id":1,"role_kind":"viewer","user":"porter-readonly","groups":[]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Map role without user",
+		method:    "POST",
+		endpoint:  "/api/projects/1/roles/viewer/impersonation",
+		body:      `{"groups": ["viewers"]}`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["required validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:      "Map role with conflicting project and role in body",
+		method:   "POST",
+		endpoint: "/api/projects/1/roles/viewer/impersonation",
+		body: `{
+			"projectid": 2,
+			"rolekind": "admin",
+			"user": "porter-viewer"
+		}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"id":1,"project_id":1,"role_kind":"viewer","user":"porter-viewer","groups":[]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+			func(c *projTest, tester *tester, t *testing.T) {
+				imps, _ := tester.repo.Project.ListProjectRoleImpersonations(2)
+
+				if len(imps) != 0 {
+					t.Errorf("%s, expected no mapping for project 2, got %d", c.msg, len(imps))
+				}
+			},
+		},
+	},
+}
+
+func TestHandleUpdateProjectRoleImpersonation(t *testing.T) {
+	testProjRequests(t, updateRoleImpersonationTests, true)
+}
+
+var listRoleImpersonationTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRoleImpersonation,
+		},
+		msg:       "List role mappings",
+		method:    "GET",
+		endpoint:  "/api/projects/1/roles/impersonation",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   `[{"id":1,"project_id":1,"role_kind":"viewer","user":"porter-viewer","groups":["viewers"]}]`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleListProjectRoleImpersonations(t *testing.T) {
+	testProjRequests(t, listRoleImpersonationTests, true)
+}
+
+var deleteRoleImpersonationTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRoleImpersonation,
+		},
+		msg:       "Delete role mapping",
+		method:    "DELETE",
+		endpoint:  "/api/projects/1/roles/viewer/impersonation",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   ``,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+			func(c *projTest, tester *tester, t *testing.T) {
+				imps, _ := tester.repo.Project.ListProjectRoleImpersonations(1)
+
+				if len(imps) != 0 {
+					t.Errorf("%s, expected role mapping to be deleted, got %d mappings", c.msg, len(imps))
+				}
+			},
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Delete missing role mapping",
+		method:    "DELETE",
+		endpoint:  "/api/projects/1/roles/admin/impersonation",
+		body:      ``,
+		expStatus: http.StatusNotFound,
+		expBody:   `{"code":602,"errors":["could not find requested object"]}`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleDeleteProjectRoleImpersonation(t *testing.T) {
+	testProjRequests(t, deleteRoleImpersonationTests, true)
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
 func initProject(tester *tester) {
@@ -175,3 +334,12 @@ func projectModelBodyValidator(c *projTest, tester *tester, t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func initRoleImpersonation(tester *tester) {
+	tester.repo.Project.UpdateProjectRoleImpersonation(&models.RoleImpersonation{
+		ProjectID: 1,
+		RoleKind:  models.RoleViewer,
+		User:      "porter-viewer",
+		Groups:    "viewers",
+	})
+}
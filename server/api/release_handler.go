@@ -1,14 +1,18 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/templater/parser"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 
 	"github.com/go-chi/chi"
@@ -71,7 +75,8 @@ type PorterRelease struct {
 	Form *models.FormYAML `json:"form"`
 }
 
-// HandleGetRelease retrieves a single release based on a name and revision
+// HandleGetRelease retrieves a single release based on a name and revision, along
+// with the form of its chart, which is generated if the chart has no form.yaml
 func (app *App) HandleGetRelease(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	revision, err := strconv.ParseUint(chi.URLParam(r, "revision"), 0, 64)
@@ -131,40 +136,125 @@ func (app *App) HandleGetRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// create a new dynamic client
-	dynClient, err := kubernetes.GetDynamicClientOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+	// get an agent for its dynamic client
+	k8sAgent, err := app.getK8sAgent(r, k8sForm.OutOfClusterConfig)
 
 	if err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
 		return
 	}
 
+	projID, _ := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
 	parserDef := &parser.ClientConfigDefault{
-		DynamicClient: dynClient,
+		DynamicClient: k8sAgent.DynamicClient,
+		HelmAgent:     agent,
 		HelmChart:     release.Chart,
 		HelmRelease:   release,
+		Repo:          app.repo,
+		ProjectID:     uint(projID),
 	}
 
 	res := &PorterRelease{release, nil}
 
-	for _, file := range release.Chart.Files {
-		if strings.Contains(file.Name, "form.yaml") {
-			formYAML, _ := parser.FormYAMLFromBytes(parserDef, file.Data)
-			if err != nil {
-				break
-			}
-
+	if formBytes := chartFormBytes(release.Chart); formBytes != nil {
+		if formYAML, err := parser.FormYAMLFromBytes(parserDef, formBytes); err == nil {
 			res.Form = formYAML
-			break
 		}
 	}
 
-	if err := json.NewEncoder(w).Encode(release); err != nil {
+	if err := json.NewEncoder(w).Encode(res); err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
 		return
 	}
 }
 
+// HandleStreamReleaseForm streams the values of the live contexts in the form of a
+// release, identified by name and revision, over a websocket. Each message has the
+// kind of change and the updated values, keyed by the path of the form content.
+func (app *App) HandleStreamReleaseForm(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	revision, err := strconv.ParseUint(chi.URLParam(r, "revision"), 0, 64)
+
+	form := &forms.GetReleaseForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo: app.repo,
+			},
+		},
+		Name:     name,
+		Revision: int(revision),
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form.ReleaseForm,
+		form.ReleaseForm.PopulateHelmOptionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	release, err := agent.GetRelease(form.Name, form.Revision)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	formBytes, parserDef, err := app.getReleaseFormParserDef(w, r, agent, release)
+
+	// errors are handled in app.getReleaseFormParserDef
+	if err != nil {
+		return
+	}
+
+	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+
+	// upgrade to websocket.
+	conn, err := upgrader.Upgrade(w, r, nil)
+
+	if err != nil {
+		app.handleErrorUpgradeWebsocket(err, w)
+		return
+	}
+
+	defer conn.Close()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	// the live contexts stream from separate informers, so writes to the
+	// websocket are serialized
+	var mu sync.Mutex
+
+	err = parser.StreamFormYAMLValues(parserDef, formBytes, func(val map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return conn.WriteJSON(val)
+	}, stopCh)
+
+	if err != nil {
+		app.logger.Warn().Err(err).Msgf("Could not stream form of release: %s", form.Name)
+		return
+	}
+
+	// listens for websocket closing handshake
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
 // HandleGetReleaseComponents retrieves kubernetes objects listed in a release identified by name and revision
 func (app *App) HandleGetReleaseComponents(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
@@ -203,16 +293,26 @@ func (app *App) HandleGetReleaseComponents(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	yamlArr := grapher.ImportMultiDocYAML([]byte(release.Manifest))
-	objects := grapher.ParseObjs(yamlArr)
+	parsed := grapher.ParseManifest([]byte(release.Manifest))
 
-	parsed := grapher.ParsedObjs{
-		Objects: objects,
-	}
+	// the graph can also be exported to formats that other tools can render
+	if format := r.URL.Query().Get("format"); format != "" && format != grapher.FormatJSON {
+		var buf bytes.Buffer
+
+		if err := parsed.Export(&buf, release.Name, format); err != nil {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrReleaseValidateFields,
+				Errors: []string{err.Error()},
+			}, w)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", grapher.ContentType(format))
+		w.Write(buf.Bytes())
 
-	parsed.GetControlRel()
-	parsed.GetLabelRel()
-	parsed.GetSpecRel()
+		return
+	}
 
 	if err := json.NewEncoder(w).Encode(parsed); err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
@@ -220,6 +320,64 @@ func (app *App) HandleGetReleaseComponents(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// HandleGetReleaseGraphDiff compares the graphs of the components of two revisions
+// of a release, which are passed as the from and to query params
+func (app *App) HandleGetReleaseGraphDiff(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	form := &forms.DiffReleaseGraphForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo: app.repo,
+			},
+		},
+		Name: name,
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form.ReleaseForm,
+		form.ReleaseForm.PopulateHelmOptionsFromQueryParams,
+		form.PopulateRevisionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	// validate the revisions
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	graphs := make([]*grapher.ParsedObjs, 0)
+
+	for _, revision := range []int{form.From, form.To} {
+		release, err := agent.GetRelease(form.Name, revision)
+
+		if err != nil {
+			app.sendExternalError(err, http.StatusNotFound, HTTPError{
+				Code:   ErrReleaseReadData,
+				Errors: []string{"release not found"},
+			}, w)
+
+			return
+		}
+
+		graphs = append(graphs, grapher.ParseManifest([]byte(release.Manifest)))
+	}
+
+	diff := grapher.Diff(graphs[0], graphs[1])
+
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
 // HandleGetReleaseControllers retrieves controllers that belong to a release.
 // Used to display status of charts.
 func (app *App) HandleGetReleaseControllers(w http.ResponseWriter, r *http.Request) {
@@ -287,7 +445,7 @@ func (app *App) HandleGetReleaseControllers(w http.ResponseWriter, r *http.Reque
 	if app.testing {
 		k8sAgent = app.TestAgents.K8sAgent
 	} else {
-		k8sAgent, err = kubernetes.GetAgentOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+		k8sAgent, err = app.getK8sAgent(r, k8sForm.OutOfClusterConfig)
 	}
 
 	yamlArr := grapher.ImportMultiDocYAML([]byte(release.Manifest))
@@ -348,6 +506,74 @@ func (app *App) HandleGetReleaseControllers(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// HandleGetReleaseEndpoints resolves the URLs at which a release can be reached, from
+// the Ingresses and LoadBalancer or NodePort Services in its manifest
+func (app *App) HandleGetReleaseEndpoints(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	revision, err := strconv.ParseUint(chi.URLParam(r, "revision"), 0, 64)
+
+	form := &forms.GetReleaseForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo: app.repo,
+			},
+		},
+		Name:     name,
+		Revision: int(revision),
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form.ReleaseForm,
+		form.ReleaseForm.PopulateHelmOptionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	release, err := agent.GetRelease(form.Name, form.Revision)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	k8sForm := &forms.K8sForm{
+		OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+			Repo: app.repo,
+		},
+	}
+
+	k8sAgent, err := app.getK8sAgentFromQueryParams(w, r, k8sForm, k8sForm)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	yamlArr := grapher.ImportMultiDocYAML([]byte(release.Manifest))
+	objects := grapher.ParseObjs(yamlArr)
+
+	endpoints, err := k8sAgent.GetEndpoints(objects, release.Namespace)
+
+	if err != nil {
+		app.handleErrorK8sRead(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(endpoints); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
 // HandleListReleaseHistory retrieves a history of releases based on a release name
 func (app *App) HandleListReleaseHistory(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
@@ -431,7 +657,33 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = agent.UpgradeRelease(form.Name, form.Values)
+	rel, err := agent.GetRelease(form.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	values, err := chartutil.ReadValues([]byte(form.Values))
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"values could not be parsed: " + err.Error()},
+		}, w)
+
+		return
+	}
+
+	if !app.applyFormValues(w, rel.Chart, values) {
+		return
+	}
+
+	_, err = agent.UpgradeReleaseByValues(form.Name, values)
 
 	if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
@@ -445,6 +697,92 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// HandleSubmitReleaseForm writes the submitted values of a release's form back to
+// the contexts of the form, such as the values of the release or a live object in
+// the cluster, and returns the result of the write to each context
+func (app *App) HandleSubmitReleaseForm(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.SubmitReleaseFormForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo: app.repo,
+			},
+		},
+		Name: name,
+	}
+
+	form.ReleaseForm.PopulateHelmOptionsFromQueryParams(
+		vals,
+		app.repo.Cluster,
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	agent, err := app.getAgentFromReleaseForm(
+		w,
+		r,
+		form.ReleaseForm,
+	)
+
+	// errors are handled in app.getAgentFromReleaseForm
+	if err != nil {
+		return
+	}
+
+	release, err := agent.GetRelease(form.Name, 0)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	formBytes, parserDef, err := app.getReleaseFormParserDef(w, r, agent, release)
+
+	// errors are handled in app.getReleaseFormParserDef
+	if err != nil {
+		return
+	}
+
+	results, err := parser.WriteFormYAMLValues(parserDef, formBytes, form.Values)
+
+	if fieldErrs, ok := err.(parser.FieldErrors); ok {
+		app.handleErrorFormFieldValidation(fieldErrs, w)
+		return
+	} else if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
 // HandleRollbackRelease rolls a release back to a specified revision
 func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
@@ -530,6 +868,144 @@ func (app *App) getAgentFromQueryParams(
 	return app.getAgentFromReleaseForm(w, r, form)
 }
 
+// getReleaseFormParserDef finds the form.yaml in the chart of a release, and creates
+// the config for parsing it with the Helm agent of the release, a Kubernetes agent
+// created from the query params, and the registries of the project
+func (app *App) getReleaseFormParserDef(
+	w http.ResponseWriter,
+	r *http.Request,
+	agent *helm.Agent,
+	rel *release.Release,
+) ([]byte, *parser.ClientConfigDefault, error) {
+	formBytes := chartFormBytes(rel.Chart)
+
+	if formBytes == nil {
+		err := fmt.Errorf("release has no form")
+
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return nil, nil, err
+	}
+
+	k8sForm := &forms.K8sForm{
+		OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+			Repo: app.repo,
+		},
+	}
+
+	k8sAgent, err := app.getK8sAgentFromQueryParams(w, r, k8sForm, k8sForm)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projID, _ := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	return formBytes, &parser.ClientConfigDefault{
+		DynamicClient: k8sAgent.DynamicClient,
+		Informers:     app.getInformers(k8sForm, k8sAgent),
+		HelmAgent:     agent,
+		HelmRelease:   rel,
+		HelmChart:     rel.Chart,
+		Repo:          app.repo,
+		ProjectID:     uint(projID),
+	}, nil
+}
+
+// chartFormBytes returns the form.yaml of a chart, or the form generated from the
+// chart's values if it has no form.yaml. Returns nil if the chart has no form, and
+// one cannot be generated.
+func chartFormBytes(ch *chart.Chart) []byte {
+	formBytes, err := parser.FormBytesFromChart(ch)
+
+	if err != nil {
+		return nil
+	}
+
+	return formBytes
+}
+
+// applyFormValues sets the computed values of the chart's form, if it has one, in
+// the values that the chart is deployed with, and then validates the values against
+// the rules of the form. If the values are invalid, the errors are sent and false is
+// returned.
+func (app *App) applyFormValues(
+	w http.ResponseWriter,
+	ch *chart.Chart,
+	vals map[string]interface{},
+) bool {
+	formBytes := chartFormBytes(ch)
+
+	if formBytes == nil {
+		return true
+	}
+
+	fieldErrs, ok := app.computeFormValues(w, formBytes, vals, ch.Values)
+
+	if !ok {
+		return false
+	}
+
+	if len(fieldErrs) > 0 {
+		app.handleErrorFormFieldValidation(fieldErrs, w)
+		return false
+	}
+
+	return true
+}
+
+// computeFormValues sets the computed values of a form in vals, which fall back to
+// defaults, and returns the validation errors of the values. If the values cannot be
+// computed or validated, the error is sent and false is returned.
+func (app *App) computeFormValues(
+	w http.ResponseWriter,
+	formBytes []byte,
+	vals map[string]interface{},
+	defaults map[string]interface{},
+) (parser.FieldErrors, bool) {
+	if err := parser.ComputeFormYAMLValues(formBytes, vals, defaults); err != nil {
+		app.sendExternalError(err, http.StatusUnprocessableEntity, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"error computing values: " + err.Error()},
+		}, w)
+
+		return nil, false
+	}
+
+	fieldErrs, err := parser.ValidateFormYAMLValues(formBytes, vals, defaults)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusUnprocessableEntity, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"error validating values: " + err.Error()},
+		}, w)
+
+		return nil, false
+	}
+
+	return fieldErrs, true
+}
+
+// handleErrorFormFieldValidation sends the validation errors of the values of a form,
+// both as messages and as fields with the key and variable of the invalid content
+func (app *App) handleErrorFormFieldValidation(fieldErrs parser.FieldErrors, w http.ResponseWriter) {
+	errs := make([]string, 0, len(fieldErrs))
+
+	for _, fieldErr := range fieldErrs {
+		errs = append(errs, fieldErr.Error())
+	}
+
+	app.sendExternalError(fieldErrs, http.StatusUnprocessableEntity, HTTPError{
+		Code:   ErrReleaseValidateFields,
+		Errors: errs,
+		Fields: fieldErrs,
+	}, w)
+}
+
 // getAgentFromReleaseForm uses a non-validated form to construct a new Helm agent based on
 // the userID found in the session and the options required by the Helm agent.
 func (app *App) getAgentFromReleaseForm(
@@ -551,7 +1027,18 @@ func (app *App) getAgentFromReleaseForm(
 	if app.testing {
 		agent = app.TestAgents.HelmAgent
 	} else {
-		agent, err = helm.GetAgentOutOfClusterConfig(form.Form, app.logger)
+		var k8sAgent *kubernetes.Agent
+
+		k8sAgent, err = app.getK8sAgent(r, &kubernetes.OutOfClusterConfig{
+			Cluster: form.Cluster,
+			Repo:    form.Repo,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		agent, err = helm.GetAgentFromK8sAgent(form.Storage, form.Namespace, app.logger, k8sAgent)
 	}
 
 	return agent, err
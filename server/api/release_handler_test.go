@@ -237,6 +237,130 @@ func TestHandleListReleaseHistory(t *testing.T) {
 	testReleaseRequests(t, listReleaseHistoryTests, true)
 }
 
+var getReleaseComponentsTests = []*releaseTest{
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initManifestRelease,
+		},
+		msg:       "Export release graph as Mermaid",
+		method:    "GET",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/1/components?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"storage":    []string{"memory"},
+			"format":     []string{"mermaid"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody:   "graph LR\n  n0[\"Service<br/>web\"]\n  n1[\"ConfigMap<br/>web\"]",
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initManifestRelease,
+		},
+		msg:       "Export release graph in unsupported format",
+		method:    "GET",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/1/components?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"storage":    []string{"memory"},
+			"format":     []string{"png"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":601,"errors":["unsupported graph format \"png\""]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleGetReleaseComponents(t *testing.T) {
+	testReleaseRequests(t, getReleaseComponentsTests, true)
+}
+
+var getReleaseGraphDiffTests = []*releaseTest{
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initManifestRelease,
+			initManifestReleaseUpgrade,
+		},
+		msg:       "Diff release graphs",
+		method:    "GET",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/diff?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"storage":    []string{"memory"},
+			"from":       []string{"1"},
+			"to":         []string{"2"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusOK,
+		expBody: `{"AddedObjects":[{"Kind":"Secret","Name":"web","Namespace":"default"}],` +
+			`"RemovedObjects":[{"Kind":"ConfigMap","Name":"web","Namespace":"default"}],` +
+			`"ChangedObjects":[],"AddedRelations":[],"RemovedRelations":[]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initManifestRelease,
+		},
+		msg:       "Diff release graphs without revision",
+		method:    "GET",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/diff?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"storage":    []string{"memory"},
+			"from":       []string{"1"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["required validation failed"]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initManifestRelease,
+		},
+		msg:       "Diff release graphs with missing revision",
+		method:    "GET",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/diff?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"storage":    []string{"memory"},
+			"from":       []string{"1"},
+			"to":         []string{"2"},
+		}.Encode(),
+		body:      "",
+		expStatus: http.StatusNotFound,
+		expBody:   `{"code":602,"errors":["release not found"]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleGetReleaseGraphDiff(t *testing.T) {
+	testReleaseRequests(t, getReleaseGraphDiffTests, true)
+}
+
 var upgradeReleaseTests = []*releaseTest{
 	&releaseTest{
 		initializers: []func(tester *tester){
@@ -309,12 +433,222 @@ var upgradeReleaseTests = []*releaseTest{
 			},
 		},
 	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Upgrade release invalid form value",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": "\nimage:\n  tag: latest\n"
+			}
+		`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["image.tag: must match the pattern ^[0-9.]+$"],"fields":[{"key":"tabs[0].sections[0].contents[0]","variable":"image.tag","message":"must match the pattern ^[0-9.]+$"}]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Upgrade release not found",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/missing/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": "\nimage:\n  tag: \"1.0\"\n"
+			}
+		`,
+		expStatus: http.StatusNotFound,
+		expBody:   `{"code":602,"errors":["release not found"]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Upgrade release unparsable values",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": "image: [tag"
+			}
+		`,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			func(c *releaseTest, tester *tester, t *testing.T) {
+				rel, err := tester.app.TestAgents.HelmAgent.GetRelease("web", 0)
+
+				if err != nil || rel.Version != 1 {
+					t.Errorf("%s, expected the release not to be upgraded", c.msg)
+				}
+			},
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initComputedFormRelease,
+		},
+		msg:       "Upgrade release uncomputable form value",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": "\nreplicaCount: two\n"
+			}
+		`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["error computing values: could not compute maxReplicas: operator * requires numbers, got two and 2"]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
 }
 
 func TestUpgradeRelease(t *testing.T) {
 	testReleaseRequests(t, upgradeReleaseTests, true)
 }
 
+var submitReleaseFormTests = []*releaseTest{
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Submit release form",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/form?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": {
+					"image.tag": "1.1.0"
+				}
+			}
+		`,
+		expStatus: http.StatusOK,
+		expBody:   `[{"context":{"type":"helm/values","config":null},"variables":["image.tag"]}]`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+			func(c *releaseTest, tester *tester, t *testing.T) {
+				rel, err := tester.app.TestAgents.HelmAgent.GetRelease("web", 0)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expConfig := map[string]interface{}{
+					"replicaCount": float64(1),
+					"image": map[string]interface{}{
+						"repository": "nginx",
+						"tag":        "1.1.0",
+					},
+				}
+
+				if rel.Version != 2 {
+					t.Errorf("%s, wrong revision: got %d want 2", c.msg, rel.Version)
+				}
+
+				if !reflect.DeepEqual(rel.Config, expConfig) {
+					t.Errorf("%s, validation wrong config: got %v want %v",
+						c.msg, rel.Config, expConfig)
+				}
+			},
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Submit release form unknown variable",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/form?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": {
+					"ingress.enabled": true
+				}
+			}
+		`,
+		expStatus: http.StatusBadRequest,
+		expBody:   `{"code":601,"errors":["variable ingress.enabled is not in the form"]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initFormRelease,
+		},
+		msg:       "Submit release form invalid value",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/web/form?" + url.Values{
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "default",
+				"storage": "memory",
+				"values": {
+					"image.tag": "latest"
+				}
+			}
+		`,
+		expStatus: http.StatusUnprocessableEntity,
+		expBody:   `{"code":601,"errors":["image.tag: must match the pattern ^[0-9.]+$"],"fields":[{"key":"tabs[0].sections[0].contents[0]","variable":"image.tag","message":"must match the pattern ^[0-9.]+$"}]}`,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			releaseBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleSubmitReleaseForm(t *testing.T) {
+	testReleaseRequests(t, submitReleaseFormTests, true)
+}
+
 var rollbackReleaseTests = []*releaseTest{
 	&releaseTest{
 		initializers: []func(tester *tester){
@@ -402,6 +736,90 @@ func initDefaultReleases(tester *tester) {
 	agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("")
 }
 
+func initManifestRelease(tester *tester) {
+	initUserDefault(tester)
+	initProject(tester)
+	initProjectClusterDefault(tester)
+
+	agent := tester.app.TestAgents.HelmAgent
+
+	rel := releaseStubToRelease(releaseStub{"web", "default", 1, "1.0.0", release.StatusDeployed})
+	rel.Manifest = "kind: Service\nmetadata:\n  name: web\n---\nkind: ConfigMap\nmetadata:\n  name: web\n"
+
+	agent.ActionConfig.Releases.Create(rel)
+	agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("")
+}
+
+func initManifestReleaseUpgrade(tester *tester) {
+	agent := tester.app.TestAgents.HelmAgent
+
+	rel := releaseStubToRelease(releaseStub{"web", "default", 2, "1.0.1", release.StatusDeployed})
+	rel.Manifest = "kind: Service\nmetadata:\n  name: web\n---\nkind: Secret\nmetadata:\n  name: web\n"
+
+	agent.ActionConfig.Releases.Create(rel)
+	agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("")
+}
+
+func initFormRelease(tester *tester) {
+	createFormRelease(tester, `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: image
+    contents:
+    - type: string-input
+      label: Image Tag
+      variable: image.tag
+      settings:
+        pattern: "^[0-9.]+$"
+`)
+}
+
+func initComputedFormRelease(tester *tester) {
+	createFormRelease(tester, `
+name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: replicas
+    contents:
+    - type: heading
+      label: Max Replicas
+      variable: maxReplicas
+      compute: replicaCount * 2
+`)
+}
+
+// createFormRelease creates the web release, whose chart has the form
+func createFormRelease(tester *tester, form string) {
+	initUserDefault(tester)
+	initProject(tester)
+	initProjectClusterDefault(tester)
+
+	agent := tester.app.TestAgents.HelmAgent
+
+	rel := releaseStubToRelease(releaseStub{"web", "default", 1, "1.0.0", release.StatusDeployed})
+	rel.Config = map[string]interface{}{
+		"replicaCount": float64(1),
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.0.0",
+		},
+	}
+	rel.Chart.Files = []*chart.File{
+		&chart.File{
+			Name: "form.yaml",
+			Data: []byte(form),
+		},
+	}
+
+	agent.ActionConfig.Releases.Create(rel)
+	agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("")
+}
+
 func initHistoryReleases(tester *tester) {
 	initUserDefault(tester)
 	initProject(tester)
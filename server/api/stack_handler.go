@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/stack"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// PorterStack is a stack deployed from a stack template, with the combined status
+// of its releases
+type PorterStack struct {
+	*models.StackExternal
+	*stack.Status
+}
+
+// HandleGetStack returns a stack deployed from a stack template, identified by its
+// name and the cluster_id and namespace query params, with the status of the
+// release of each of its charts and their combined status
+func (app *App) HandleGetStack(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo: app.repo,
+		},
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form,
+		form.PopulateHelmOptionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	if form.Cluster == nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"cluster_id is required"},
+		}, w)
+
+		return
+	}
+
+	s, err := app.repo.Stack.ReadStack(form.Cluster.ID, form.Namespace, chi.URLParam(r, "name"))
+
+	if err != nil || s.ProjectID != uint(projID) {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"stack not found"},
+		}, w)
+
+		return
+	}
+
+	stackYAML, err := stack.FromBytes([]byte(s.Spec))
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	app.writeStackStatus(w, agent, s, stackYAML)
+}
+
+// writeStackStatus reads the status of the releases of a stack, and writes the
+// stack with its status
+func (app *App) writeStackStatus(
+	w http.ResponseWriter,
+	agent *helm.Agent,
+	s *models.Stack,
+	stackYAML *models.StackYAML,
+) {
+	status, err := stack.GetStatus(agent, s.Name, stackYAML)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(&PorterStack{s.Externalize(), status}); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/stack"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+const stackSpec = `name: shop
+charts:
+- name: app
+  chart: web
+  values_from:
+  - variable: env.DB_HOST
+    chart: db
+    query: '{ .name }'
+- name: db
+  chart: postgres
+- name: cache
+  chart: redis
+`
+
+var stackEndpoint = "/api/projects/1/stacks/shop?" + url.Values{
+	"cluster_id": []string{"1"},
+	"namespace":  []string{"default"},
+	"storage":    []string{"memory"},
+}.Encode()
+
+var getStackTests = []*releaseTest{
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initStack,
+		},
+		msg:       "Get stack",
+		method:    "GET",
+		endpoint:  stackEndpoint,
+		namespace: "default",
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){
+			func(c *releaseTest, tester *tester, t *testing.T) {
+				res := &struct {
+					Name     string               `json:"name"`
+					Revision uint                 `json:"revision"`
+					Status   string               `json:"status"`
+					Charts   []*stack.ChartStatus `json:"charts"`
+				}{}
+
+				json.Unmarshal(tester.rr.Body.Bytes(), res)
+
+				if res.Name != "shop" || res.Revision != 1 {
+					t.Errorf("%s, wrong stack: got %s revision %d", c.msg, res.Name, res.Revision)
+				}
+
+				// the app failed, and the cache was never deployed
+				if res.Status != stack.StatusFailed {
+					t.Errorf("%s, wrong status: got %s want %s", c.msg, res.Status, stack.StatusFailed)
+				}
+
+				expStatuses := []string{"deployed", "failed", stack.StatusMissing}
+
+				if len(res.Charts) != len(expStatuses) {
+					t.Fatalf("%s, wrong number of charts: got %d", c.msg, len(res.Charts))
+				}
+
+				for i, chartStatus := range res.Charts {
+					if chartStatus.Status != expStatuses[i] {
+						t.Errorf("%s, wrong status of %s: got %s want %s",
+							c.msg, chartStatus.Name, chartStatus.Status, expStatuses[i])
+					}
+				}
+			},
+		},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initDefaultReleases,
+		},
+		msg:       "Get stack that does not exist",
+		method:    "GET",
+		endpoint:  stackEndpoint,
+		namespace: "default",
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+}
+
+func TestHandleGetStack(t *testing.T) {
+	testReleaseRequests(t, getStackTests, true)
+}
+
+func initStack(tester *tester) {
+	initUserDefault(tester)
+	initProject(tester)
+	initProjectClusterDefault(tester)
+
+	agent := tester.app.TestAgents.HelmAgent
+
+	makeReleases(agent, []releaseStub{
+		releaseStub{"shop-db", "default", 1, "1.0.0", release.StatusDeployed},
+		releaseStub{"shop-app", "default", 1, "1.0.0", release.StatusFailed},
+	})
+
+	agent.ActionConfig.Releases.Driver.(*driver.Memory).SetNamespace("")
+
+	tester.repo.Stack.CreateStack(&models.Stack{
+		ProjectID:       1,
+		ClusterID:       1,
+		Name:            "shop",
+		Namespace:       "default",
+		TemplateName:    "shop-stack",
+		TemplateVersion: "0.1.0",
+		Revision:        1,
+		Spec:            stackSpec,
+	})
+}
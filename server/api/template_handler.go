@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/helm/loader"
+	"github.com/porter-dev/porter/internal/helm/stack"
+	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/templater/parser"
 
 	"github.com/porter-dev/porter/internal/models"
@@ -44,6 +47,43 @@ func (app *App) HandleListTemplates(w http.ResponseWriter, r *http.Request) {
 
 // HandleReadTemplate reads a given template with name and version field
 func (app *App) HandleReadTemplate(w http.ResponseWriter, r *http.Request) {
+	app.readTemplate(w, r, &parser.ClientConfigDefault{})
+}
+
+// HandleReadProjectTemplate reads a given template with name and version field for
+// a cluster of a project, so that the contexts of the form that read from the
+// cluster and the project's registries are populated
+func (app *App) HandleReadProjectTemplate(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	k8sForm := &forms.K8sForm{
+		OutOfClusterConfig: &kubernetes.OutOfClusterConfig{
+			Repo: app.repo,
+		},
+	}
+
+	agent, err := app.getK8sAgentFromQueryParams(w, r, k8sForm, k8sForm)
+
+	// errors are handled in app.getK8sAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	app.readTemplate(w, r, &parser.ClientConfigDefault{
+		DynamicClient: agent.DynamicClient,
+		Repo:          app.repo,
+		ProjectID:     uint(projID),
+	})
+}
+
+// readTemplate reads the template with the name and version URL params, and reads
+// its form with the clients of parserDef
+func (app *App) readTemplate(w http.ResponseWriter, r *http.Request, parserDef *parser.ClientConfigDefault) {
 	name := chi.URLParam(r, "name")
 	version := chi.URLParam(r, "version")
 
@@ -75,25 +115,27 @@ func (app *App) HandleReadTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parserDef := &parser.ClientConfigDefault{
-		HelmChart: chart,
-	}
+	parserDef.HelmChart = chart
 
 	res := &models.PorterChartRead{}
 	res.Metadata = chart.Metadata
 	res.Values = chart.Values
 
 	for _, file := range chart.Files {
-		if strings.Contains(file.Name, "form.yaml") {
-			formYAML, err := parser.FormYAMLFromBytes(parserDef, file.Data)
+		if strings.Contains(file.Name, "README.md") {
+			res.Markdown = string(file.Data)
+		}
+	}
 
-			if err != nil {
-				break
-			}
+	// stack templates list the charts that they deploy
+	if stackYAML, err := stack.FromChart(chart); err == nil {
+		res.Stack = stackYAML
+	}
 
+	// charts without a form.yaml get a form generated from their values
+	if formBytes, err := parser.FormBytesFromChart(chart); err == nil && formBytes != nil {
+		if formYAML, err := parser.FormYAMLFromBytes(parserDef, formBytes); err == nil {
 			res.Form = formYAML
-		} else if strings.Contains(file.Name, "README.md") {
-			res.Markdown = string(file.Data)
 		}
 	}
 
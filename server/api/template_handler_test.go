@@ -3,11 +3,16 @@ package api_test
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -90,8 +95,124 @@ func TestHandleListTemplates(t *testing.T) {
 	testTemplatesRequests(t, listTemplatesTests, true)
 }
 
+const namespaceFormYAML = `name: web
+tabs:
+- name: main
+  label: Main
+  sections:
+  - name: main
+    contents:
+    - type: select
+      label: Namespace
+      variable: namespace
+      settings:
+        options_from:
+          context:
+            type: cluster/metadata
+          query: "{ .namespaces }"
+`
+
+func TestHandleReadProjectTemplate(t *testing.T) {
+	web := newTestChart("web", nil)
+	web.Files = []*chart.File{
+		&chart.File{Name: "form.yaml", Data: []byte(namespaceFormYAML)},
+	}
+
+	server := newChartRepo(t, web)
+	repoURL := url.QueryEscape(server.URL)
+
+	readTemplateTests := []*templateTest{
+		&templateTest{
+			initializers: []func(tester *tester){
+				initUserDefault,
+				initProject,
+				initProjectClusterDefault,
+				initTemplateNamespaces,
+			},
+			msg:       "Read template for a cluster",
+			method:    "GET",
+			endpoint:  "/api/projects/1/templates/web/0.1.0?cluster_id=1&repo_url=" + repoURL,
+			expStatus: http.StatusOK,
+			useCookie: true,
+			validators: []func(c *templateTest, tester *tester, t *testing.T){
+				templateOptionsValidator([]string{"default", "kube-system"}),
+			},
+		},
+		&templateTest{
+			initializers: []func(tester *tester){
+				initUserDefault,
+				initTemplateNamespaces,
+			},
+			msg:       "Read template without a cluster",
+			method:    "GET",
+			endpoint:  "/api/templates/web/0.1.0?repo_url=" + repoURL,
+			expStatus: http.StatusOK,
+			useCookie: true,
+			validators: []func(c *templateTest, tester *tester, t *testing.T){
+				templateOptionsValidator(nil),
+			},
+		},
+		&templateTest{
+			initializers: []func(tester *tester){
+				initUserDefault,
+				initProject,
+				initProjectClusterDefault,
+			},
+			msg:       "Read template for a cluster of another project",
+			method:    "GET",
+			endpoint:  "/api/projects/1/templates/web/0.1.0?cluster_id=2&repo_url=" + repoURL,
+			expStatus: http.StatusForbidden,
+			useCookie: true,
+		},
+	}
+
+	testTemplatesRequests(t, readTemplateTests, true)
+}
+
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
+func initTemplateNamespaces(tester *tester) {
+	agent := kubernetes.GetAgentTesting()
+
+	agent.DynamicClient = kubernetes.NewDynamicClientTesting(
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "kube-system"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "default"},
+		}},
+	)
+
+	tester.app.TestAgents.K8sAgent = agent
+}
+
+// templateOptionsValidator checks the options of the namespace select of the form
+func templateOptionsValidator(expOptions []string) func(c *templateTest, tester *tester, t *testing.T) {
+	return func(c *templateTest, tester *tester, t *testing.T) {
+		gotBody := models.PorterChartRead{}
+
+		json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
+
+		if gotBody.Form == nil {
+			t.Fatalf("%s, template has no form", c.msg)
+		}
+
+		var options []string
+
+		for _, option := range gotBody.Form.Tabs[0].Sections[0].Contents[0].Settings.Options {
+			options = append(options, option.Label)
+		}
+
+		if !reflect.DeepEqual(options, expOptions) {
+			t.Errorf("%s, wrong namespace options: expected %v, got %v", c.msg, expOptions, options)
+		}
+	}
+}
+
 func templatesListValidator(c *templateTest, tester *tester, t *testing.T) {
 	gotBody := make([]*models.PorterChartList, 0)
 	expBody := make([]*models.PorterChartList, 0)